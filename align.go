@@ -0,0 +1,145 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// alignCoarseSize est la taille au-dessous de laquelle AlignTranslation cesse de réduire les
+// images et cherche le décalage de façon exhaustive.
+const alignCoarseSize = 64
+
+// AlignTranslation estime le décalage de img par rapport à ref, deux vues de la même scène, et
+// renvoie img recalée sur ref ainsi que ce décalage : un détail situé en (x, y) dans ref se
+// trouve en (x+shift.X, y+shift.Y) dans img. Le décalage, au dixième de pixel près, est celui qui
+// maximise la corrélation croisée normalisée des luminances ; il est cherché d'abord sur des
+// versions réduites des images, puis affiné à chaque agrandissement, et peut atteindre le quart
+// de leur taille. L'image recalée est interpolée de façon bilinéaire ; les pixels qu'elle ne
+// couvre pas sont noirs.
+func AlignTranslation(ref, img *PPM) (*PPM, Vec2, error) {
+	if ref.width != img.width || ref.height != img.height {
+		return nil, Vec2{}, fmt.Errorf("les images n'ont pas la même taille: %dx%d et %dx%d", ref.width, ref.height, img.width, img.height)
+	}
+	if ref.width < 2 || ref.height < 2 {
+		return nil, Vec2{}, fmt.Errorf("image trop petite pour être alignée: %dx%d", ref.width, ref.height)
+	}
+
+	shift := estimateShift(grayPyramid(ref), grayPyramid(img))
+
+	samples := img.toFloat(false)
+	aligned := NewPPM(img.width, img.height, img.max)
+	for y, row := range aligned.data {
+		for x, pixel := range row {
+			sx, sy := float64(x)+shift.X, float64(y)+shift.Y
+			if !samples.contains(sx, sy) {
+				continue
+			}
+			for c := range pixel {
+				pixel[c] = encodeValue(samples.bilinear(sx, sy, c), img.max, false)
+			}
+		}
+	}
+	aligned.meta = img.meta.derive("align %+.1f%+.1f", -shift.X, -shift.Y)
+	return aligned, shift, nil
+}
+
+// grayPyramid renvoie la luminance de l'image (entre 0 et 1) suivie de ses réductions
+// successives de moitié, jusqu'à alignCoarseSize pixels de côté environ.
+func grayPyramid(ppm *PPM) []*floatImage {
+	gray := newFloatImage(ppm.width, ppm.height, 1)
+	scale := 1.0
+	if ppm.max > 0 {
+		scale = 1 / float64(ppm.max)
+	}
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			gray.pix[gray.index(x, y, 0)] = luminance(pixel) * scale
+		}
+	}
+
+	pyramid := []*floatImage{gray}
+	for level := gray; max(level.width, level.height) > alignCoarseSize && min(level.width, level.height) >= 32; {
+		half := newFloatImage(level.width/2, level.height/2, 1)
+		for y := 0; y < half.height; y++ {
+			for x := 0; x < half.width; x++ {
+				sum := level.at(2*x, 2*y, 0) + level.at(2*x+1, 2*y, 0) + level.at(2*x, 2*y+1, 0) + level.at(2*x+1, 2*y+1, 0)
+				half.pix[half.index(x, y, 0)] = sum / 4
+			}
+		}
+		pyramid = append(pyramid, half)
+		level = half
+	}
+	return pyramid
+}
+
+// estimateShift cherche le décalage entier sur le niveau le plus réduit des pyramides, le reporte
+// et l'affine d'un pixel à chaque niveau, puis l'estime à une fraction de pixel près sur l'image
+// entière en ajustant une parabole sur les corrélations voisines.
+func estimateShift(ref, img []*floatImage) Vec2 {
+	coarsest := len(ref) - 1
+	a, b := ref[coarsest], img[coarsest]
+	rangeX, rangeY := max(a.width/4, 1), max(a.height/4, 1)
+	dx, dy, _ := bestShift(a, b, 0, 0, rangeX, rangeY)
+	for level := coarsest - 1; level >= 0; level-- {
+		dx, dy, _ = bestShift(ref[level], img[level], 2*dx, 2*dy, 1, 1)
+	}
+
+	// Parabole passant par les corrélations en d-1, d et d+1 : son sommet donne la partie
+	// fractionnaire du décalage.
+	refine := func(minus, center, plus float64) float64 {
+		curvature := minus - 2*center + plus
+		if curvature >= 0 || math.IsInf(minus, 0) || math.IsInf(plus, 0) {
+			return 0
+		}
+		return clampFloat((minus-plus)/(2*curvature), -0.5, 0.5)
+	}
+	center := correlation(ref[0], img[0], dx, dy)
+	fx := refine(correlation(ref[0], img[0], dx-1, dy), center, correlation(ref[0], img[0], dx+1, dy))
+	fy := refine(correlation(ref[0], img[0], dx, dy-1), center, correlation(ref[0], img[0], dx, dy+1))
+	return Vec2{X: math.Round((float64(dx)+fx)*10) / 10, Y: math.Round((float64(dy)+fy)*10) / 10}
+}
+
+// bestShift renvoie le décalage entier, à au plus rangeX et rangeY pixels de (dx, dy), qui
+// maximise la corrélation entre ref et img, ainsi que cette corrélation.
+func bestShift(ref, img *floatImage, dx, dy, rangeX, rangeY int) (int, int, float64) {
+	bestX, bestY, best := dx, dy, math.Inf(-1)
+	for sy := dy - rangeY; sy <= dy+rangeY; sy++ {
+		for sx := dx - rangeX; sx <= dx+rangeX; sx++ {
+			if score := correlation(ref, img, sx, sy); score > best {
+				bestX, bestY, best = sx, sy, score
+			}
+		}
+	}
+	return bestX, bestY, best
+}
+
+// correlation renvoie la corrélation croisée normalisée (entre -1 et 1) entre ref et img décalée
+// de (dx, dy), calculée sur la partie commune aux deux images. Elle vaut -Inf si cette partie
+// couvre moins de la moitié de chaque dimension, pour ne pas comparer de trop petites zones.
+func correlation(ref, img *floatImage, dx, dy int) float64 {
+	x0, x1 := max(0, -dx), min(ref.width, img.width-dx)
+	y0, y1 := max(0, -dy), min(ref.height, img.height-dy)
+	if 2*(x1-x0) < ref.width || 2*(y1-y0) < ref.height {
+		return math.Inf(-1)
+	}
+
+	n := float64((x1 - x0) * (y1 - y0))
+	var sumA, sumB, sumAA, sumBB, sumAB float64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			a := ref.pix[ref.index(x, y, 0)]
+			b := img.pix[img.index(x+dx, y+dy, 0)]
+			sumA += a
+			sumB += b
+			sumAA += a * a
+			sumBB += b * b
+			sumAB += a * b
+		}
+	}
+	covariance := sumAB - sumA*sumB/n
+	variance := (sumAA - sumA*sumA/n) * (sumBB - sumB*sumB/n)
+	if variance <= 0 {
+		return 0
+	}
+	return covariance / math.Sqrt(variance)
+}
@@ -0,0 +1,44 @@
+package netpbm
+
+// Annotation est une boîte étiquetée à dessiner par DrawAnnotations, par exemple le résultat d'un
+// détecteur d'objets : la zone détectée, son libellé ("personne 0.92") et sa couleur.
+type Annotation struct {
+	Rect  Rect
+	Label string
+	Color Pixel
+}
+
+// contrastColor renvoie le noir ou le blanc, selon celui qui se lit le mieux sur la couleur donnée.
+func contrastColor(background Pixel) Pixel {
+	luma := Rec601.Red*float64(background.Red) + Rec601.Green*float64(background.Green) + Rec601.Blue*float64(background.Blue)
+	if luma > 128 {
+		return Pixel{}
+	}
+	return Pixel{Red: 255, Green: 255, Blue: 255}
+}
+
+// DrawAnnotations dessine chaque annotation sur l'image PPM : le contour de sa zone et, au-dessus
+// (ou à l'intérieur si la zone touche le haut de l'image), son libellé sur un fond de sa couleur,
+// écrit en noir ou en blanc selon le contraste. L'épaisseur des traits et la taille du texte
+// croissent avec celle de l'image, pour rester lisibles sur une trame haute définition.
+func (ppm *PPM) DrawAnnotations(annotations []Annotation) {
+	defer ppm.changes.batch(ppm.Size)()
+	scale := 1 + min(ppm.width, ppm.height)/400
+	for _, annotation := range annotations {
+		r := annotation.Rect
+		for i := 0; i < scale; i++ {
+			ppm.strokeRect(Rect{X: r.X + i, Y: r.Y + i, Width: r.Width - 2*i, Height: r.Height - 2*i}, annotation.Color)
+		}
+		if annotation.Label == "" {
+			continue
+		}
+
+		textWidth, textHeight := TextSize(annotation.Label, scale)
+		label := Rect{X: r.X, Y: r.Y - textHeight - 2*scale, Width: textWidth + 2*scale, Height: textHeight + 2*scale}
+		if label.Y < 0 {
+			label.Y = r.Y
+		}
+		ppm.fillRect(label, annotation.Color)
+		ppm.DrawText(Point{X: label.X + scale, Y: label.Y + scale}, annotation.Label, scale, contrastColor(annotation.Color))
+	}
+}
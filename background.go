@@ -0,0 +1,147 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// BackgroundMethod est la façon dont un BackgroundModel suit le fond de la scène.
+type BackgroundMethod int
+
+const (
+	// BackgroundAverage tient une moyenne glissante des images : chaque nouvelle image compte
+	// pour Rate dans le fond. Simple, mais un objet qui passe laisse une trace le temps d'être
+	// oublié.
+	BackgroundAverage BackgroundMethod = iota
+	// BackgroundMedian approche la médiane des images : le fond avance d'au plus Rate vers chaque
+	// nouvelle image. Il ignore les passages brefs mais s'adapte lentement aux changements
+	// d'éclairage.
+	BackgroundMedian
+)
+
+// String renvoie le nom de la méthode.
+func (method BackgroundMethod) String() string {
+	switch method {
+	case BackgroundAverage:
+		return "moyenne"
+	case BackgroundMedian:
+		return "médiane"
+	}
+	return fmt.Sprintf("BackgroundMethod(%d)", int(method))
+}
+
+// BackgroundModel estime le fond immobile d'une séquence d'images PGM prises d'un point de vue
+// fixe, pour en extraire ce qui bouge : on lui fait voir les images une à une avec Accumulate, et
+// Foreground renvoie le masque des pixels qui s'écartent du fond. Les niveaux sont ramenés entre
+// 0 et 1, quelle que soit la valeur maximale des images.
+type BackgroundModel struct {
+	Method    BackgroundMethod
+	Rate      float64 // Vitesse d'adaptation du fond, entre 0 et 1
+	Threshold float64 // Écart au fond, entre 0 et 1, au-delà duquel un pixel est au premier plan
+
+	background [][]float64
+	width      int
+	frames     int
+}
+
+// NewBackgroundModel crée un modèle de fond vide. Pour BackgroundAverage, rate est le poids de
+// chaque nouvelle image (0.05 oublie un objet en une cinquantaine d'images) ; pour
+// BackgroundMedian, c'est le pas maximal par image (1.0/255 avance d'un niveau sur 255).
+func NewBackgroundModel(method BackgroundMethod, rate, threshold float64) *BackgroundModel {
+	return &BackgroundModel{Method: method, Rate: rate, Threshold: threshold}
+}
+
+// Accumulate met le fond à jour avec une nouvelle image. La première image sert de fond initial ;
+// les suivantes doivent avoir la même taille.
+func (model *BackgroundModel) Accumulate(frame *PGM) error {
+	if model.background == nil {
+		model.background = make([][]float64, frame.height)
+		for y := range model.background {
+			model.background[y] = make([]float64, frame.width)
+			for x := range model.background[y] {
+				model.background[y][x] = normalizedLevel(frame, x, y)
+			}
+		}
+		model.width = frame.width
+		model.frames = 1
+		return nil
+	}
+	if err := model.checkSize(frame); err != nil {
+		return err
+	}
+
+	model.frames++
+	rate := math.Max(0, math.Min(model.Rate, 1))
+	if model.Method == BackgroundAverage {
+		// Moyenne exacte des premières images, tant qu'elle pèse plus que rate : le fond se
+		// stabilise plus vite qu'en partant de la seule première image.
+		rate = math.Max(rate, 1/float64(model.frames))
+	}
+	for y, row := range model.background {
+		for x, value := range row {
+			level := normalizedLevel(frame, x, y)
+			switch model.Method {
+			case BackgroundMedian:
+				row[x] = value + math.Max(-rate, math.Min(level-value, rate))
+			default:
+				row[x] = value + (level-value)*rate
+			}
+		}
+	}
+	return nil
+}
+
+// Foreground renvoie le masque des pixels de frame qui s'écartent du fond de plus de Threshold :
+// noirs au premier plan, blancs sur le fond. L'image n'est pas ajoutée au modèle ; c'est à
+// l'appelant de la passer ensuite à Accumulate s'il le souhaite.
+func (model *BackgroundModel) Foreground(frame *PGM) (*PBM, error) {
+	if model.background == nil {
+		return nil, fmt.Errorf("aucune image accumulée dans le modèle de fond")
+	}
+	if err := model.checkSize(frame); err != nil {
+		return nil, err
+	}
+
+	mask := NewPBM(frame.width, frame.height)
+	for y, row := range model.background {
+		for x, value := range row {
+			mask.data[y][x] = math.Abs(normalizedLevel(frame, x, y)-value) > model.Threshold
+		}
+	}
+	mask.meta = frame.meta.derive("foreground")
+	return mask, nil
+}
+
+// Background renvoie le fond estimé, en image PGM de valeur maximale 255, ou nil si aucune image
+// n'a encore été accumulée.
+func (model *BackgroundModel) Background() *PGM {
+	if model.background == nil {
+		return nil
+	}
+	pgm := NewPGM(model.width, len(model.background), 255)
+	for y, row := range model.background {
+		for x, value := range row {
+			pgm.data[y][x] = uint8(math.Round(value * 255))
+		}
+	}
+	return pgm
+}
+
+// Frames renvoie le nombre d'images accumulées.
+func (model *BackgroundModel) Frames() int {
+	return model.frames
+}
+
+// Reset oublie le fond : la prochaine image accumulée, de n'importe quelle taille, le remplace.
+func (model *BackgroundModel) Reset() {
+	model.background = nil
+	model.width, model.frames = 0, 0
+}
+
+// checkSize vérifie que frame a la taille des images déjà accumulées.
+func (model *BackgroundModel) checkSize(frame *PGM) error {
+	if frame.width != model.width || frame.height != len(model.background) {
+		return fmt.Errorf("taille %dx%d différente de celle du modèle de fond (%dx%d)", frame.width, frame.height, model.width, len(model.background))
+	}
+	return nil
+}
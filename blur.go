@@ -0,0 +1,76 @@
+package netpbm
+
+import "math"
+
+// gaussianKernel renvoie un noyau gaussien normalisé de rayon ⌈3σ⌉.
+func gaussianKernel(sigma float64) []float64 {
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	var total float64
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		total += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= total
+	}
+	return kernel
+}
+
+// blur applique un flou gaussien séparable d'écart type sigma.
+func (img *floatImage) blur(sigma float64) *floatImage {
+	if sigma <= 0 {
+		result := newFloatImage(img.width, img.height, img.channels)
+		copy(result.pix, img.pix)
+		return result
+	}
+
+	kernel := gaussianKernel(sigma)
+	radius := len(kernel) / 2
+
+	horizontal := newFloatImage(img.width, img.height, img.channels)
+	for y := 0; y < img.height; y++ {
+		for x := 0; x < img.width; x++ {
+			for c := 0; c < img.channels; c++ {
+				var sum float64
+				for k, w := range kernel {
+					sum += w * img.at(x+k-radius, y, c)
+				}
+				horizontal.pix[horizontal.index(x, y, c)] = sum
+			}
+		}
+	}
+
+	result := newFloatImage(img.width, img.height, img.channels)
+	for y := 0; y < img.height; y++ {
+		for x := 0; x < img.width; x++ {
+			for c := 0; c < img.channels; c++ {
+				var sum float64
+				for k, w := range kernel {
+					sum += w * horizontal.at(x, y+k-radius, c)
+				}
+				result.pix[result.index(x, y, c)] = sum
+			}
+		}
+	}
+	return result
+}
+
+// GaussianBlur renvoie une copie floutée de l'image PPM (flou gaussien d'écart type sigma).
+// Si linear est vrai, le flou est calculé en lumière linéaire.
+func (ppm *PPM) GaussianBlur(sigma float64, linear bool) *PPM {
+	result := ppm.toFloat(linear).blur(sigma).toPPM(ppm.max, linear)
+	result.magicNumber = ppm.magicNumber
+	result.meta = ppm.meta.derive("blur %g", sigma)
+	return result
+}
+
+// GaussianBlur renvoie une copie floutée de l'image PGM (flou gaussien d'écart type sigma).
+// Si linear est vrai, le flou est calculé en lumière linéaire.
+func (pgm *PGM) GaussianBlur(sigma float64, linear bool) *PGM {
+	result := pgm.toFloat(linear).blur(sigma).toPGM(pgm.max, linear)
+	result.magicNumber = pgm.magicNumber
+	result.meta = pgm.meta.derive("blur %g", sigma)
+	return result
+}
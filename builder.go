@@ -0,0 +1,128 @@
+package netpbm
+
+import "fmt"
+
+// defaultBuilderPalette associe une couleur à chaque symbole reconnu d'emblée par Builder.Rows :
+// les initiales anglaises des couleurs primaires et secondaires, plus # et . pour les images
+// bitonales.
+var defaultBuilderPalette = map[rune]Pixel{
+	'K': {Red: 0, Green: 0, Blue: 0},
+	'W': {Red: 255, Green: 255, Blue: 255},
+	'R': {Red: 255, Green: 0, Blue: 0},
+	'G': {Red: 0, Green: 255, Blue: 0},
+	'B': {Red: 0, Green: 0, Blue: 255},
+	'C': {Red: 0, Green: 255, Blue: 255},
+	'M': {Red: 255, Green: 0, Blue: 255},
+	'Y': {Red: 255, Green: 255, Blue: 0},
+	'#': {Red: 0, Green: 0, Blue: 0},
+	'.': {Red: 255, Green: 255, Blue: 255},
+}
+
+// Builder construit pas à pas une petite image, surtout pour écrire lisiblement les images des
+// tests :
+//
+//	img := netpbm.Build(3, 2).Rows(
+//		"RRG",
+//		"GGB",
+//	).PPM()
+//
+// Ses méthodes renvoient le Builder pour être enchaînées. Une erreur (pixel hors de l'image,
+// symbole inconnu, ligne de la mauvaise longueur…) est une erreur de programmation dans le code
+// qui l'appelle : elle provoque une panique plutôt que d'être renvoyée.
+type Builder struct {
+	ppm     *PPM
+	palette map[rune]Pixel
+	row     int // Première ligne qu'écrira le prochain appel à Rows
+}
+
+// Build commence une image noire de width×height pixels, de valeur maximale 255.
+func Build(width, height int) *Builder {
+	if width < 0 || height < 0 {
+		panic(fmt.Sprintf("netpbm: taille invalide pour Build: %dx%d", width, height))
+	}
+	palette := make(map[rune]Pixel, len(defaultBuilderPalette))
+	for symbol, color := range defaultBuilderPalette {
+		palette[symbol] = color
+	}
+	return &Builder{ppm: NewPPM(width, height, 255), palette: palette}
+}
+
+// Fill peint toute l'image avec color.
+func (builder *Builder) Fill(color Pixel) *Builder {
+	builder.ppm.own()
+	for _, row := range builder.ppm.data {
+		for _, pixel := range row {
+			pixel[0], pixel[1], pixel[2] = color.Red, color.Green, color.Blue
+		}
+	}
+	return builder
+}
+
+// Px peint le pixel (x, y) avec color.
+func (builder *Builder) Px(x, y int, color Pixel) *Builder {
+	if x < 0 || x >= builder.ppm.width || y < 0 || y >= builder.ppm.height {
+		panic(fmt.Sprintf("netpbm: pixel (%d, %d) hors de l'image %dx%d", x, y, builder.ppm.width, builder.ppm.height))
+	}
+	// Les images renvoyées par PPM partagent les lignes de l'image en construction.
+	pixel := builder.ppm.writableRow(y)[x]
+	pixel[0], pixel[1], pixel[2] = color.Red, color.Green, color.Blue
+	return builder
+}
+
+// Color associe color au symbole, pour les appels suivants à Rows. Les symboles prédéfinis
+// (K, W, R, G, B, C, M, Y, # et .) peuvent être redéfinis.
+func (builder *Builder) Color(symbol rune, color Pixel) *Builder {
+	builder.palette[symbol] = color
+	return builder
+}
+
+// Rows peint des lignes entières de l'image, un symbole par pixel, en commençant par la ligne du
+// haut puis, aux appels suivants, là où le précédent s'est arrêté. Chaque chaîne doit compter
+// exactement un symbole par colonne.
+func (builder *Builder) Rows(rows ...string) *Builder {
+	for _, symbols := range rows {
+		y := builder.row
+		if y >= builder.ppm.height {
+			panic(fmt.Sprintf("netpbm: ligne %q en trop, l'image n'a que %d lignes", symbols, builder.ppm.height))
+		}
+		runes := []rune(symbols)
+		if len(runes) != builder.ppm.width {
+			panic(fmt.Sprintf("netpbm: la ligne %d (%q) compte %d symboles au lieu de %d", y, symbols, len(runes), builder.ppm.width))
+		}
+		for x, symbol := range runes {
+			color, ok := builder.palette[symbol]
+			if !ok {
+				panic(fmt.Sprintf("netpbm: symbole %q inconnu à la ligne %d (voir Builder.Color)", symbol, y))
+			}
+			builder.Px(x, y, color)
+		}
+		builder.row++
+	}
+	return builder
+}
+
+// PPM renvoie l'image construite. Le Builder peut continuer à servir : les images déjà renvoyées
+// ne sont pas modifiées.
+func (builder *Builder) PPM() *PPM {
+	return builder.ppm.Copy()
+}
+
+// PGM renvoie l'image construite convertie en niveaux de gris, avec les réglages par défaut de
+// Convert.
+func (builder *Builder) PGM() *PGM {
+	pgm, err := convertToPGM(builder.ppm, ConvertOptions{})
+	if err != nil {
+		panic("netpbm: " + err.Error())
+	}
+	return pgm
+}
+
+// PBM renvoie l'image construite convertie en noir et blanc, avec les réglages par défaut de
+// Convert : les pixels plus sombres que le gris moyen deviennent noirs.
+func (builder *Builder) PBM() *PBM {
+	pbm, err := convertToPBM(builder.ppm, ConvertOptions{})
+	if err != nil {
+		panic("netpbm: " + err.Error())
+	}
+	return pbm
+}
@@ -0,0 +1,155 @@
+package netpbm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Opérations en bloc sur des lignes d'octets contiguës. Les lignes PGM et PBM sont déjà contiguës ;
+// les composantes d'une ligne PPM sont rassemblées dans un tampon réutilisé d'une ligne à l'autre,
+// traitées d'un bloc, puis recopiées dans les pixels.
+
+// repeatByte répète un octet dans les huit octets d'un mot de 64 bits.
+func repeatByte(b uint8) uint64 {
+	return uint64(b) * 0x0101010101010101
+}
+
+// subtractFrom remplace chaque octet v de la ligne par max - v (modulo 256, comme la soustraction
+// d'octets), huit octets à la fois. Le bit de poids fort de chaque octet est traité à part pour
+// qu'aucune retenue ne passe d'un octet à l'autre, même pour les niveaux supérieurs à max.
+func subtractFrom(row []uint8, max uint8) {
+	const high = 0x8080808080808080
+	word := repeatByte(max)
+	i := 0
+	for ; i+8 <= len(row); i += 8 {
+		v := binary.LittleEndian.Uint64(row[i:])
+		binary.LittleEndian.PutUint64(row[i:], ((word|high)-(v&^high))^((word^^v)&high))
+	}
+	for ; i < len(row); i++ {
+		row[i] = max - row[i]
+	}
+}
+
+// applyTable remplace chaque octet de la ligne par son image dans la table, par paquets de huit.
+func applyTable(row []uint8, table *[256]uint8) {
+	i := 0
+	for ; i+8 <= len(row); i += 8 {
+		chunk := row[i : i+8 : i+8]
+		chunk[0], chunk[1], chunk[2], chunk[3] = table[chunk[0]], table[chunk[1]], table[chunk[2]], table[chunk[3]]
+		chunk[4], chunk[5], chunk[6], chunk[7] = table[chunk[4]], table[chunk[5]], table[chunk[6]], table[chunk[7]]
+	}
+	for ; i < len(row); i++ {
+		row[i] = table[row[i]]
+	}
+}
+
+// clampedLevel borne un niveau à [0, max] pour le ranger dans une table.
+func clampedLevel(value, max int) uint8 {
+	if value < 0 {
+		return 0
+	}
+	if value > max {
+		return uint8(max)
+	}
+	return uint8(value)
+}
+
+// brightnessTable renvoie la table qui ajoute delta à chaque niveau, bornée à [0, max].
+func brightnessTable(delta, max int) *[256]uint8 {
+	var table [256]uint8
+	for i := range table {
+		table[i] = clampedLevel(i+delta, max)
+	}
+	return &table
+}
+
+// Brightness ajoute delta à chaque niveau de l'image PGM (négatif pour assombrir).
+func (pgm *PGM) Brightness(delta int) {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("brightness %+d", delta)
+	table := brightnessTable(delta, pgm.max)
+	for _, row := range pgm.data {
+		applyTable(row, table)
+	}
+}
+
+// Brightness ajoute delta à chaque composante de l'image PPM (négatif pour assombrir).
+func (ppm *PPM) Brightness(delta int) {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("brightness %+d", delta)
+	table := brightnessTable(delta, ppm.max)
+	buffer := make([]uint8, 3*ppm.width)
+	for _, row := range ppm.data {
+		gatherRow(buffer, row)
+		applyTable(buffer, table)
+		scatterRow(row, buffer)
+	}
+}
+
+// gatherRow copie les composantes des pixels de la ligne dans buffer, à la suite.
+func gatherRow(buffer []uint8, row [][]uint8) {
+	for x, pixel := range row {
+		copy(buffer[3*x:3*x+3], pixel)
+	}
+}
+
+// scatterRow recopie buffer dans les pixels de la ligne, sans les réallouer.
+func scatterRow(row [][]uint8, buffer []uint8) {
+	for x, pixel := range row {
+		copy(pixel, buffer[3*x:3*x+3])
+	}
+}
+
+// blendBytes mélange deux lignes en virgule fixe : dst = dst × (256 - alpha) / 256 + src × alpha / 256.
+func blendBytes(dst, src []uint8, alpha int) {
+	inverse := 256 - alpha
+	for i := range dst {
+		dst[i] = uint8((int(dst[i])*inverse + int(src[i])*alpha + 128) >> 8)
+	}
+}
+
+// alphaFixed convertit une opacité comprise entre 0 et 1 en virgule fixe sur 8 bits.
+func alphaFixed(opacity float64) int {
+	return int(clampFloat(opacity, 0, 1)*256 + 0.5)
+}
+
+// Blend mélange l'image PGM avec other, de même taille et de même valeur maximale, selon l'opacité
+// de other (0 : inchangée, 1 : remplacée par other).
+func (pgm *PGM) Blend(other *PGM, opacity float64) error {
+	if other.width != pgm.width || other.height != pgm.height || other.max != pgm.max {
+		return fmt.Errorf("images incompatibles: %dx%d (max %d) et %dx%d (max %d)",
+			pgm.width, pgm.height, pgm.max, other.width, other.height, other.max)
+	}
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("blend %g", opacity)
+	alpha := alphaFixed(opacity)
+	for y, row := range pgm.data {
+		blendBytes(row, other.data[y], alpha)
+	}
+	return nil
+}
+
+// Blend mélange l'image PPM avec other, de même taille et de même valeur maximale, selon l'opacité
+// de other. Chaque ligne est rassemblée dans un tampon contigu, mélangée d'un bloc puis redistribuée.
+func (ppm *PPM) Blend(other *PPM, opacity float64) error {
+	if other.width != ppm.width || other.height != ppm.height || other.max != ppm.max {
+		return fmt.Errorf("images incompatibles: %dx%d (max %d) et %dx%d (max %d)",
+			ppm.width, ppm.height, ppm.max, other.width, other.height, other.max)
+	}
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("blend %g", opacity)
+	alpha := alphaFixed(opacity)
+	dst := make([]uint8, 3*ppm.width)
+	src := make([]uint8, 3*ppm.width)
+	for y, row := range ppm.data {
+		gatherRow(dst, row)
+		gatherRow(src, other.data[y])
+		blendBytes(dst, src, alpha)
+		scatterRow(row, dst)
+	}
+	return nil
+}
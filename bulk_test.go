@@ -0,0 +1,108 @@
+package netpbm
+
+import "testing"
+
+// Dimensions d'une image 4K UHD.
+const benchWidth, benchHeight = 3840, 2160
+
+func benchPPM() *PPM {
+	ppm := NewPPM(benchWidth, benchHeight, 255)
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			pixel[0], pixel[1], pixel[2] = uint8(x), uint8(y), uint8(x+y)
+		}
+	}
+	return ppm
+}
+
+func benchPGM() *PGM {
+	pgm := NewPGM(benchWidth, benchHeight, 255)
+	for y, row := range pgm.data {
+		for x := range row {
+			row[x] = uint8(x + y)
+		}
+	}
+	return pgm
+}
+
+func TestSubtractFromAboveMax(t *testing.T) {
+	for max := 0; max < 256; max++ {
+		row := make([]uint8, 19)
+		want := make([]uint8, len(row))
+		for i := range row {
+			row[i] = uint8(max + i*37 - 100)
+			want[i] = uint8(max) - row[i]
+		}
+		subtractFrom(row, uint8(max))
+		for i := range row {
+			if row[i] != want[i] {
+				t.Fatalf("max %d, octet %d: %d au lieu de %d", max, i, row[i], want[i])
+			}
+		}
+	}
+}
+
+func TestPGMInvertAboveMax(t *testing.T) {
+	pgm := NewPGM(9, 1, 10)
+	copy(pgm.data[0], []uint8{11, 0, 0, 0, 0, 0, 0, 0, 3})
+	pgm.Invert()
+	want := []uint8{255, 10, 10, 10, 10, 10, 10, 10, 7}
+	for x, value := range pgm.data[0] {
+		if value != want[x] {
+			t.Fatalf("pixel %d: %d au lieu de %d", x, value, want[x])
+		}
+	}
+}
+
+func BenchmarkPGMInvert(b *testing.B) {
+	pgm := benchPGM()
+	b.SetBytes(benchWidth * benchHeight)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pgm.Invert()
+	}
+}
+
+// BenchmarkPGMInvertScalar sert de référence : l'inversion octet par octet.
+func BenchmarkPGMInvertScalar(b *testing.B) {
+	pgm := benchPGM()
+	b.SetBytes(benchWidth * benchHeight)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, row := range pgm.data {
+			for x := range row {
+				row[x] = uint8(pgm.max) - row[x]
+			}
+		}
+	}
+}
+
+func BenchmarkPPMInvert(b *testing.B) {
+	ppm := benchPPM()
+	b.SetBytes(3 * benchWidth * benchHeight)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ppm.Invert()
+	}
+}
+
+func BenchmarkPPMBrightness(b *testing.B) {
+	ppm := benchPPM()
+	b.SetBytes(3 * benchWidth * benchHeight)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ppm.Brightness(1)
+	}
+}
+
+func BenchmarkPPMBlend(b *testing.B) {
+	ppm, other := benchPPM(), benchPPM()
+	other.Invert()
+	b.SetBytes(3 * benchWidth * benchHeight)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ppm.Blend(other, 0.5)
+	}
+}
@@ -0,0 +1,82 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// Calibrate corrige l'image PGM avec une image de noir (dark) et une image de champ plat (flat) :
+// chaque pixel devient (image - dark) / flat × moyenne(flat), puis est écrêté entre 0 et la valeur
+// maximale. L'image de noir supprime le courant d'obscurité et les pixels chauds, le champ plat
+// corrige le vignetage et les poussières. L'une ou l'autre peut valoir nil pour être ignorée.
+func (pgm *PGM) Calibrate(dark, flat *PGM) error {
+	for _, frame := range []*PGM{dark, flat} {
+		if frame != nil && (frame.width != pgm.width || frame.height != pgm.height) {
+			return fmt.Errorf("l'image de calibration mesure %dx%d au lieu de %dx%d", frame.width, frame.height, pgm.width, pgm.height)
+		}
+	}
+
+	var flatMean float64
+	if flat != nil {
+		for _, row := range flat.data {
+			for _, value := range row {
+				flatMean += float64(value)
+			}
+		}
+		flatMean /= float64(pgm.width * pgm.height)
+		if flatMean == 0 {
+			return fmt.Errorf("le champ plat est entièrement noir")
+		}
+	}
+
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("calibrate")
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			value := float64(pgm.data[y][x])
+			if dark != nil {
+				value -= float64(dark.data[y][x])
+			}
+			if flat != nil {
+				// Un pixel mort du champ plat (valeur nulle) est laissé non corrigé.
+				if f := float64(flat.data[y][x]); f > 0 {
+					value = value / f * flatMean
+				}
+			}
+			pgm.data[y][x] = uint8(clampFloat(math.Round(value), 0, float64(pgm.max)))
+		}
+	}
+
+	return nil
+}
+
+// MasterFrame combine plusieurs images de calibration (noirs ou champs plats) en une image maîtresse
+// par moyenne, ce qui réduit leur bruit avant de les passer à Calibrate.
+func MasterFrame(frames []*PGM) (*PGM, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("aucune image de calibration")
+	}
+
+	width, height := frames[0].Size()
+	sums := make([]int, width*height)
+	for i, frame := range frames {
+		if frame.width != width || frame.height != height {
+			return nil, fmt.Errorf("l'image %d mesure %dx%d au lieu de %dx%d", i, frame.width, frame.height, width, height)
+		}
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				sums[y*width+x] += int(frame.data[y][x])
+			}
+		}
+	}
+
+	master := NewPGM(width, height, frames[0].max)
+	n := len(frames)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			master.data[y][x] = uint8((sums[y*width+x] + n/2) / n)
+		}
+	}
+	return master, nil
+}
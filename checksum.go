@@ -0,0 +1,68 @@
+package netpbm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+)
+
+// checksumPrefix commence les commentaires d'empreinte.
+const checksumPrefix = "sha256:"
+
+// L'empreinte porte sur les pixels sous leur forme binaire (celle de P4, P5 et P6), indépendamment
+// de la façon dont ils sont écrits : une image enregistrée en ASCII puis convertie en binaire garde
+// la même empreinte, et seuls les pixels sont protégés, pas la mise en forme du fichier.
+
+// formatChecksum renvoie le commentaire d'empreinte correspondant au hachage.
+func formatChecksum(h hash.Hash) string {
+	return checksumPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// rasterChecksum renvoie le commentaire d'empreinte des pixels de l'image.
+func rasterChecksum(img Image) string {
+	h := sha256.New()
+	switch img := img.(type) {
+	case *PBM:
+		packed := make([]byte, (img.width+7)/8)
+		for _, row := range img.data {
+			packRow(packed, row)
+			h.Write(packed)
+		}
+	case *PackedPBM:
+		return img.checksum()
+	case *PGM:
+		for _, row := range img.data {
+			h.Write(row)
+		}
+	case *PPM:
+		line := make([]byte, 0, 3*img.width)
+		for _, row := range img.data {
+			line = line[:0]
+			for _, pixel := range row {
+				line = append(line, pixel[:3]...)
+			}
+			h.Write(line)
+		}
+	}
+	return formatChecksum(h)
+}
+
+// checksum renvoie le commentaire d'empreinte des pixels de l'image compacte.
+func (packed *PackedPBM) checksum() string {
+	h := sha256.New()
+	h.Write(packed.bits)
+	return formatChecksum(h)
+}
+
+// verifyChecksum vérifie l'empreinte lue dans l'en-tête de l'image, s'il y en a une.
+func verifyChecksum(img Image) error {
+	meta := metadataOf(img)
+	if meta == nil || meta.Checksum == "" || DefaultDecodeOptions.IgnoreChecksum {
+		return nil
+	}
+	if actual := rasterChecksum(img); actual != meta.Checksum {
+		return fmt.Errorf("empreinte incorrecte, fichier corrompu: %s attendu, %s obtenu", meta.Checksum, actual)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package netpbm
+
+// Zones de découpe : PushClipRect et PushClipPath limitent les méthodes de dessin de l'image PPM
+// (DrawLine, DrawFilledPolygon, FillPath, DrawText…) à une zone, jusqu'au PopClip correspondant.
+// Les zones s'empilent : chacune est l'intersection de la zone demandée et de la précédente. Les
+// opérations qui transforment l'image entière (Invert, Rotate90CW, BlurRegions…) et Set ne sont pas
+// concernées.
+
+// clipRegion est une zone de découpe : les pixels de bounds, ou seulement ceux marqués dans mask
+// s'il n'est pas nil (mask[y][x] pour le pixel (bounds.X+x, bounds.Y+y)).
+type clipRegion struct {
+	bounds Rect
+	mask   [][]bool
+}
+
+// contains indique si le pixel (x, y) est dans la zone.
+func (clip *clipRegion) contains(x, y int) bool {
+	x, y = x-clip.bounds.X, y-clip.bounds.Y
+	if x < 0 || x >= clip.bounds.Width || y < 0 || y >= clip.bounds.Height {
+		return false
+	}
+	return clip.mask == nil || clip.mask[y][x]
+}
+
+// clip renvoie la zone de découpe en cours, ou nil si le dessin n'est pas limité.
+func (ppm *PPM) clip() *clipRegion {
+	if len(ppm.clips) == 0 {
+		return nil
+	}
+	return &ppm.clips[len(ppm.clips)-1]
+}
+
+// visible indique si le dessin peut modifier le pixel (x, y) : il est dans l'image et dans la zone
+// de découpe en cours.
+func (ppm *PPM) visible(x, y int) bool {
+	if x < 0 || x >= ppm.width || y < 0 || y >= ppm.height {
+		return false
+	}
+	clip := ppm.clip()
+	return clip == nil || clip.contains(x, y)
+}
+
+// pushClip empile la zone des pixels de bounds pour lesquels inside est vrai (tous si inside est
+// nil), restreinte à l'image et à la zone en cours.
+func (ppm *PPM) pushClip(bounds Rect, inside func(x, y int) bool) {
+	bounds = bounds.Intersect(Rect{Width: ppm.width, Height: ppm.height})
+	previous := ppm.clip()
+	if previous != nil {
+		bounds = bounds.Intersect(previous.bounds)
+		if previous.mask != nil {
+			if inside == nil {
+				inside = previous.contains
+			} else {
+				shape := inside
+				inside = func(x, y int) bool { return shape(x, y) && previous.contains(x, y) }
+			}
+		}
+	}
+
+	region := clipRegion{bounds: bounds}
+	if inside != nil {
+		region.mask = make([][]bool, bounds.Height)
+		for y := range region.mask {
+			region.mask[y] = make([]bool, bounds.Width)
+			for x := range region.mask[y] {
+				region.mask[y][x] = inside(bounds.X+x, bounds.Y+y)
+			}
+		}
+	}
+	ppm.clips = append(ppm.clips, region)
+}
+
+// PushClipRect limite le dessin à la zone r du repère en cours, jusqu'au PopClip correspondant.
+func (ppm *PPM) PushClipRect(r Rect) {
+	if ppm.transform != nil {
+		ppm.PushClipPath(rectPath(r), FillNonZero)
+		return
+	}
+	ppm.pushClip(r, nil)
+}
+
+// PushClipPath limite le dessin à l'intérieur du tracé, exprimé dans le repère en cours, selon la
+// règle de remplissage (voir FillPath), jusqu'au PopClip correspondant.
+func (ppm *PPM) PushClipPath(path *Path, rule FillRule) {
+	shape := NewPBM(ppm.width, ppm.height)
+	bounds := Rect{}
+	ppm.toDevicePath(path).fillSpans(ppm.width, ppm.height, rule, func(y, startX, endX int) {
+		for x := startX; x <= endX; x++ {
+			shape.data[y][x] = true
+		}
+		bounds = bounds.Union(Rect{X: startX, Y: y, Width: endX - startX + 1, Height: 1})
+	})
+	ppm.pushClip(bounds, func(x, y int) bool { return shape.data[y][x] })
+}
+
+// PopClip retire la dernière zone de découpe empilée ; il est sans effet si la pile est vide.
+func (ppm *PPM) PopClip() {
+	if len(ppm.clips) > 0 {
+		ppm.clips = ppm.clips[:len(ppm.clips)-1]
+	}
+}
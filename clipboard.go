@@ -0,0 +1,15 @@
+package netpbm
+
+import "bytes"
+
+// CopyToClipboard copie l'image dans le presse-papiers du système, pour la coller directement dans
+// un document ou une messagerie. L'image est encodée en PNG puis confiée à l'outil du système
+// (voir copyPNG) : xclip ou wl-copy sous Linux, osascript sous macOS et PowerShell sous Windows,
+// qui la convertit en bitmap.
+func CopyToClipboard(img Image) error {
+	var buffer bytes.Buffer
+	if err := encodePNG(&buffer, img); err != nil {
+		return err
+	}
+	return copyPNG(buffer.Bytes())
+}
@@ -0,0 +1,32 @@
+//go:build darwin
+
+package netpbm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// copyPNG copie une image PNG dans le presse-papiers avec osascript, qui la lit depuis un fichier temporaire.
+func copyPNG(data []byte) error {
+	file, err := os.CreateTemp("", "netpbm-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf("set the clipboard to (read (POSIX file %q) as «class PNGf»)", file.Name())
+	if output, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("copie dans le presse-papiers: %v %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !freebsd && !netbsd && !openbsd && !dragonfly && !darwin && !windows
+
+package netpbm
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// copyPNG signale que le presse-papiers n'est pas pris en charge sur ce système.
+func copyPNG(data []byte) error {
+	return fmt.Errorf("presse-papiers non pris en charge sur %s", runtime.GOOS)
+}
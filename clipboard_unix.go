@@ -0,0 +1,27 @@
+//go:build linux || freebsd || netbsd || openbsd || dragonfly
+
+package netpbm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// copyPNG copie une image PNG dans le presse-papiers avec wl-copy sous Wayland, xclip sous X11.
+func copyPNG(data []byte) error {
+	var cmd *exec.Cmd
+	if _, err := exec.LookPath("wl-copy"); err == nil && os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmd = exec.Command("wl-copy", "--type", "image/png")
+	} else if _, err := exec.LookPath("xclip"); err == nil {
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "image/png", "-i")
+	} else {
+		return fmt.Errorf("presse-papiers indisponible: xclip ou wl-copy requis")
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("copie dans le presse-papiers: %v %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
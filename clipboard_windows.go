@@ -0,0 +1,38 @@
+//go:build windows
+
+package netpbm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// copyPNG copie une image dans le presse-papiers avec PowerShell : System.Drawing lit le PNG depuis
+// un fichier temporaire et le presse-papiers le reçoit en bitmap (CF_BITMAP/CF_DIB), le format que
+// comprennent toutes les applications Windows.
+func copyPNG(data []byte) error {
+	file, err := os.CreateTemp("", "netpbm-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	script := "Add-Type -AssemblyName System.Windows.Forms, System.Drawing; " +
+		"$image = [System.Drawing.Image]::FromFile('" + strings.ReplaceAll(file.Name(), "'", "''") + "'); " +
+		"[System.Windows.Forms.Clipboard]::SetImage($image); $image.Dispose()"
+	cmd := exec.Command("powershell", "-NoProfile", "-STA", "-Command", script)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("copie dans le presse-papiers: %v %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
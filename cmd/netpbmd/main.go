@@ -0,0 +1,91 @@
+// Commande netpbmd : surveille un répertoire et convertit les images Netpbm qui y arrivent
+// (depuis un scanner, une ferme de rendu…) vers un répertoire de sortie.
+//
+//	netpbmd -in entrant -out converti -format P6 -max-width 1920
+//	netpbmd -in entrant -out converti -format P2 -eval 'v = max - v' -once
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/eliiimk/Netpbm"
+)
+
+func main() {
+	input := flag.String("in", "", "répertoire surveillé")
+	output := flag.String("out", "", "répertoire de sortie")
+	format := flag.String("format", "P6", "format de sortie (P1 à P6)")
+	maxWidth := flag.Int("max-width", 0, "largeur maximale (0 = inchangée)")
+	maxHeight := flag.Int("max-height", 0, "hauteur maximale (0 = inchangée)")
+	interval := flag.Duration("interval", 0, "intervalle de scrutation (2s par défaut)")
+	eval := flag.String("eval", "", "programme appliqué à chaque image convertie (r, g, b en PPM, v en PGM)")
+	once := flag.Bool("once", false, "faire un seul passage puis quitter")
+	flag.Parse()
+
+	if *input == "" || *output == "" {
+		fmt.Fprintln(os.Stderr, "netpbmd: -in et -out sont obligatoires")
+		flag.Usage()
+		os.Exit(2)
+	}
+	target, err := netpbm.ParseFormat(strings.ToUpper(*format))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "netpbmd:", err)
+		os.Exit(2)
+	}
+
+	// Le programme est compilé d'emblée pour signaler une erreur de syntaxe avant toute conversion.
+	if *eval != "" {
+		var channels []string
+		switch target {
+		case netpbm.FormatP2, netpbm.FormatP5:
+			channels = []string{"v"}
+		case netpbm.FormatP3, netpbm.FormatP6:
+			channels = []string{"r", "g", "b"}
+		default:
+			fmt.Fprintln(os.Stderr, "netpbmd: -eval ne s'applique pas au format", target)
+			os.Exit(2)
+		}
+		if _, err := netpbm.Compile(*eval, channels...); err != nil {
+			fmt.Fprintln(os.Stderr, "netpbmd:", err)
+			os.Exit(2)
+		}
+	}
+
+	watcher := &netpbm.FolderWatcher{
+		Input:    *input,
+		Output:   *output,
+		Preset:   netpbm.ConvertPreset{Format: target, MaxWidth: *maxWidth, MaxHeight: *maxHeight, Eval: *eval},
+		Interval: *interval,
+		Logf:     log.Printf,
+	}
+
+	// Un fichier n'est converti qu'une fois stable, c'est-à-dire vu inchangé lors de deux passages :
+	// -once fait donc deux passages consécutifs.
+	if *once {
+		for i := 0; i < 2; i++ {
+			if _, err := watcher.Scan(); err != nil {
+				log.Fatal(err)
+			}
+		}
+		return
+	}
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	log.Printf("surveillance de %s → %s (%s)", *input, *output, target)
+	if err := watcher.Run(stop); err != nil {
+		log.Fatal(err)
+	}
+}
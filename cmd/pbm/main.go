@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/eliiimk/Netpbm"
+)
+
+func main() {
+	// Exemple d'utilisation
+	image, err := netpbm.ReadPBM("exemple.pbm")
+	if err != nil {
+		fmt.Println("Erreur lors de la lecture de l'image PBM :", err)
+		return
+	}
+
+	// Afficher les informations de l'image
+	fmt.Printf("Nombre magique : %s\n", image.MagicNumber())
+	width, height := image.Size()
+	fmt.Printf("Dimensions du dessin : %d x %d\n", width, height)
+
+	// Afficher la valeur d'un pixel (par exemple, à la position (2, 3))
+	value := image.At(2, 3)
+	fmt.Printf("Valeur du pixel à la position (2, 3) : %t\n", value)
+
+	// Appliquer des opérations (par exemple, Inverser, Flip, Flop)
+
+	fmt.Println("Image normale:")
+	image.Display()
+
+	// Affiche l'image inversée
+	image.Invert()
+	fmt.Println("Image inversée:")
+	image.Display()
+
+	image.Flip()
+	fmt.Println("Image inversée horizontalement:")
+	image.Display()
+
+	image.Flop()
+	fmt.Println("Image inversée verticalement:")
+	image.Display()
+	// Enregistrer l'image modifiée
+	err = image.Save("image_modifiee.pbm")
+	if err != nil {
+		fmt.Println("Erreur lors de l'enregistrement de l'image PBM :", err)
+		return
+	}
+}
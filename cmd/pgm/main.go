@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/eliiimk/Netpbm"
+)
+
+func main() {
+	// Exemple d'utilisation
+	pgm, err := netpbm.ReadPGM("exemple.pgm")
+	if err != nil {
+		fmt.Println("Erreur lors de la lecture de l'image PGM:", err)
+		return
+	}
+	nouveauNombreMagique := "P2"
+	pgm.SetMagicNumber(nouveauNombreMagique)
+	fmt.Println()
+	fmt.Println("Nouveau nombre magique:", pgm.MagicNumber())
+
+	width, height := pgm.Size()
+	fmt.Println()
+	fmt.Println("Taille de l'image:", width, height)
+
+	// Afficher la nouvelle valeur maximale
+	ValeurMax := uint8(10)
+	pgm.SetMaxValue(ValeurMax)
+	fmt.Println()
+	fmt.Println("La valeur maximale de l'image:", pgm.MaxValue())
+
+	x, y := 0, 9
+	pixelValue := pgm.At(x, y)
+	fmt.Println()
+	fmt.Printf("Valeur du pixel à la position (%d, %d): %d\n", x, y, pixelValue)
+
+	newPixelValue := uint8(4) // Nouvelle valeur que vous souhaitez définir
+	pgm.Set(x, y, newPixelValue)
+	fmt.Println()
+	fmt.Printf("Nouvelle valeur du pixel à la position (%d, %d): %d\n", x, y, pgm.At(x, y))
+
+	fmt.Println()
+	fmt.Println("Image normale :")
+	fmt.Println()
+	pgm.Display()
+	fmt.Println()
+
+	pgm.Invert()
+	fmt.Println("Image avec les couleurs inversé :")
+	fmt.Println()
+	pgm.Display()
+	fmt.Println()
+
+	pgm.Flip()
+	fmt.Println("Image retourner à l'horizontalement :")
+	fmt.Println()
+	pgm.Display()
+	fmt.Println()
+
+	pgm.Flop()
+	fmt.Println("Image renverser à la verticalement :")
+	fmt.Println()
+	pgm.Display()
+	fmt.Println()
+
+	pgm.Rotate90CW()
+	fmt.Println("Image tourné à 90° dans le sens des aiguilles d'une montre :")
+	fmt.Println()
+	pgm.Display()
+	fmt.Println()
+
+	// Sauvegarde de l'image modifiée
+	err = pgm.Save("image_modifiee.pgm")
+	if err != nil {
+		fmt.Println("Erreur lors de l'enregistrement de l'image PGM modifiée:", err)
+		return
+	}
+
+	// Conversion en PBM
+	pbm := pgm.ToPBM()
+
+	// Affichage de l'image PBM
+	pbmWidth, pbmHeight := pbm.Size()
+	fmt.Println()
+	fmt.Println("Taille de l'image PBM:", pbmWidth, pbmHeight)
+	fmt.Println()
+	fmt.Println("Image PBM:")
+	fmt.Println()
+	pbm.Display()
+
+	// Sauvegarde de l'image PBM
+	err = pbm.Save("image_pbm.pbm")
+	if err != nil {
+		fmt.Println("Erreur lors de l'enregistrement de l'image PBM:", err)
+		return
+	}
+}
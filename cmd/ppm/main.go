@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/eliiimk/Netpbm"
+)
+
+func main() {
+	// Exemple d'utilisation
+	ppm, err := netpbm.ReadPPM("exemple.ppm")
+	if err != nil {
+		fmt.Println("Erreur lors de la lecture de l'image PPM:", err)
+		return
+	}
+	nouveauNombreMagique := "P3"
+	ppm.SetMagicNumber(nouveauNombreMagique)
+	fmt.Println()
+	fmt.Println("Nouveau nombre magique:", ppm.MagicNumber())
+
+	width, height := ppm.Size()
+	fmt.Println()
+	fmt.Println("Taille de l'image:", width, height)
+
+	x, y := 0, 9
+	pixelValue := ppm.At(x, y)
+	fmt.Println()
+	fmt.Printf("Valeur du pixel à la position (%d, %d): %v\n", x, y, pixelValue)
+
+	newPixelValue := []uint8{100, 150, 200} // Nouvelle valeur que vous souhaitez définir
+	ppm.Set(x, y, newPixelValue)
+	fmt.Println()
+	fmt.Printf("Nouvelle valeur du pixel à la position (%d, %d): %v\n", x, y, ppm.At(x, y))
+
+	fmt.Println()
+	fmt.Println("Image normale :")
+	fmt.Println()
+	ppm.Display()
+	fmt.Println()
+
+	ppm.Invert()
+	fmt.Println("Image avec les couleurs inversées :")
+	fmt.Println()
+	ppm.Display()
+	fmt.Println()
+
+	ppm.Flip()
+	fmt.Println("Image retournée horizontalement :")
+	fmt.Println()
+	ppm.Display()
+	fmt.Println()
+
+	ppm.Flop()
+	fmt.Println("Image renversée verticalement :")
+	fmt.Println()
+	ppm.Display()
+	fmt.Println()
+
+	ppm.Rotate90CW()
+	fmt.Println("Image tournée à 90° dans le sens des aiguilles d'une montre :")
+	fmt.Println()
+	ppm.Display()
+	fmt.Println()
+
+	// Création d'une nouvelle instance de netpbm.Point avec de nouvelles valeurs
+	newPoint := netpbm.Point{X: 3, Y: 5}
+	newPixel := []uint8{100, 150, 200}
+
+	// Utilisation de la nouvelle instance de netpbm.Point pour définir un nouveau pixel
+	ppm.Set(newPoint.X, newPoint.Y, newPixel)
+	fmt.Printf("Nouvelle valeur du pixel à la position (%d, %d): %v\n", newPoint.X, newPoint.Y, ppm.At(newPoint.X, newPoint.Y))
+	fmt.Println()
+
+	point1 := netpbm.Point{X: 10, Y: 10}
+	point2 := netpbm.Point{X: 50, Y: 30}
+
+	couleurLigne := netpbm.Pixel{Red: 255, Green: 0, Blue: 0} // Rouge
+
+	// Tracez une ligne entre les deux points
+	ppm.DrawLine(point1, point2, couleurLigne)
+	fmt.Println("ligne :")
+	fmt.Println()
+	ppm.Display()
+	fmt.Println()
+
+	// Couleur du rectangle
+	couleurRectangle := netpbm.Pixel{Red: 0, Green: 0, Blue: 255} // Bleu
+
+	// Dessiner un rectangle rempli dans l'image PPM
+	ppm.DrawFilledRectangle(point1, width, height, couleurRectangle)
+
+	// Affichage de l'image avec le rectangle rempli
+	fmt.Println("Rectangle rempli :")
+	fmt.Println()
+	ppm.Display()
+	fmt.Println()
+
+	// Dessiner un triangle
+	p1 := netpbm.Point{X: 5, Y: 5}
+	p2 := netpbm.Point{X: 10, Y: 15}
+	p3 := netpbm.Point{X: 15, Y: 5}
+	ppm.DrawTriangle(p1, p2, p3, netpbm.Pixel{Red: 255, Green: 0, Blue: 0})
+
+	// Affichage de l'image avec la ligne dessinée
+	fmt.Println("triangle:")
+	fmt.Println()
+	ppm.Display()
+
+	couleurTriangle := netpbm.Pixel{Red: 0, Green: 255, Blue: 0} // Vert
+	ppm.DrawFilledTriangle(p1, p2, p3, couleurTriangle)
+
+	// Affichage de l'image avec le triangle rempli
+	fmt.Println("Triangle rempli :")
+	fmt.Println()
+	ppm.Display()
+	fmt.Println()
+
+	centreCercle := netpbm.Point{X: 30, Y: 30}
+	rayonCercle := 15
+	couleurCercle := netpbm.Pixel{Red: 255, Green: 0, Blue: 0} // Rouge
+	ppmCercle := ppm.Copy()                                    // Créer une copie de l'image originale
+	ppmCercle.DrawCircle(centreCercle, rayonCercle, couleurCercle)
+
+	// Affichage de l'image avec le cercle
+	fmt.Println("Image avec le cercle :")
+	ppmCercle.Display()
+	fmt.Println()
+
+	// Dessiner un cercle rempli
+	centreCercleRempli := netpbm.Point{X: 80, Y: 30}
+	rayonCercleRempli := 10
+	couleurCercleRempli := netpbm.Pixel{Red: 0, Green: 255, Blue: 0} // Vert
+	ppmCercleRempli := ppm.Copy()                                    // Créer une copie de l'image originale
+	ppmCercleRempli.DrawFilledCircle(centreCercleRempli, rayonCercleRempli, couleurCercleRempli)
+
+	// Affichage de l'image avec le cercle rempli
+	fmt.Println("Image avec le cercle rempli :")
+	ppmCercleRempli.Display()
+	fmt.Println()
+
+	// Dessiner un polygone
+	pointsPolygone := []netpbm.Point{
+		{X: 10, Y: 60},
+		{X: 20, Y: 80},
+		{X: 30, Y: 60},
+		{X: 25, Y: 50},
+	}
+	couleurPolygone := netpbm.Pixel{Red: 0, Green: 0, Blue: 255} // Bleu
+	ppmPolygone := ppm.Copy()                                    // Créer une copie de l'image originale
+	ppmPolygone.DrawPolygon(pointsPolygone, couleurPolygone)
+
+	// Affichage de l'image avec le polygone
+	fmt.Println("Image avec le polygone :")
+	ppmPolygone.Display()
+	fmt.Println()
+
+	// Dessiner un polygone rempli
+	pointsPolygoneRempli := []netpbm.Point{
+		{X: 60, Y: 60},
+		{X: 70, Y: 80},
+		{X: 80, Y: 60},
+		{X: 75, Y: 50},
+	}
+	couleurPolygoneRempli := netpbm.Pixel{Red: 255, Green: 255, Blue: 0} // Jaune
+	ppmPolygoneRempli := ppm.Copy()                                      // Créer une copie de l'image originale
+	ppmPolygoneRempli.DrawFilledPolygon(pointsPolygoneRempli, couleurPolygoneRempli)
+
+	// Affichage de l'image avec le polygone rempli
+	fmt.Println("Image avec le polygone rempli :")
+	ppmPolygoneRempli.Display()
+	fmt.Println()
+
+	// Sauvegarde de l'image modifiée
+	err = ppm.Save("image_modifiee.ppm")
+	if err != nil {
+		fmt.Println("Erreur lors de l'enregistrement de l'image PPM modifiée:", err)
+		return
+	}
+}
@@ -0,0 +1,227 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// LinearRGB représente une couleur en RVB linéaire (composantes entre 0 et 1, primaires sRGB).
+type LinearRGB struct {
+	R, G, B float64
+}
+
+// XYZ représente une couleur dans l'espace CIE 1931 XYZ (blanc D65, Y = 1 pour le blanc).
+type XYZ struct {
+	X, Y, Z float64
+}
+
+// Lab représente une couleur dans l'espace CIELAB (blanc D65).
+type Lab struct {
+	L, A, B float64
+}
+
+// Blanc de référence D65.
+const (
+	whiteX = 0.95047
+	whiteY = 1.0
+	whiteZ = 1.08883
+)
+
+// ToLinear convertit le pixel sRGB (valeurs entre 0 et max) en RVB linéaire.
+func (p Pixel) ToLinear(max int) LinearRGB {
+	if max <= 0 {
+		max = 255
+	}
+	m := float64(max)
+	return LinearRGB{
+		R: srgbToLinear(float64(p.Red) / m),
+		G: srgbToLinear(float64(p.Green) / m),
+		B: srgbToLinear(float64(p.Blue) / m),
+	}
+}
+
+// ToPixel convertit la couleur linéaire en pixel sRGB de valeur maximale max (avec écrêtage).
+func (c LinearRGB) ToPixel(max int) Pixel {
+	if max <= 0 {
+		max = 255
+	}
+	return Pixel{
+		Red:   encodeValue(c.R, max, true),
+		Green: encodeValue(c.G, max, true),
+		Blue:  encodeValue(c.B, max, true),
+	}
+}
+
+// ToXYZ convertit la couleur linéaire en XYZ.
+func (c LinearRGB) ToXYZ() XYZ {
+	return XYZ{
+		X: 0.4124564*c.R + 0.3575761*c.G + 0.1804375*c.B,
+		Y: 0.2126729*c.R + 0.7151522*c.G + 0.0721750*c.B,
+		Z: 0.0193339*c.R + 0.1191920*c.G + 0.9503041*c.B,
+	}
+}
+
+// ToLinear convertit la couleur XYZ en RVB linéaire (sans écrêtage).
+func (c XYZ) ToLinear() LinearRGB {
+	return LinearRGB{
+		R: 3.2404542*c.X - 1.5371385*c.Y - 0.4985314*c.Z,
+		G: -0.9692660*c.X + 1.8760108*c.Y + 0.0415560*c.Z,
+		B: 0.0556434*c.X - 0.2040259*c.Y + 1.0572252*c.Z,
+	}
+}
+
+// labF est la fonction de compression de CIELAB.
+func labF(t float64) float64 {
+	const delta = 6.0 / 29
+	if t > delta*delta*delta {
+		return math.Cbrt(t)
+	}
+	return t/(3*delta*delta) + 4.0/29
+}
+
+// labFInverse est la réciproque de labF.
+func labFInverse(t float64) float64 {
+	const delta = 6.0 / 29
+	if t > delta {
+		return t * t * t
+	}
+	return 3 * delta * delta * (t - 4.0/29)
+}
+
+// ToLab convertit la couleur XYZ en CIELAB.
+func (c XYZ) ToLab() Lab {
+	fx, fy, fz := labF(c.X/whiteX), labF(c.Y/whiteY), labF(c.Z/whiteZ)
+	return Lab{
+		L: 116*fy - 16,
+		A: 500 * (fx - fy),
+		B: 200 * (fy - fz),
+	}
+}
+
+// ToXYZ convertit la couleur CIELAB en XYZ.
+func (c Lab) ToXYZ() XYZ {
+	fy := (c.L + 16) / 116
+	return XYZ{
+		X: whiteX * labFInverse(fy+c.A/500),
+		Y: whiteY * labFInverse(fy),
+		Z: whiteZ * labFInverse(fy-c.B/200),
+	}
+}
+
+// ToLab convertit le pixel sRGB (valeurs entre 0 et max) en CIELAB.
+func (p Pixel) ToLab(max int) Lab {
+	return p.ToLinear(max).ToXYZ().ToLab()
+}
+
+// ToPixel convertit la couleur CIELAB en pixel sRGB de valeur maximale max.
+func (c Lab) ToPixel(max int) Pixel {
+	return c.ToXYZ().ToLinear().ToPixel(max)
+}
+
+// LabAt renvoie la couleur CIELAB du pixel en (x, y).
+func (ppm *PPM) LabAt(x, y int) Lab {
+	return ppm.pixel(x, y).ToLab(ppm.max)
+}
+
+// DeltaE76 renvoie la différence de couleur CIE76 (distance euclidienne dans CIELAB).
+func DeltaE76(a, b Lab) float64 {
+	return math.Sqrt((a.L-b.L)*(a.L-b.L) + (a.A-b.A)*(a.A-b.A) + (a.B-b.B)*(a.B-b.B))
+}
+
+// DeltaE2000 renvoie la différence de couleur CIEDE2000, plus fidèle à la perception que CIE76.
+func DeltaE2000(a, b Lab) float64 {
+	const pow25to7 = 6103515625.0 // 25^7
+
+	c1 := math.Hypot(a.A, a.B)
+	c2 := math.Hypot(b.A, b.B)
+	cMean := (c1 + c2) / 2
+	cMean7 := math.Pow(cMean, 7)
+	g := 0.5 * (1 - math.Sqrt(cMean7/(cMean7+pow25to7)))
+
+	a1, a2 := (1+g)*a.A, (1+g)*b.A
+	c1p, c2p := math.Hypot(a1, a.B), math.Hypot(a2, b.B)
+	h1p, h2p := hueAngle(a.B, a1), hueAngle(b.B, a2)
+
+	deltaL := b.L - a.L
+	deltaC := c2p - c1p
+	var deltaH float64
+	if c1p*c2p != 0 {
+		dh := h2p - h1p
+		if dh > 180 {
+			dh -= 360
+		} else if dh < -180 {
+			dh += 360
+		}
+		deltaH = 2 * math.Sqrt(c1p*c2p) * math.Sin(dh*math.Pi/360)
+	}
+
+	lMean := (a.L + b.L) / 2
+	cpMean := (c1p + c2p) / 2
+	hMean := h1p + h2p
+	if c1p*c2p != 0 {
+		if math.Abs(h1p-h2p) > 180 {
+			if hMean < 360 {
+				hMean += 360
+			} else {
+				hMean -= 360
+			}
+		}
+		hMean /= 2
+	}
+
+	rad := math.Pi / 180
+	t := 1 - 0.17*math.Cos((hMean-30)*rad) + 0.24*math.Cos(2*hMean*rad) +
+		0.32*math.Cos((3*hMean+6)*rad) - 0.20*math.Cos((4*hMean-63)*rad)
+	deltaTheta := 30 * math.Exp(-((hMean-275)/25)*((hMean-275)/25))
+	cpMean7 := math.Pow(cpMean, 7)
+	rc := 2 * math.Sqrt(cpMean7/(cpMean7+pow25to7))
+	l50 := (lMean - 50) * (lMean - 50)
+	sl := 1 + 0.015*l50/math.Sqrt(20+l50)
+	sc := 1 + 0.045*cpMean
+	sh := 1 + 0.015*cpMean*t
+	rt := -math.Sin(2*deltaTheta*rad) * rc
+
+	dl, dc, dh := deltaL/sl, deltaC/sc, deltaH/sh
+	return math.Sqrt(dl*dl + dc*dc + dh*dh + rt*dc*dh)
+}
+
+// hueAngle renvoie l'angle de teinte en degrés dans [0, 360).
+func hueAngle(b, a float64) float64 {
+	if a == 0 && b == 0 {
+		return 0
+	}
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// DeltaEStats contient la carte des différences de couleur entre deux images et ses statistiques.
+type DeltaEStats struct {
+	Map       [][]float64
+	Mean, Max float64
+}
+
+// DeltaEImage calcule la différence CIEDE2000 pixel par pixel entre deux images de même taille.
+func DeltaEImage(a, b *PPM) (*DeltaEStats, error) {
+	if a.width != b.width || a.height != b.height {
+		return nil, fmt.Errorf("les images n'ont pas la même taille: %dx%d et %dx%d", a.width, a.height, b.width, b.height)
+	}
+
+	stats := &DeltaEStats{Map: make([][]float64, a.height)}
+	for y := 0; y < a.height; y++ {
+		stats.Map[y] = make([]float64, a.width)
+		for x := 0; x < a.width; x++ {
+			d := DeltaE2000(a.LabAt(x, y), b.LabAt(x, y))
+			stats.Map[y][x] = d
+			stats.Mean += d
+			stats.Max = math.Max(stats.Max, d)
+		}
+	}
+	if n := a.width * a.height; n > 0 {
+		stats.Mean /= float64(n)
+	}
+
+	return stats, nil
+}
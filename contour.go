@@ -0,0 +1,165 @@
+package netpbm
+
+import "math"
+
+// mooreNeighbors liste les 8 voisins dans le sens des aiguilles d'une montre (axe Y vers le bas), en partant de l'ouest.
+var mooreNeighbors = [8]Point{{-1, 0}, {-1, -1}, {0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}}
+
+// isSet indique si le pixel (x, y) est noir ; les pixels hors de l'image sont considérés blancs.
+func (pbm *PBM) isSet(x, y int) bool {
+	return x >= 0 && x < pbm.width && y >= 0 && y < pbm.height && pbm.data[y][x]
+}
+
+// TraceContours renvoie le contour extérieur de chaque composante connexe (8-connexité) de pixels
+// noirs, sous forme de polygone fermé (le dernier point n'est pas répété). Les contours sont obtenus
+// par suivi de Moore et parcourus dans le sens des aiguilles d'une montre.
+func (pbm *PBM) TraceContours() [][]Point {
+	visited := make([][]bool, pbm.height)
+	for y := range visited {
+		visited[y] = make([]bool, pbm.width)
+	}
+
+	var contours [][]Point
+	for y := 0; y < pbm.height; y++ {
+		for x := 0; x < pbm.width; x++ {
+			if !pbm.data[y][x] || visited[y][x] {
+				continue
+			}
+			// Premier pixel de la composante dans l'ordre de balayage : son voisin ouest est blanc.
+			contours = append(contours, pbm.traceFrom(Point{X: x, Y: y}))
+			pbm.markComponent(visited, x, y)
+		}
+	}
+	return contours
+}
+
+// traceFrom suit le contour de Moore à partir du pixel de départ, dont le voisin ouest est blanc.
+func (pbm *PBM) traceFrom(start Point) []Point {
+	contour := []Point{start}
+	current := start
+	backtrack := Point{X: start.X - 1, Y: start.Y}
+	startBacktrack := backtrack
+
+	for steps := 0; steps < 4*pbm.width*pbm.height+4; steps++ {
+		// Direction du pixel de retour vu depuis le pixel courant.
+		from := 0
+		for d, n := range mooreNeighbors {
+			if current.X+n.X == backtrack.X && current.Y+n.Y == backtrack.Y {
+				from = d
+				break
+			}
+		}
+
+		found := false
+		previous := backtrack
+		for i := 1; i <= 8; i++ {
+			n := mooreNeighbors[(from+i)%8]
+			candidate := Point{X: current.X + n.X, Y: current.Y + n.Y}
+			if pbm.isSet(candidate.X, candidate.Y) {
+				backtrack = previous
+				current = candidate
+				found = true
+				break
+			}
+			previous = candidate
+		}
+		if !found {
+			// Pixel isolé.
+			return contour
+		}
+
+		// Critère d'arrêt de Jacob : retour au départ par le même côté.
+		if current == start && backtrack == startBacktrack {
+			return contour
+		}
+		if current == start {
+			continue
+		}
+		contour = append(contour, current)
+	}
+	return contour
+}
+
+// markComponent marque comme visités tous les pixels noirs 8-connexes au pixel (x, y).
+func (pbm *PBM) markComponent(visited [][]bool, x, y int) {
+	stack := []Point{{X: x, Y: y}}
+	visited[y][x] = true
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, n := range mooreNeighbors {
+			nx, ny := p.X+n.X, p.Y+n.Y
+			if pbm.isSet(nx, ny) && !visited[ny][nx] {
+				visited[ny][nx] = true
+				stack = append(stack, Point{X: nx, Y: ny})
+			}
+		}
+	}
+}
+
+// SimplifyPolygon simplifie un polygone fermé par l'algorithme de Douglas-Peucker : les sommets
+// situés à moins de epsilon pixels du tracé simplifié sont supprimés.
+func SimplifyPolygon(points []Point, epsilon float64) []Point {
+	if len(points) < 4 {
+		return append([]Point(nil), points...)
+	}
+
+	// Couper le polygone fermé en deux chaînes, au sommet le plus éloigné du premier.
+	far := 0
+	var farDistance float64
+	for i, p := range points {
+		if d := math.Hypot(float64(p.X-points[0].X), float64(p.Y-points[0].Y)); d > farDistance {
+			far, farDistance = i, d
+		}
+	}
+	if far == 0 {
+		return []Point{points[0]}
+	}
+
+	first := simplifyChain(points[:far+1], epsilon)
+	second := simplifyChain(append(append([]Point(nil), points[far:]...), points[0]), epsilon)
+	return append(first[:len(first)-1], second[:len(second)-1]...)
+}
+
+// SimplifyPolyline simplifie une ligne brisée ouverte par l'algorithme de Douglas-Peucker.
+func SimplifyPolyline(points []Point, epsilon float64) []Point {
+	if len(points) < 3 {
+		return append([]Point(nil), points...)
+	}
+	return simplifyChain(points, epsilon)
+}
+
+// simplifyChain applique récursivement Douglas-Peucker à une chaîne dont les extrémités sont conservées.
+func simplifyChain(points []Point, epsilon float64) []Point {
+	if len(points) < 3 {
+		return append([]Point(nil), points...)
+	}
+
+	a, b := points[0], points[len(points)-1]
+	index := 0
+	var maxDistance float64
+	for i := 1; i < len(points)-1; i++ {
+		if d := segmentDistance(points[i], a, b); d > maxDistance {
+			index, maxDistance = i, d
+		}
+	}
+
+	if maxDistance <= epsilon {
+		return []Point{a, b}
+	}
+	left := simplifyChain(points[:index+1], epsilon)
+	right := simplifyChain(points[index:], epsilon)
+	return append(left[:len(left)-1], right...)
+}
+
+// segmentDistance renvoie la distance du point p au segment [a, b].
+func segmentDistance(p, a, b Point) float64 {
+	dx, dy := float64(b.X-a.X), float64(b.Y-a.Y)
+	px, py := float64(p.X-a.X), float64(p.Y-a.Y)
+	lengthSquared := dx*dx + dy*dy
+	if lengthSquared == 0 {
+		return math.Hypot(px, py)
+	}
+	t := clampFloat((px*dx+py*dy)/lengthSquared, 0, 1)
+	return math.Hypot(px-t*dx, py-t*dy)
+}
@@ -0,0 +1,279 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// LumaWeights représente les coefficients appliqués aux canaux rouge, vert et bleu lors du passage
+// en niveaux de gris. Leur somme vaut normalement 1.
+type LumaWeights struct {
+	Red, Green, Blue float64
+}
+
+var (
+	// Rec601 sont les coefficients de luminance de la télévision standard (et de la plupart des outils Netpbm).
+	Rec601 = LumaWeights{0.299, 0.587, 0.114}
+	// Rec709 sont les coefficients de luminance de la télévision haute définition et du sRGB.
+	Rec709 = LumaWeights{0.2126, 0.7152, 0.0722}
+	// AverageWeights donne le même poids aux trois canaux.
+	AverageWeights = LumaWeights{1.0 / 3, 1.0 / 3, 1.0 / 3}
+)
+
+// ConvertOptions règle les conversions effectuées par Convert. La valeur zéro donne les réglages par défaut.
+type ConvertOptions struct {
+	// Weights sont les coefficients de luminance utilisés pour passer de la couleur au gris (Rec601 si nuls).
+	Weights LumaWeights
+	// Threshold est le seuil, en fraction de la valeur maximale, en dessous duquel un pixel devient noir
+	// lors du passage en bitonal (0.5 si nul).
+	Threshold float64
+	// Dither, s'il n'est pas nil, remplace le seuillage par une diffusion d'erreur lors du passage en bitonal.
+	Dither *DitherOptions
+	// MaxValue est la valeur maximale de l'image produite en PGM ou PPM (celle de la source si nulle,
+	// 255 pour une source bitonale).
+	MaxValue int
+	// Polarity est la convention de l'image produite en PBM. Les pixels sombres deviennent noirs
+	// quelle qu'elle soit : elle ne change que le sens des valeurs de At et Set.
+	Polarity Polarity
+}
+
+// weights renvoie les coefficients de luminance à utiliser.
+func (options ConvertOptions) weights() LumaWeights {
+	if options.Weights == (LumaWeights{}) {
+		return Rec601
+	}
+	return options.Weights
+}
+
+// threshold renvoie le seuil de passage en bitonal.
+func (options ConvertOptions) threshold() float64 {
+	if options.Threshold <= 0 {
+		return 0.5
+	}
+	return options.Threshold
+}
+
+// Convert renvoie l'image convertie dans le format cible, quel que soit son format d'origine
+// (les 36 combinaisons de P1 à P6). Le résultat est un *PBM, un *PGM ou un *PPM dont le nombre
+// magique est celui du format cible ; l'image d'origine n'est jamais modifiée.
+func Convert(img Image, target Format, options ConvertOptions) (Image, error) {
+	if packed, ok := img.(*PackedPBM); ok {
+		img = packed.Unpack()
+	}
+	switch target {
+	case FormatP1, FormatP4:
+		pbm, err := convertToPBM(img, options)
+		if err != nil {
+			return nil, err
+		}
+		pbm.magicNumber = target.MagicNumber()
+		pbm.polarity = options.Polarity
+		inheritConverted(&pbm.meta, img, target)
+		return pbm, nil
+	case FormatP2, FormatP5:
+		pgm, err := convertToPGM(img, options)
+		if err != nil {
+			return nil, err
+		}
+		pgm.magicNumber = target.MagicNumber()
+		inheritConverted(&pgm.meta, img, target)
+		return pgm, nil
+	case FormatP3, FormatP6:
+		ppm, err := convertToPPM(img, options)
+		if err != nil {
+			return nil, err
+		}
+		ppm.magicNumber = target.MagicNumber()
+		inheritConverted(&ppm.meta, img, target)
+		return ppm, nil
+	}
+	return nil, fmt.Errorf("format inconnu: %d", int(target))
+}
+
+// inheritConverted donne à l'image convertie les métadonnées de l'image d'origine, en notant la
+// conversion si le type de l'image a changé.
+func inheritConverted(meta *Metadata, img Image, target Format) {
+	source := metadataOf(img)
+	if source == nil || source == meta {
+		return
+	}
+	if originalFormat(img) == target {
+		*meta = source.clone()
+		return
+	}
+	*meta = source.derive("convert %s", target)
+}
+
+// convertToPBM convertit l'image en une nouvelle image PBM.
+func convertToPBM(img Image, options ConvertOptions) (*PBM, error) {
+	var gray *PGM
+	switch img := img.(type) {
+	case *PBM:
+		result := NewPBM(img.width, img.height)
+		for y := range img.data {
+			copy(result.data[y], img.data[y])
+		}
+		return result, nil
+	case *PGM:
+		gray = img
+	case *PPM:
+		gray = colorToGray(img, options.weights(), img.max)
+	default:
+		return nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+	}
+
+	if options.Dither != nil {
+		return gray.Dither(*options.Dither), nil
+	}
+
+	result := NewPBM(gray.width, gray.height)
+	limit := options.threshold() * float64(gray.max)
+	for y := 0; y < gray.height; y++ {
+		for x := 0; x < gray.width; x++ {
+			result.data[y][x] = float64(gray.data[y][x]) < limit
+		}
+	}
+	return result, nil
+}
+
+// convertToPGM convertit l'image en une nouvelle image PGM.
+func convertToPGM(img Image, options ConvertOptions) (*PGM, error) {
+	switch img := img.(type) {
+	case *PBM:
+		maxValue := options.MaxValue
+		if maxValue <= 0 {
+			maxValue = 255
+		}
+		result := NewPGM(img.width, img.height, maxValue)
+		for y := 0; y < img.height; y++ {
+			for x := 0; x < img.width; x++ {
+				if !img.data[y][x] {
+					result.data[y][x] = uint8(maxValue)
+				}
+			}
+		}
+		return result, nil
+	case *PGM:
+		result := img.Copy()
+		if options.MaxValue > 0 && options.MaxValue != img.max {
+			if err := result.Rescale(options.MaxValue, false); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case *PPM:
+		maxValue := options.MaxValue
+		if maxValue <= 0 {
+			maxValue = img.max
+		}
+		return colorToGray(img, options.weights(), maxValue), nil
+	}
+	return nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+}
+
+// convertToPPM convertit l'image en une nouvelle image PPM.
+func convertToPPM(img Image, options ConvertOptions) (*PPM, error) {
+	if ppm, ok := img.(*PPM); ok {
+		result := ppm.Copy()
+		if options.MaxValue > 0 && options.MaxValue != ppm.max {
+			if err := result.Rescale(options.MaxValue, false); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	gray, err := convertToPGM(img, options)
+	if err != nil {
+		return nil, err
+	}
+	result := NewPPM(gray.width, gray.height, gray.max)
+	for y := 0; y < gray.height; y++ {
+		for x := 0; x < gray.width; x++ {
+			v := gray.data[y][x]
+			result.data[y][x] = []uint8{v, v, v}
+		}
+	}
+	return result, nil
+}
+
+// colorToGray convertit une image PPM en PGM de valeur maximale maxValue avec les coefficients donnés.
+func colorToGray(ppm *PPM, weights LumaWeights, maxValue int) *PGM {
+	scale := 1.0
+	if ppm.max > 0 {
+		scale = float64(maxValue) / float64(ppm.max)
+	}
+
+	pgm := NewPGM(ppm.width, ppm.height, maxValue)
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			value := ppm.data[y][x]
+			gray := weights.Red*float64(value[0]) + weights.Green*float64(value[1]) + weights.Blue*float64(value[2])
+			pgm.data[y][x] = uint8(clampFloat(math.Round(gray*scale), 0, float64(maxValue)))
+		}
+	}
+	return pgm
+}
+
+// GrayMode est la façon dont ToPGM passe de la couleur au gris.
+type GrayMode int
+
+const (
+	GrayRec601  GrayMode = iota // Luminance de la télévision standard (coefficients Rec601)
+	GrayRec709                  // Luminance de la haute définition et du sRGB (coefficients Rec709)
+	GrayAverage                 // Moyenne des trois canaux
+	GrayRed                     // Canal rouge seul
+	GrayGreen                   // Canal vert seul
+	GrayBlue                    // Canal bleu seul
+)
+
+// String renvoie le nom du mode.
+func (mode GrayMode) String() string {
+	switch mode {
+	case GrayRec601:
+		return "rec601"
+	case GrayRec709:
+		return "rec709"
+	case GrayAverage:
+		return "average"
+	case GrayRed:
+		return "red"
+	case GrayGreen:
+		return "green"
+	case GrayBlue:
+		return "blue"
+	}
+	return fmt.Sprintf("GrayMode(%d)", int(mode))
+}
+
+// weights renvoie les coefficients correspondant au mode.
+func (mode GrayMode) weights() (LumaWeights, error) {
+	switch mode {
+	case GrayRec601:
+		return Rec601, nil
+	case GrayRec709:
+		return Rec709, nil
+	case GrayAverage:
+		return AverageWeights, nil
+	case GrayRed:
+		return LumaWeights{Red: 1}, nil
+	case GrayGreen:
+		return LumaWeights{Green: 1}, nil
+	case GrayBlue:
+		return LumaWeights{Blue: 1}, nil
+	}
+	return LumaWeights{}, fmt.Errorf("mode de conversion en gris inconnu: %v", mode)
+}
+
+// ToPGM convertit l'image PPM en niveaux de gris, avec la même valeur maximale, selon le mode
+// choisi : luminance (Rec601 ou Rec709), moyenne des canaux ou extraction d'un seul canal. Pour
+// d'autres coefficients, voir Convert et ConvertOptions.Weights.
+func (ppm *PPM) ToPGM(mode GrayMode) (*PGM, error) {
+	weights, err := mode.weights()
+	if err != nil {
+		return nil, err
+	}
+	pgm := colorToGray(ppm, weights, ppm.max)
+	pgm.meta = ppm.meta.derive("gray %s", mode)
+	return pgm, nil
+}
@@ -0,0 +1,132 @@
+package netpbm
+
+import "math"
+
+// Kernel représente un noyau de convolution de taille Width×Height (dimensions impaires).
+// Values est rangé ligne par ligne ; Bias est ajouté au résultat, exprimé en fraction de la valeur maximale.
+type Kernel struct {
+	Width, Height int
+	Values        []float64
+	Bias          float64
+}
+
+// NewKernel crée un noyau à partir de ses lignes.
+func NewKernel(rows [][]float64) Kernel {
+	kernel := Kernel{Height: len(rows)}
+	if len(rows) > 0 {
+		kernel.Width = len(rows[0])
+	}
+	for _, row := range rows {
+		kernel.Values = append(kernel.Values, row...)
+	}
+	return kernel
+}
+
+// Normalized renvoie une copie du noyau dont la somme des coefficients vaut 1 (si elle n'est pas nulle).
+func (kernel Kernel) Normalized() Kernel {
+	var sum float64
+	for _, v := range kernel.Values {
+		sum += v
+	}
+	result := kernel
+	result.Values = make([]float64, len(kernel.Values))
+	for i, v := range kernel.Values {
+		if sum != 0 {
+			v /= sum
+		}
+		result.Values[i] = v
+	}
+	return result
+}
+
+// convolve applique le noyau à l'image flottante. Le noyau n'est pas retourné : il est appliqué
+// comme une corrélation, ce qui garde l'orientation intuitive des noyaux non symétriques.
+func (img *floatImage) convolve(kernel Kernel) *floatImage {
+	result := newFloatImage(img.width, img.height, img.channels)
+	cx, cy := kernel.Width/2, kernel.Height/2
+	for y := 0; y < img.height; y++ {
+		for x := 0; x < img.width; x++ {
+			for c := 0; c < img.channels; c++ {
+				sum := kernel.Bias
+				for ky := 0; ky < kernel.Height; ky++ {
+					for kx := 0; kx < kernel.Width; kx++ {
+						w := kernel.Values[ky*kernel.Width+kx]
+						if w != 0 {
+							sum += w * img.at(x+kx-cx, y+ky-cy, c)
+						}
+					}
+				}
+				result.pix[result.index(x, y, c)] = sum
+			}
+		}
+	}
+	return result
+}
+
+// Convolve renvoie une copie de l'image PPM convoluée par le noyau (les bords sont prolongés).
+func (ppm *PPM) Convolve(kernel Kernel) *PPM {
+	result := ppm.toFloat(false).convolve(kernel).toPPM(ppm.max, false)
+	result.magicNumber = ppm.magicNumber
+	result.meta = ppm.meta.derive("convolve %dx%d", kernel.Width, kernel.Height)
+	return result
+}
+
+// Convolve renvoie une copie de l'image PGM convoluée par le noyau (les bords sont prolongés).
+func (pgm *PGM) Convolve(kernel Kernel) *PGM {
+	result := pgm.toFloat(false).convolve(kernel).toPGM(pgm.max, false)
+	result.magicNumber = pgm.magicNumber
+	result.meta = pgm.meta.derive("convolve %dx%d", kernel.Width, kernel.Height)
+	return result
+}
+
+// EmbossKernel renvoie le noyau d'estampage 3×3 pour une lumière venant de la direction donnée
+// (en degrés, 0 = depuis la droite, 90 = depuis le bas, l'axe Y étant orienté vers le bas).
+func EmbossKernel(direction float64) Kernel {
+	sin, cos := math.Sincos(direction * math.Pi / 180)
+	kernel := Kernel{Width: 3, Height: 3, Values: make([]float64, 9), Bias: 0.5}
+	for j := -1; j <= 1; j++ {
+		for i := -1; i <= 1; i++ {
+			// Les voisins du côté de la lumière sont positifs, ceux du côté opposé négatifs.
+			kernel.Values[(j+1)*3+i+1] = math.Round(float64(i)*cos + float64(j)*sin)
+		}
+	}
+	return kernel
+}
+
+// Emboss renvoie une version estampée (en relief, sur fond gris moyen) de l'image PPM,
+// éclairée depuis la direction donnée en degrés.
+func (ppm *PPM) Emboss(direction float64) *PPM {
+	result := ppm.Convolve(EmbossKernel(direction))
+	result.meta = ppm.meta.derive("emboss %g", direction)
+	return result
+}
+
+// MotionBlurKernel renvoie un noyau de flou de bougé : un segment de longueur distance pixels,
+// centré, orienté selon angle degrés.
+func MotionBlurKernel(angle, distance float64) Kernel {
+	if distance < 1 {
+		return Kernel{Width: 1, Height: 1, Values: []float64{1}}
+	}
+	radius := int(math.Ceil(distance / 2))
+	size := 2*radius + 1
+	kernel := Kernel{Width: size, Height: size, Values: make([]float64, size*size)}
+
+	// Échantillonner finement le segment et accumuler sa couverture dans les cellules du noyau.
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	samples := int(math.Ceil(distance * 4))
+	for i := 0; i <= samples; i++ {
+		t := (float64(i)/float64(samples) - 0.5) * distance
+		x := int(math.Round(t*cos)) + radius
+		y := int(math.Round(t*sin)) + radius
+		kernel.Values[y*size+x]++
+	}
+	return kernel.Normalized()
+}
+
+// MotionBlur renvoie une copie de l'image PPM floutée comme par un déplacement de distance pixels
+// dans la direction angle (en degrés).
+func (ppm *PPM) MotionBlur(angle, distance float64) *PPM {
+	result := ppm.Convolve(MotionBlurKernel(angle, distance))
+	result.meta = ppm.meta.derive("motion blur %g %g", angle, distance)
+	return result
+}
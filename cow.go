@@ -0,0 +1,196 @@
+package netpbm
+
+// Copie sur écriture : Copy ne duplique pas les pixels, la copie et l'original partagent leurs
+// lignes jusqu'à ce que l'un des deux les modifie. Chaque image note les lignes qu'elle partage
+// (shared) ; une ligne partagée est dupliquée par writableRow juste avant d'être écrite, et own
+// duplique toutes les lignes partagées avant une opération qui modifie l'image entière. Les
+// méthodes qui écrivent dans data doivent donc passer par l'une ou l'autre. Comme Copy marque
+// aussi les lignes de l'original, elle ne doit pas être appelée en même temps qu'une autre
+// méthode sur la même image.
+//
+// Les lignes vues par SubImage (viewed) font exception : partager une ligne avec une vue est voulu,
+// et Copy les duplique aussitôt pour la copie, sans les marquer chez l'image copiée. Les rotations,
+// qui déplacent les pixels d'une ligne à l'autre, détachent d'abord ces lignes par detachViews.
+
+// sharedRows renvoie des indicateurs marquant les height lignes comme partagées.
+func sharedRows(height int) []bool {
+	shared := make([]bool, height)
+	for i := range shared {
+		shared[i] = true
+	}
+	return shared
+}
+
+// Copy crée une copie de l'image PPM ; les lignes ne sont dupliquées qu'à la première écriture.
+func (ppm *PPM) Copy() *PPM {
+	data := make([][][]uint8, len(ppm.data))
+	copy(data, ppm.data)
+	ppm.shared = sharedRows(len(data))
+	shared := sharedRows(len(data))
+	for y := range data {
+		if isViewed(ppm.viewed, y) {
+			data[y] = clonePixels(data[y])
+			ppm.shared[y], shared[y] = false, false
+		}
+	}
+
+	return &PPM{
+		data:        data,
+		width:       ppm.width,
+		height:      ppm.height,
+		magicNumber: ppm.magicNumber,
+		max:         ppm.max,
+		shared:      shared,
+		meta:        ppm.meta.clone(),
+	}
+}
+
+// isViewed indique si la ligne y est vue par une vue (voir SubImage).
+func isViewed(viewed []bool, y int) bool {
+	return y < len(viewed) && viewed[y]
+}
+
+// clonePixels renvoie une copie de la ligne de pixels, dans un tampon contigu.
+func clonePixels(row [][]uint8) [][]uint8 {
+	clone := make([][]uint8, len(row))
+	buffer := make([]uint8, 3*len(row))
+	for x, pixel := range row {
+		clone[x] = buffer[3*x : 3*x+3 : 3*x+3]
+		copy(clone[x], pixel)
+	}
+	return clone
+}
+
+// writableRow renvoie la ligne y, dupliquée au préalable si elle est partagée avec une copie.
+func (ppm *PPM) writableRow(y int) [][]uint8 {
+	if y < len(ppm.shared) && ppm.shared[y] {
+		ppm.data[y] = clonePixels(ppm.data[y])
+		ppm.shared[y] = false
+	}
+	return ppm.data[y]
+}
+
+// own duplique toutes les lignes partagées : l'image peut ensuite être modifiée librement. Elle est
+// aussi marquée comme modifiée en entier (voir Dirty).
+func (ppm *PPM) own() {
+	ppm.changes.markAll(ppm.Size)
+	for y := range ppm.shared {
+		ppm.writableRow(y)
+	}
+	ppm.shared = nil
+}
+
+// detachViews duplique les lignes vues par une vue (pixels compris), qui ne partagent alors plus
+// rien avec l'image.
+func (ppm *PPM) detachViews() {
+	for y := range ppm.viewed {
+		if ppm.viewed[y] {
+			ppm.data[y] = clonePixels(ppm.data[y])
+		}
+	}
+	ppm.viewed = nil
+}
+
+// Copy crée une copie de l'image PGM ; les lignes ne sont dupliquées qu'à la première écriture.
+func (pgm *PGM) Copy() *PGM {
+	data := make([][]uint8, len(pgm.data))
+	copy(data, pgm.data)
+	pgm.shared = sharedRows(len(data))
+	shared := sharedRows(len(data))
+	for y := range data {
+		if isViewed(pgm.viewed, y) {
+			data[y] = append([]uint8(nil), data[y]...)
+			pgm.shared[y], shared[y] = false, false
+		}
+	}
+
+	return &PGM{
+		data:        data,
+		width:       pgm.width,
+		height:      pgm.height,
+		magicNumber: pgm.magicNumber,
+		max:         pgm.max,
+		shared:      shared,
+		meta:        pgm.meta.clone(),
+	}
+}
+
+// writableRow renvoie la ligne y, dupliquée au préalable si elle est partagée avec une copie.
+func (pgm *PGM) writableRow(y int) []uint8 {
+	if y < len(pgm.shared) && pgm.shared[y] {
+		pgm.data[y] = append([]uint8(nil), pgm.data[y]...)
+		pgm.shared[y] = false
+	}
+	return pgm.data[y]
+}
+
+// own duplique toutes les lignes partagées : l'image peut ensuite être modifiée librement.
+func (pgm *PGM) own() {
+	pgm.changes.markAll(pgm.Size)
+	for y := range pgm.shared {
+		pgm.writableRow(y)
+	}
+	pgm.shared = nil
+}
+
+// detachViews duplique les lignes vues par une vue, qui ne partagent alors plus rien avec l'image.
+func (pgm *PGM) detachViews() {
+	for y := range pgm.viewed {
+		if pgm.viewed[y] {
+			pgm.data[y] = append([]uint8(nil), pgm.data[y]...)
+		}
+	}
+	pgm.viewed = nil
+}
+
+// Copy crée une copie de l'image PBM ; les lignes ne sont dupliquées qu'à la première écriture.
+func (pbm *PBM) Copy() *PBM {
+	data := make([][]bool, len(pbm.data))
+	copy(data, pbm.data)
+	pbm.shared = sharedRows(len(data))
+	shared := sharedRows(len(data))
+	for y := range data {
+		if isViewed(pbm.viewed, y) {
+			data[y] = append([]bool(nil), data[y]...)
+			pbm.shared[y], shared[y] = false, false
+		}
+	}
+
+	return &PBM{
+		data:        data,
+		width:       pbm.width,
+		height:      pbm.height,
+		magicNumber: pbm.magicNumber,
+		polarity:    pbm.polarity,
+		shared:      shared,
+		meta:        pbm.meta.clone(),
+	}
+}
+
+// writableRow renvoie la ligne y, dupliquée au préalable si elle est partagée avec une copie.
+func (pbm *PBM) writableRow(y int) []bool {
+	if y < len(pbm.shared) && pbm.shared[y] {
+		pbm.data[y] = append([]bool(nil), pbm.data[y]...)
+		pbm.shared[y] = false
+	}
+	return pbm.data[y]
+}
+
+// own duplique toutes les lignes partagées : l'image peut ensuite être modifiée librement.
+func (pbm *PBM) own() {
+	pbm.changes.markAll(pbm.Size)
+	for y := range pbm.shared {
+		pbm.writableRow(y)
+	}
+	pbm.shared = nil
+}
+
+// detachViews duplique les lignes vues par une vue, qui ne partagent alors plus rien avec l'image.
+func (pbm *PBM) detachViews() {
+	for y := range pbm.viewed {
+		if pbm.viewed[y] {
+			pbm.data[y] = append([]bool(nil), pbm.data[y]...)
+		}
+	}
+	pbm.viewed = nil
+}
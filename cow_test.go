@@ -0,0 +1,86 @@
+package netpbm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCopyOnWritePGM(t *testing.T) {
+	original := testPGM()
+	clone := original.Copy()
+	if &clone.data[1][0] != &original.data[1][0] {
+		t.Fatal("Copy a dupliqué les lignes avant toute écriture")
+	}
+
+	// Une écriture ne duplique que sa ligne, des deux côtés.
+	clone.Set(0, 1, 77)
+	if original.At(0, 1) == 77 {
+		t.Fatal("Set sur la copie a modifié l'original")
+	}
+	if &clone.data[2][0] != &original.data[2][0] {
+		t.Error("Set a dupliqué une autre ligne que la sienne")
+	}
+	original.Set(0, 2, 88)
+	if clone.At(0, 2) == 88 {
+		t.Fatal("Set sur l'original a modifié la copie")
+	}
+
+	// Une opération sur toute l'image les sépare entièrement.
+	original.Invert()
+	want := testPGM()
+	want.Set(0, 1, 77)
+	assertSamePGM(t, clone, want)
+}
+
+func TestCopyOnWritePPM(t *testing.T) {
+	original := NewPPM(3, 2, 255)
+	original.Set(1, 0, []uint8{1, 2, 3})
+	clone := original.Copy()
+
+	clone.Set(1, 0, []uint8{9, 9, 9})
+	if got := original.At(1, 0); !bytes.Equal(got, []uint8{1, 2, 3}) {
+		t.Fatalf("Set sur la copie a modifié l'original: %v", got)
+	}
+	original.Invert()
+	if got := clone.At(0, 1); !bytes.Equal(got, []uint8{0, 0, 0}) {
+		t.Fatalf("Invert sur l'original a modifié la copie: %v", got)
+	}
+	// Les pixels d'une ligne dupliquée restent dans un tampon contigu et indépendant.
+	if got := clone.At(2, 0); !bytes.Equal(got, []uint8{0, 0, 0}) {
+		t.Fatalf("pixel voisin modifié: %v", got)
+	}
+}
+
+func TestCopyOnWritePBM(t *testing.T) {
+	original := testPBM(13, 5, 0)
+	clone := original.Copy()
+	want := original.At(4, 3)
+
+	clone.Set(4, 3, !want)
+	if original.At(4, 3) != want {
+		t.Fatal("Set sur la copie a modifié l'original")
+	}
+	clone.Flip()
+	if original.At(4, 3) != want {
+		t.Fatal("Flip sur la copie a modifié l'original")
+	}
+}
+
+func TestCopyOfViewedImage(t *testing.T) {
+	original := NewPGM(4, 4, 255)
+	view := original.SubImage(Rect{X: 1, Y: 1, Width: 2, Height: 2})
+	clone := original.Copy()
+
+	// La vue et l'image partagent leurs pixels, mais pas la copie.
+	view.Set(0, 0, 200)
+	if original.At(1, 1) != 200 {
+		t.Fatal("la vue ne partage plus ses pixels avec l'image")
+	}
+	if clone.At(1, 1) != 0 {
+		t.Fatal("la copie partage les pixels de la vue")
+	}
+	clone.Set(2, 2, 100)
+	if view.At(1, 1) != 0 || original.At(2, 2) != 0 {
+		t.Fatal("Set sur la copie a modifié la vue")
+	}
+}
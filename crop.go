@@ -0,0 +1,121 @@
+package netpbm
+
+// Crop et SubImage ramènent la zone demandée aux limites de l'image. Crop en renvoie une copie ;
+// SubImage, une vue qui partage ses pixels avec l'image, jusqu'à ce que l'une des deux soit tournée
+// ou redimensionnée. Les écritures dans l'une ne sont pas notées par Dirty dans l'autre.
+
+// Crop renvoie une copie de la zone r de l'image PPM.
+func (ppm *PPM) Crop(r Rect) *PPM {
+	r = r.Intersect(Rect{Width: ppm.width, Height: ppm.height})
+	result := ppm.crop(r)
+	result.magicNumber = ppm.magicNumber
+	result.meta = ppm.meta.derive("crop %dx%d+%d+%d", r.Width, r.Height, r.X, r.Y)
+	return result
+}
+
+// SubImage renvoie une vue sur la zone r de l'image PPM, qui partage ses pixels.
+func (ppm *PPM) SubImage(r Rect) *PPM {
+	r = r.Intersect(Rect{Width: ppm.width, Height: ppm.height})
+	data := make([][][]uint8, r.Height)
+	for y := range data {
+		// La ligne est d'abord détachée des copies éventuelles, pour que les écritures dans la
+		// vue n'atteignent que cette image.
+		data[y] = ppm.writableRow(r.Y + y)[r.X : r.X+r.Width : r.X+r.Width]
+	}
+	ppm.markViewed(r)
+	return &PPM{
+		data:        data,
+		width:       r.Width,
+		height:      r.Height,
+		magicNumber: ppm.magicNumber,
+		max:         ppm.max,
+		viewed:      sharedRows(len(data)),
+		meta:        ppm.meta.derive("subimage %dx%d+%d+%d", r.Width, r.Height, r.X, r.Y),
+	}
+}
+
+// Crop renvoie une copie de la zone r de l'image PGM.
+func (pgm *PGM) Crop(r Rect) *PGM {
+	r = r.Intersect(Rect{Width: pgm.width, Height: pgm.height})
+	result := pgm.crop(r)
+	result.magicNumber = pgm.magicNumber
+	result.meta = pgm.meta.derive("crop %dx%d+%d+%d", r.Width, r.Height, r.X, r.Y)
+	return result
+}
+
+// SubImage renvoie une vue sur la zone r de l'image PGM, qui partage ses pixels.
+func (pgm *PGM) SubImage(r Rect) *PGM {
+	r = r.Intersect(Rect{Width: pgm.width, Height: pgm.height})
+	data := make([][]uint8, r.Height)
+	for y := range data {
+		data[y] = pgm.writableRow(r.Y + y)[r.X : r.X+r.Width : r.X+r.Width]
+	}
+	pgm.markViewed(r)
+	return &PGM{
+		data:        data,
+		width:       r.Width,
+		height:      r.Height,
+		magicNumber: pgm.magicNumber,
+		max:         pgm.max,
+		viewed:      sharedRows(len(data)),
+		meta:        pgm.meta.derive("subimage %dx%d+%d+%d", r.Width, r.Height, r.X, r.Y),
+	}
+}
+
+// Crop renvoie une copie de la zone r de l'image PBM.
+func (pbm *PBM) Crop(r Rect) *PBM {
+	r = r.Intersect(Rect{Width: pbm.width, Height: pbm.height})
+	result := pbm.crop(r)
+	result.magicNumber = pbm.magicNumber
+	result.meta = pbm.meta.derive("crop %dx%d+%d+%d", r.Width, r.Height, r.X, r.Y)
+	return result
+}
+
+// SubImage renvoie une vue sur la zone r de l'image PBM, qui partage ses pixels.
+func (pbm *PBM) SubImage(r Rect) *PBM {
+	r = r.Intersect(Rect{Width: pbm.width, Height: pbm.height})
+	data := make([][]bool, r.Height)
+	for y := range data {
+		data[y] = pbm.writableRow(r.Y + y)[r.X : r.X+r.Width : r.X+r.Width]
+	}
+	pbm.markViewed(r)
+	return &PBM{
+		data:        data,
+		width:       r.Width,
+		height:      r.Height,
+		magicNumber: pbm.magicNumber,
+		polarity:    pbm.polarity,
+		viewed:      sharedRows(len(data)),
+		meta:        pbm.meta.derive("subimage %dx%d+%d+%d", r.Width, r.Height, r.X, r.Y),
+	}
+}
+
+// markViewed note que les lignes de la zone r sont vues par une vue.
+func (ppm *PPM) markViewed(r Rect) {
+	if len(ppm.viewed) < len(ppm.data) {
+		ppm.viewed = append(ppm.viewed, make([]bool, len(ppm.data)-len(ppm.viewed))...)
+	}
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		ppm.viewed[y] = true
+	}
+}
+
+// markViewed note que les lignes de la zone r sont vues par une vue.
+func (pgm *PGM) markViewed(r Rect) {
+	if len(pgm.viewed) < len(pgm.data) {
+		pgm.viewed = append(pgm.viewed, make([]bool, len(pgm.data)-len(pgm.viewed))...)
+	}
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		pgm.viewed[y] = true
+	}
+}
+
+// markViewed note que les lignes de la zone r sont vues par une vue.
+func (pbm *PBM) markViewed(r Rect) {
+	if len(pbm.viewed) < len(pbm.data) {
+		pbm.viewed = append(pbm.viewed, make([]bool, len(pbm.data)-len(pbm.viewed))...)
+	}
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		pbm.viewed[y] = true
+	}
+}
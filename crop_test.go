@@ -0,0 +1,84 @@
+package netpbm
+
+import "testing"
+
+func TestSubImageCopyOnWrite(t *testing.T) {
+	a := NewPGM(4, 4, 255)
+	v := a.SubImage(Rect{X: 1, Y: 1, Width: 2, Height: 2})
+	c := a.Copy()
+
+	v.Set(0, 0, 99)
+	if got := a.At(1, 1); got != 99 {
+		t.Errorf("image d'origine: %d au lieu de 99", got)
+	}
+	if got := c.At(1, 1); got != 0 {
+		t.Errorf("copie modifiée par la vue: %d au lieu de 0", got)
+	}
+
+	a.Set(2, 2, 7)
+	if got := v.At(1, 1); got != 7 {
+		t.Errorf("vue: %d au lieu de 7", got)
+	}
+	c.Set(1, 1, 3)
+	if got := a.At(1, 1); got != 99 {
+		t.Errorf("image d'origine modifiée par la copie: %d au lieu de 99", got)
+	}
+}
+
+func TestSubImageDetachedByRotation(t *testing.T) {
+	rotations := map[string]func(*PPM){
+		"90":  (*PPM).Rotate90CW,
+		"270": (*PPM).Rotate90CCW,
+		"180": (*PPM).Rotate180,
+		"30":  func(ppm *PPM) { ppm.Rotate(30, Pixel{}) },
+	}
+	for name, rotate := range rotations {
+		// Rotation de l'image : ni les écritures en place dans la vue, ni celles dans l'image ne
+		// doivent passer de l'une à l'autre.
+		a := NewPPM(4, 3, 255)
+		v := a.SubImage(Rect{X: 1, Y: 1, Width: 2, Height: 2})
+		rotate(a)
+		v.Invert()
+		if !allBlack(a) {
+			t.Errorf("%s: écriture de la vue visible dans l'image tournée", name)
+		}
+		a.Invert()
+		v.Invert()
+		if !allBlack(v) {
+			t.Errorf("%s: écriture de l'image tournée visible dans la vue", name)
+		}
+
+		// Rotation de la vue.
+		b := NewPPM(4, 3, 255)
+		w := b.SubImage(Rect{X: 1, Y: 1, Width: 2, Height: 2})
+		rotate(w)
+		w.Invert()
+		if !allBlack(b) {
+			t.Errorf("%s: écriture de la vue tournée visible dans l'image", name)
+		}
+	}
+}
+
+func TestSubImageFollowsRescale(t *testing.T) {
+	a := NewPPM(4, 4, 255)
+	a.Invert()
+	v := a.SubImage(Rect{X: 1, Y: 1, Width: 2, Height: 2})
+	if err := a.Rescale(15, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.At(0, 0); got[0] != 15 {
+		t.Errorf("vue: %v au lieu de [15 15 15]", got)
+	}
+}
+
+// allBlack indique si tous les pixels de l'image sont noirs.
+func allBlack(ppm *PPM) bool {
+	for _, row := range ppm.data {
+		for _, pixel := range row {
+			if pixel[0] != 0 || pixel[1] != 0 || pixel[2] != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package netpbm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DemosaicMethod choisit l'interpolation utilisée par Demosaic.
+type DemosaicMethod int
+
+const (
+	DemosaicBilinear DemosaicMethod = iota // Moyenne des voisins de la même couleur
+	DemosaicMalvar                         // Interpolation corrigée par le gradient (Malvar, He et Cutler, 2004)
+)
+
+// Noyaux 5×5 de Malvar-He-Cutler, à diviser par 8.
+var (
+	// Vert sur un site rouge ou bleu.
+	malvarGreen = [5][5]float64{
+		{0, 0, -1, 0, 0},
+		{0, 0, 2, 0, 0},
+		{-1, 2, 4, 2, -1},
+		{0, 0, 2, 0, 0},
+		{0, 0, -1, 0, 0},
+	}
+	// Rouge (ou bleu) sur un site vert dont les voisins horizontaux sont de cette couleur.
+	malvarRow = [5][5]float64{
+		{0, 0, 0.5, 0, 0},
+		{0, -1, 0, -1, 0},
+		{-1, 4, 5, 4, -1},
+		{0, -1, 0, -1, 0},
+		{0, 0, 0.5, 0, 0},
+	}
+	// Rouge (ou bleu) sur un site vert dont les voisins verticaux sont de cette couleur.
+	malvarColumn = [5][5]float64{
+		{0, 0, -1, 0, 0},
+		{0, -1, 4, -1, 0},
+		{0.5, 0, 5, 0, 0.5},
+		{0, -1, 4, -1, 0},
+		{0, 0, -1, 0, 0},
+	}
+	// Rouge sur un site bleu, ou bleu sur un site rouge.
+	malvarDiagonal = [5][5]float64{
+		{0, 0, -1.5, 0, 0},
+		{0, 2, 0, 2, 0},
+		{-1.5, 0, 6, 0, -1.5},
+		{0, 2, 0, 2, 0},
+		{0, 0, -1.5, 0, 0},
+	}
+)
+
+// bayerChannel renvoie le canal (0 rouge, 1 vert, 2 bleu) du photosite (x, y) pour la matrice donnée.
+func bayerChannel(pattern string, x, y int) int {
+	return strings.IndexByte("RGB", pattern[(y&1)*2+(x&1)])
+}
+
+// mirrorIndex ramène un indice dans [0, n) par réflexion sur les bords, ce qui conserve la parité
+// et donc la couleur des photosites dans une mosaïque de Bayer.
+func mirrorIndex(i, n int) int {
+	for i < 0 || i >= n {
+		if i < 0 {
+			i = -i
+		}
+		if i >= n {
+			i = 2*(n-1) - i
+		}
+		if n == 1 {
+			return 0
+		}
+	}
+	return i
+}
+
+// Demosaic reconstitue une image couleur à partir d'une mosaïque de Bayer brute, telle que produite
+// par « dcraw -D ». pattern décrit les quatre photosites du coin supérieur gauche, ligne par ligne :
+// "RGGB", "BGGR", "GRBG" ou "GBRG".
+func (pgm *PGM) Demosaic(pattern string, method DemosaicMethod) (*PPM, error) {
+	pattern = strings.ToUpper(pattern)
+	switch pattern {
+	case "RGGB", "BGGR", "GRBG", "GBRG":
+	default:
+		return nil, fmt.Errorf("matrice de Bayer inconnue: %s", pattern)
+	}
+
+	sample := func(x, y int) float64 {
+		return float64(pgm.data[mirrorIndex(y, pgm.height)][mirrorIndex(x, pgm.width)])
+	}
+	result := NewPPM(pgm.width, pgm.height, pgm.max)
+	result.meta = pgm.meta.derive("demosaic %s", pattern)
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			site := bayerChannel(pattern, x, y)
+			value := make([]uint8, 3)
+			for c := 0; c < 3; c++ {
+				var level float64
+				switch {
+				case c == site:
+					level = sample(x, y)
+				case method == DemosaicMalvar:
+					level = malvarAt(sample, x, y, malvarKernel(pattern, x, y, c))
+				default:
+					level = bilinearAt(pattern, sample, x, y, c)
+				}
+				value[c] = toLevel(level, pgm.max)
+			}
+			result.data[y][x] = value
+		}
+	}
+	return result, nil
+}
+
+// bilinearAt renvoie la moyenne des photosites voisins (3×3) du canal c.
+func bilinearAt(pattern string, sample func(x, y int) float64, x, y, c int) float64 {
+	sum, count := 0.0, 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			if bayerChannel(pattern, x+dx, y+dy) == c {
+				sum += sample(x+dx, y+dy)
+				count++
+			}
+		}
+	}
+	return sum / float64(count)
+}
+
+// malvarKernel choisit le noyau de Malvar qui estime le canal c au photosite (x, y).
+func malvarKernel(pattern string, x, y, c int) *[5][5]float64 {
+	site := bayerChannel(pattern, x, y)
+	switch {
+	case c == 1:
+		return &malvarGreen
+	case site != 1:
+		return &malvarDiagonal
+	case bayerChannel(pattern, x+1, y) == c:
+		return &malvarRow
+	default:
+		return &malvarColumn
+	}
+}
+
+// malvarAt applique un noyau de Malvar centré sur (x, y).
+func malvarAt(sample func(x, y int) float64, x, y int, kernel *[5][5]float64) float64 {
+	sum := 0.0
+	for ky := 0; ky < 5; ky++ {
+		for kx := 0; kx < 5; kx++ {
+			if weight := kernel[ky][kx]; weight != 0 {
+				sum += weight * sample(x+kx-2, y+ky-2)
+			}
+		}
+	}
+	return sum / 8
+}
@@ -0,0 +1,198 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// Les échantillons de PGM et PPM sont stockés sur 8 bits : leur profondeur se règle par la valeur
+// maximale, entre 1 et 255. Les images 16 bits passent par PGM16 et PPM16 (voir Promote8to16 et
+// Reduce16to8).
+
+// Normalize étire les niveaux de l'image PGM pour que le plus sombre vaille 0 et le plus clair la valeur maximale.
+func (pgm *PGM) Normalize() {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("normalize")
+	low, high := 255, 0
+	for _, row := range pgm.data {
+		for _, value := range row {
+			low, high = min(low, int(value)), max(high, int(value))
+		}
+	}
+	if high <= low {
+		return
+	}
+
+	for _, row := range pgm.data {
+		for x, value := range row {
+			row[x] = uint8((int(value) - low) * pgm.max / (high - low))
+		}
+	}
+}
+
+// Normalize étire les niveaux de l'image PPM pour que la composante la plus sombre vaille 0 et la
+// plus claire la valeur maximale. Le même étirement est appliqué aux trois canaux pour préserver les teintes.
+func (ppm *PPM) Normalize() {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("normalize")
+	low, high := 255, 0
+	for _, row := range ppm.data {
+		for _, pixel := range row {
+			for c := 0; c < 3; c++ {
+				low, high = min(low, int(pixel[c])), max(high, int(pixel[c]))
+			}
+		}
+	}
+	if high <= low {
+		return
+	}
+
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			value := make([]uint8, 3)
+			for c := 0; c < 3; c++ {
+				value[c] = uint8((int(pixel[c]) - low) * ppm.max / (high - low))
+			}
+			ppm.data[y][x] = value
+		}
+	}
+}
+
+// Rescale change la valeur maximale de l'image PGM en convertissant tous les niveaux.
+// Lors d'une réduction, dither active une diffusion d'erreur (Floyd-Steinberg) qui évite l'apparition d'aplats.
+func (pgm *PGM) Rescale(newMax int, dither bool) error {
+	if newMax < 1 || newMax > 255 {
+		return fmt.Errorf("valeur maximale invalide: %d", newMax)
+	}
+	if pgm.max <= 0 {
+		return fmt.Errorf("valeur maximale actuelle invalide: %d", pgm.max)
+	}
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("rescale %d", newMax)
+
+	scale := float64(newMax) / float64(pgm.max)
+	levels := make([][]float64, pgm.height)
+	for y := range levels {
+		levels[y] = make([]float64, pgm.width)
+		for x := range levels[y] {
+			levels[y][x] = float64(pgm.data[y][x]) * scale
+		}
+	}
+
+	quantize := func(x, y int, values []float64) []float64 {
+		v := clampFloat(math.Round(values[0]), 0, float64(newMax))
+		pgm.data[y][x] = uint8(v)
+		return []float64{v}
+	}
+	if dither && newMax < pgm.max {
+		diffuse([][][]float64{levels}, pgm.width, pgm.height, DitherOptions{}, quantize)
+	} else {
+		for y := range levels {
+			for x := range levels[y] {
+				quantize(x, y, levels[y][x:x+1])
+			}
+		}
+	}
+
+	pgm.max = newMax
+	return nil
+}
+
+// Rescale change la valeur maximale de l'image PPM en convertissant toutes les composantes.
+// Lors d'une réduction, dither active une diffusion d'erreur (Floyd-Steinberg).
+func (ppm *PPM) Rescale(newMax int, dither bool) error {
+	if newMax < 1 || newMax > 255 {
+		return fmt.Errorf("valeur maximale invalide: %d", newMax)
+	}
+	if ppm.max <= 0 {
+		return fmt.Errorf("valeur maximale actuelle invalide: %d", ppm.max)
+	}
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("rescale %d", newMax)
+
+	scale := float64(newMax) / float64(ppm.max)
+	channels := make([][][]float64, 3)
+	for c := range channels {
+		channels[c] = make([][]float64, ppm.height)
+		for y := range channels[c] {
+			channels[c][y] = make([]float64, ppm.width)
+			for x := range channels[c][y] {
+				channels[c][y][x] = float64(ppm.data[y][x][c]) * scale
+			}
+		}
+	}
+
+	// Les pixels sont écrits en place : une vue (voir SubImage) voit le résultat.
+	quantize := func(x, y int, values []float64) []float64 {
+		chosen := make([]float64, 3)
+		for c := 0; c < 3; c++ {
+			chosen[c] = clampFloat(math.Round(values[c]), 0, float64(newMax))
+			ppm.data[y][x][c] = uint8(chosen[c])
+		}
+		return chosen
+	}
+	if dither && newMax < ppm.max {
+		diffuse(channels, ppm.width, ppm.height, DitherOptions{}, quantize)
+	} else {
+		for y := 0; y < ppm.height; y++ {
+			for x := 0; x < ppm.width; x++ {
+				quantize(x, y, []float64{channels[0][y][x], channels[1][y][x], channels[2][y][x]})
+			}
+		}
+	}
+
+	ppm.max = newMax
+	return nil
+}
+
+// gammaTable renvoie la table de correction gamma pour des niveaux compris entre 0 et max.
+// Un gamma supérieur à 1 éclaircit les tons moyens, un gamma inférieur à 1 les assombrit.
+func gammaTable(gamma float64, max int) []uint8 {
+	table := make([]uint8, 256)
+	for i := range table {
+		if i > max {
+			table[i] = uint8(max)
+			continue
+		}
+		table[i] = uint8(math.Round(math.Pow(float64(i)/float64(max), 1/gamma) * float64(max)))
+	}
+	return table
+}
+
+// Gamma applique une correction gamma à l'image PGM.
+func (pgm *PGM) Gamma(gamma float64) error {
+	if gamma <= 0 {
+		return fmt.Errorf("gamma invalide: %g", gamma)
+	}
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("gamma %g", gamma)
+	table := gammaTable(gamma, pgm.max)
+	for _, row := range pgm.data {
+		for x, value := range row {
+			row[x] = table[value]
+		}
+	}
+	return nil
+}
+
+// Gamma applique une correction gamma aux trois canaux de l'image PPM.
+func (ppm *PPM) Gamma(gamma float64) error {
+	if gamma <= 0 {
+		return fmt.Errorf("gamma invalide: %g", gamma)
+	}
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("gamma %g", gamma)
+	table := gammaTable(gamma, ppm.max)
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			ppm.data[y][x] = []uint8{table[pixel[0]], table[pixel[1]], table[pixel[2]]}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,433 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// PGM16 est une image PGM dont les niveaux sont stockés sur 16 bits, pour une valeur maximale
+// jusqu'à 65535. Elle sert d'étape entre les fichiers 16 bits et PGM (voir Promote8to16 et
+// Reduce16to8) : les traitements de PGM ne s'y appliquent pas.
+type PGM16 struct {
+	data          [][]uint16
+	width, height int
+	magicNumber   string
+	max           int
+	meta          Metadata
+}
+
+// PPM16 est l'équivalent de PGM16 pour les images PPM : trois composantes de 16 bits par pixel.
+type PPM16 struct {
+	data          [][][3]uint16
+	width, height int
+	magicNumber   string
+	max           int
+	meta          Metadata
+}
+
+// NewPGM16 crée une image PGM16 noire, au format binaire P5.
+func NewPGM16(width, height, max int) *PGM16 {
+	data := make([][]uint16, height)
+	for i := range data {
+		data[i] = make([]uint16, width)
+	}
+	return &PGM16{data: data, width: width, height: height, magicNumber: "P5", max: max}
+}
+
+// NewPPM16 crée une image PPM16 noire, au format binaire P6.
+func NewPPM16(width, height, max int) *PPM16 {
+	data := make([][][3]uint16, height)
+	for i := range data {
+		data[i] = make([][3]uint16, width)
+	}
+	return &PPM16{data: data, width: width, height: height, magicNumber: "P6", max: max}
+}
+
+// Size renvoie la largeur et la hauteur de l'image.
+func (pgm *PGM16) Size() (int, int) {
+	return pgm.width, pgm.height
+}
+
+// MaxValue renvoie la valeur maximale de l'image.
+func (pgm *PGM16) MaxValue() int {
+	return pgm.max
+}
+
+// At renvoie la valeur du pixel en (x, y).
+func (pgm *PGM16) At(x, y int) uint16 {
+	return pgm.data[y][x]
+}
+
+// Set définit la valeur du pixel en (x, y).
+func (pgm *PGM16) Set(x, y int, value uint16) {
+	pgm.data[y][x] = value
+}
+
+// Metadata renvoie les métadonnées de l'image PGM16, modifiables.
+func (pgm *PGM16) Metadata() *Metadata {
+	return &pgm.meta
+}
+
+// Size renvoie la largeur et la hauteur de l'image.
+func (ppm *PPM16) Size() (int, int) {
+	return ppm.width, ppm.height
+}
+
+// MaxValue renvoie la valeur maximale de l'image.
+func (ppm *PPM16) MaxValue() int {
+	return ppm.max
+}
+
+// At renvoie les composantes du pixel en (x, y).
+func (ppm *PPM16) At(x, y int) [3]uint16 {
+	return ppm.data[y][x]
+}
+
+// Set définit les composantes du pixel en (x, y).
+func (ppm *PPM16) Set(x, y int, pixel [3]uint16) {
+	ppm.data[y][x] = pixel
+}
+
+// Metadata renvoie les métadonnées de l'image PPM16, modifiables.
+func (ppm *PPM16) Metadata() *Metadata {
+	return &ppm.meta
+}
+
+// ReadPGM16 lit une image PGM (P2 ou P5) de valeur maximale quelconque à partir d'un fichier.
+func ReadPGM16(filename string) (*PGM16, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner, err := scanFile(file, DefaultDecodeOptions)
+	if err != nil {
+		return nil, err
+	}
+	return decodePGM16(scanner)
+}
+
+// DecodePGM16 lit une image PGM à partir de r, comme ReadPGM16 à partir d'un fichier.
+func DecodePGM16(r io.Reader) (*PGM16, error) {
+	return decodePGM16(newSampleScanner(r, DefaultDecodeOptions.bufferSize()))
+}
+
+// decodePGM16 lit une image PGM16 à travers scanner.
+func decodePGM16(scanner *sampleScanner) (*PGM16, error) {
+	magicNumber, err := scanner.token()
+	if err != nil {
+		return nil, fmt.Errorf("en-tête illisible: %v", err)
+	}
+	if magicNumber != "P2" && magicNumber != "P5" {
+		return nil, fmt.Errorf("format PGM non pris en charge: %s", magicNumber)
+	}
+	width, height, max, err := scanner.dimensions(true)
+	if err != nil {
+		return nil, err
+	}
+
+	pgm := NewPGM16(width, height, max)
+	pgm.magicNumber = magicNumber
+	if magicNumber == "P5" {
+		if err := scanner.endHeader(); err != nil {
+			return nil, err
+		}
+	}
+	for i, row := range pgm.data {
+		if err := readSamples16(scanner, row, max, magicNumber == "P5"); err != nil {
+			return nil, fmt.Errorf("ligne %d de l'image: %v", i+1, err)
+		}
+	}
+	for _, comment := range scanner.comments {
+		pgm.meta.parseComment(comment)
+	}
+	return pgm, nil
+}
+
+// ReadPPM16 lit une image PPM (P3 ou P6) de valeur maximale quelconque à partir d'un fichier.
+func ReadPPM16(filename string) (*PPM16, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner, err := scanFile(file, DefaultDecodeOptions)
+	if err != nil {
+		return nil, err
+	}
+	return decodePPM16(scanner)
+}
+
+// DecodePPM16 lit une image PPM à partir de r, comme ReadPPM16 à partir d'un fichier.
+func DecodePPM16(r io.Reader) (*PPM16, error) {
+	return decodePPM16(newSampleScanner(r, DefaultDecodeOptions.bufferSize()))
+}
+
+// decodePPM16 lit une image PPM16 à travers scanner.
+func decodePPM16(scanner *sampleScanner) (*PPM16, error) {
+	magicNumber, err := scanner.token()
+	if err != nil {
+		return nil, fmt.Errorf("en-tête illisible: %v", err)
+	}
+	if magicNumber != "P3" && magicNumber != "P6" {
+		return nil, fmt.Errorf("format PPM non pris en charge: %s", magicNumber)
+	}
+	width, height, max, err := scanner.dimensions(true)
+	if err != nil {
+		return nil, err
+	}
+
+	ppm := NewPPM16(width, height, max)
+	ppm.magicNumber = magicNumber
+	if magicNumber == "P6" {
+		if err := scanner.endHeader(); err != nil {
+			return nil, err
+		}
+	}
+	samples := make([]uint16, 3*width)
+	for i, row := range ppm.data {
+		if err := readSamples16(scanner, samples, max, magicNumber == "P6"); err != nil {
+			return nil, fmt.Errorf("ligne %d de l'image: %v", i+1, err)
+		}
+		for x := range row {
+			copy(row[x][:], samples[3*x:3*x+3])
+		}
+	}
+	for _, comment := range scanner.comments {
+		ppm.meta.parseComment(comment)
+	}
+	return ppm, nil
+}
+
+// readSamples16 remplit dst avec les échantillons suivants : en décimal, ou en binaire (après
+// endHeader) sur un octet si max < 256 et sur deux octets gros-boutistes sinon.
+func readSamples16(scanner *sampleScanner, dst []uint16, max int, raw bool) error {
+	if !raw {
+		for i := range dst {
+			value, err := scanner.next()
+			if err != nil {
+				return err
+			}
+			if value > max {
+				return fmt.Errorf("valeur %d supérieure au maximum %d", value, max)
+			}
+			dst[i] = uint16(value)
+		}
+		return nil
+	}
+
+	width := sampleWidth(max)
+	buf := make([]byte, width*len(dst))
+	if err := scanner.read(buf); err != nil {
+		return err
+	}
+	for i := range dst {
+		if width == 2 {
+			dst[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		} else {
+			dst[i] = uint16(buf[i])
+		}
+		if int(dst[i]) > max {
+			return fmt.Errorf("valeur %d supérieure au maximum %d", dst[i], max)
+		}
+	}
+	return nil
+}
+
+// sampleWidth renvoie le nombre d'octets d'un échantillon binaire pour la valeur maximale max.
+func sampleWidth(max int) int {
+	if max > 255 {
+		return 2
+	}
+	return 1
+}
+
+// Save enregistre l'image PGM16 dans un fichier et renvoie une erreur en cas de problème.
+func (pgm *PGM16) Save(filename string) error {
+	return saveTo(filename, pgm.Encode)
+}
+
+// Encode écrit l'image PGM16 dans w, en P2 ou en P5 selon son nombre magique. En P5, chaque
+// échantillon occupe deux octets gros-boutistes si la valeur maximale dépasse 255.
+func (pgm *PGM16) Encode(w io.Writer) error {
+	return encodeSamples16(w, pgm.magicNumber, pgm.magicNumber == "P5", pgm.width, pgm.height, pgm.max, func(y int, dst []uint16) {
+		copy(dst, pgm.data[y])
+	}, 1)
+}
+
+// Save enregistre l'image PPM16 dans un fichier et renvoie une erreur en cas de problème.
+func (ppm *PPM16) Save(filename string) error {
+	return saveTo(filename, ppm.Encode)
+}
+
+// Encode écrit l'image PPM16 dans w, en P3 ou en P6 selon son nombre magique, comme PGM16.Encode.
+func (ppm *PPM16) Encode(w io.Writer) error {
+	return encodeSamples16(w, ppm.magicNumber, ppm.magicNumber == "P6", ppm.width, ppm.height, ppm.max, func(y int, dst []uint16) {
+		for x, pixel := range ppm.data[y] {
+			copy(dst[3*x:], pixel[:])
+		}
+	}, 3)
+}
+
+// saveTo crée le fichier et y écrit l'image avec encode.
+func saveTo(filename string, encode func(io.Writer) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// encodeSamples16 écrit l'en-tête puis les lignes d'échantillons fournies par row, channels
+// échantillons par pixel.
+func encodeSamples16(w io.Writer, magicNumber string, raw bool, width, height, max int, row func(y int, dst []uint16), channels int) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "%s\n%d %d\n%d\n", magicNumber, width, height, max)
+
+	samples := make([]uint16, channels*width)
+	line := make([]byte, 0, 6*len(samples)+1)
+	for y := 0; y < height; y++ {
+		row(y, samples)
+		line = line[:0]
+		for _, value := range samples {
+			switch {
+			case !raw:
+				line = strconv.AppendUint(line, uint64(value), 10)
+				line = append(line, ' ')
+			case sampleWidth(max) == 2:
+				line = append(line, byte(value>>8), byte(value))
+			default:
+				line = append(line, byte(value))
+			}
+		}
+		if !raw {
+			line = append(line, '\n')
+		}
+		if _, err := writer.Write(line); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// Promote8to16 renvoie une copie de l'image PGM en 16 bits, de valeur maximale 65535 : chaque
+// niveau v devient v*65535/max arrondi, si bien que Reduce16to8 retrouve l'image d'origine.
+func (pgm *PGM) Promote8to16() *PGM16 {
+	result := NewPGM16(pgm.width, pgm.height, 65535)
+	result.meta = pgm.meta.derive("promote 16")
+	for y, row := range pgm.data {
+		for x, value := range row {
+			result.data[y][x] = promoteSample(value, pgm.max)
+		}
+	}
+	return result
+}
+
+// Promote8to16 renvoie une copie de l'image PPM en 16 bits, de valeur maximale 65535, comme
+// PGM.Promote8to16.
+func (ppm *PPM) Promote8to16() *PPM16 {
+	result := NewPPM16(ppm.width, ppm.height, 65535)
+	result.meta = ppm.meta.derive("promote 16")
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			for c := 0; c < 3; c++ {
+				result.data[y][x][c] = promoteSample(pixel[c], ppm.max)
+			}
+		}
+	}
+	return result
+}
+
+// promoteSample ramène value, sur l'échelle 0..max, à l'échelle 0..65535.
+func promoteSample(value uint8, max int) uint16 {
+	if max <= 0 {
+		return 0
+	}
+	return uint16(min((int(value)*65535+max/2)/max, 65535))
+}
+
+// Reduce16to8 renvoie une copie de l'image PGM16 sur 8 bits, de valeur maximale 255. dither active
+// une diffusion d'erreur (Floyd-Steinberg), comme PGM.Rescale, qui évite les aplats dans les dégradés.
+func (pgm *PGM16) Reduce16to8(dither bool) *PGM {
+	result := NewPGM(pgm.width, pgm.height, 255)
+	result.meta = pgm.meta.derive("reduce 8")
+	if pgm.max <= 0 {
+		return result
+	}
+
+	scale := 255 / float64(pgm.max)
+	levels := make([][]float64, pgm.height)
+	for y := range levels {
+		levels[y] = make([]float64, pgm.width)
+		for x := range levels[y] {
+			levels[y][x] = float64(pgm.data[y][x]) * scale
+		}
+	}
+
+	quantize := func(x, y int, values []float64) []float64 {
+		v := clampFloat(math.Round(values[0]), 0, 255)
+		result.data[y][x] = uint8(v)
+		return []float64{v}
+	}
+	if dither {
+		diffuse([][][]float64{levels}, pgm.width, pgm.height, DitherOptions{}, quantize)
+	} else {
+		for y := range levels {
+			for x := range levels[y] {
+				quantize(x, y, levels[y][x:x+1])
+			}
+		}
+	}
+	return result
+}
+
+// Reduce16to8 renvoie une copie de l'image PPM16 sur 8 bits, de valeur maximale 255, comme
+// PGM16.Reduce16to8.
+func (ppm *PPM16) Reduce16to8(dither bool) *PPM {
+	result := NewPPM(ppm.width, ppm.height, 255)
+	result.meta = ppm.meta.derive("reduce 8")
+	if ppm.max <= 0 {
+		return result
+	}
+
+	scale := 255 / float64(ppm.max)
+	channels := make([][][]float64, 3)
+	for c := range channels {
+		channels[c] = make([][]float64, ppm.height)
+		for y := range channels[c] {
+			channels[c][y] = make([]float64, ppm.width)
+			for x := range channels[c][y] {
+				channels[c][y][x] = float64(ppm.data[y][x][c]) * scale
+			}
+		}
+	}
+
+	quantize := func(x, y int, values []float64) []float64 {
+		chosen := make([]float64, 3)
+		for c := 0; c < 3; c++ {
+			chosen[c] = clampFloat(math.Round(values[c]), 0, 255)
+			result.data[y][x][c] = uint8(chosen[c])
+		}
+		return chosen
+	}
+	if dither {
+		diffuse(channels, ppm.width, ppm.height, DitherOptions{}, quantize)
+	} else {
+		for y := 0; y < ppm.height; y++ {
+			for x := 0; x < ppm.width; x++ {
+				quantize(x, y, []float64{channels[0][y][x], channels[1][y][x], channels[2][y][x]})
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package netpbm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPromoteReduceRoundTrip(t *testing.T) {
+	pgm := testPGM()
+	wide := pgm.Promote8to16()
+	if wide.MaxValue() != 65535 {
+		t.Fatalf("valeur maximale %d, 65535 attendu", wide.MaxValue())
+	}
+	if got, want := wide.At(0, 0), promoteSample(pgm.At(0, 0), pgm.MaxValue()); got != want {
+		t.Errorf("pixel (0, 0): %d, %d attendu", got, want)
+	}
+	// Réduite, l'image est celle d'origine ramenée à la valeur maximale 255.
+	want := testPGM()
+	if err := want.Rescale(255, false); err != nil {
+		t.Fatal(err)
+	}
+	assertSamePGM(t, wide.Reduce16to8(false), want)
+
+	// De valeur maximale 255, chaque niveau v devient exactement v*257 : l'aller-retour est sans
+	// perte, même avec tramage.
+	assertSamePGM(t, want.Promote8to16().Reduce16to8(true), want)
+
+	ppm := NewPPM(2, 1, 255)
+	ppm.Set(0, 0, []uint8{0, 128, 255})
+	ppm.Set(1, 0, []uint8{1, 2, 254})
+	wideColor := ppm.Promote8to16()
+	if got := wideColor.At(0, 0); got != [3]uint16{0, 32896, 65535} {
+		t.Errorf("pixel (0, 0): %v", got)
+	}
+	back := wideColor.Reduce16to8(true)
+	for x := 0; x < 2; x++ {
+		if got, want := back.At(x, 0), ppm.At(x, 0); !bytes.Equal(got, want) {
+			t.Errorf("pixel (%d, 0): %v, %v attendu", x, got, want)
+		}
+	}
+	if provenance := back.Metadata().Provenance; len(provenance) < 2 || provenance[len(provenance)-1] != "reduce 8" {
+		t.Errorf("provenance %q", provenance)
+	}
+}
+
+func TestReduce16to8Dither(t *testing.T) {
+	// Un aplat entre deux niveaux de 8 bits : sans tramage il est arrondi d'un bloc, avec
+	// tramage la moyenne est conservée.
+	wide := NewPGM16(16, 16, 65535)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			wide.Set(x, y, 100*257+128)
+		}
+	}
+	sum := func(pgm *PGM) (total int, levels map[uint8]bool) {
+		levels = map[uint8]bool{}
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				total += int(pgm.At(x, y))
+				levels[pgm.At(x, y)] = true
+			}
+		}
+		return total, levels
+	}
+	if _, levels := sum(wide.Reduce16to8(false)); len(levels) != 1 {
+		t.Errorf("sans tramage: %d niveaux, 1 attendu", len(levels))
+	}
+	total, levels := sum(wide.Reduce16to8(true))
+	if len(levels) != 2 {
+		t.Errorf("avec tramage: %d niveaux, 2 attendus", len(levels))
+	}
+	if mean := float64(total) / 256; mean < 100.3 || mean > 100.7 {
+		t.Errorf("avec tramage: moyenne %.2f, 100.5 attendu", mean)
+	}
+}
+
+func TestPGM16RoundTrip(t *testing.T) {
+	for _, max := range []int{200, 1023, 65535} {
+		for _, magicNumber := range []string{"P2", "P5"} {
+			pgm := NewPGM16(3, 2, max)
+			pgm.magicNumber = magicNumber
+			pgm.Set(0, 0, uint16(max))
+			pgm.Set(2, 1, uint16(max/3))
+			var buf bytes.Buffer
+			if err := pgm.Encode(&buf); err != nil {
+				t.Fatal(err)
+			}
+			got, err := DecodePGM16(&buf)
+			if err != nil {
+				t.Fatalf("%s max %d: %v", magicNumber, max, err)
+			}
+			for y := 0; y < 2; y++ {
+				for x := 0; x < 3; x++ {
+					if got.At(x, y) != pgm.At(x, y) {
+						t.Errorf("%s max %d, pixel (%d, %d): %d, %d attendu", magicNumber, max, x, y, got.At(x, y), pgm.At(x, y))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPPM16RoundTrip(t *testing.T) {
+	for _, magicNumber := range []string{"P3", "P6"} {
+		ppm := NewPPM16(2, 2, 4095)
+		ppm.magicNumber = magicNumber
+		ppm.Set(0, 0, [3]uint16{4095, 0, 1})
+		ppm.Set(1, 1, [3]uint16{256, 2048, 4094})
+		var buf bytes.Buffer
+		if err := ppm.Encode(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if magicNumber == "P6" && buf.Len() != len("P6\n2 2\n4095\n")+2*2*3*2 {
+			t.Errorf("P6: %d octets, deux par échantillon attendus", buf.Len())
+		}
+		got, err := DecodePPM16(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", magicNumber, err)
+		}
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 2; x++ {
+				if got.At(x, y) != ppm.At(x, y) {
+					t.Errorf("%s, pixel (%d, %d): %v, %v attendu", magicNumber, x, y, got.At(x, y), ppm.At(x, y))
+				}
+			}
+		}
+	}
+}
+
+func TestDecode16Errors(t *testing.T) {
+	for _, input := range []string{
+		"P2\n2 1\n1000\n0 1001\n",     // Échantillon trop grand
+		"P5\n1 1\n1000\n\x03\xe9",     // Échantillon binaire trop grand
+		"P5\n2 1\n1000\n\x00\x01\x00", // Données tronquées
+		"P3\n1 1\n65535\n0 0",         // Pixel incomplet
+		"P5\n1 1\n65536\n\x00\x00",    // Valeur maximale hors limites
+	} {
+		var err error
+		if strings.HasPrefix(input, "P3") {
+			_, err = DecodePPM16(strings.NewReader(input))
+		} else {
+			_, err = DecodePGM16(strings.NewReader(input))
+		}
+		if err == nil {
+			t.Errorf("%q accepté", input)
+		}
+	}
+
+	// Les images 8 bits refusent une valeur maximale sur 16 bits plutôt que de la tronquer.
+	if _, err := DecodePGM(strings.NewReader("P5\n1 1\n1000\n\x00\x00")); err == nil {
+		t.Error("DecodePGM accepte une image 16 bits")
+	}
+}
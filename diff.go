@@ -0,0 +1,102 @@
+package netpbm
+
+import "fmt"
+
+// DiffOptions règle la comparaison effectuée par DiffOverlay.
+type DiffOptions struct {
+	Tolerance int   // Écart maximal toléré sur chaque composante avant de considérer un pixel comme modifié
+	Boxes     bool  // Encadrer chaque zone modifiée
+	BoxColor  Pixel // Couleur des cadres
+}
+
+// DiffOverlay renvoie une copie de l'image a dans laquelle les pixels différents de b sont
+// peints avec la couleur highlight, ainsi que les rectangles englobant chaque zone modifiée
+// (pixels différents connexes en 8-connexité).
+func DiffOverlay(a, b *PPM, highlight Pixel, options DiffOptions) (*PPM, []Rect, error) {
+	if a.width != b.width || a.height != b.height {
+		return nil, nil, fmt.Errorf("les images n'ont pas la même taille: %dx%d et %dx%d", a.width, a.height, b.width, b.height)
+	}
+
+	changed := make([][]bool, a.height)
+	for y := range changed {
+		changed[y] = make([]bool, a.width)
+		for x := range changed[y] {
+			for k := 0; k < 3; k++ {
+				if abs(int(a.data[y][x][k])-int(b.data[y][x][k])) > options.Tolerance {
+					changed[y][x] = true
+					break
+				}
+			}
+		}
+	}
+
+	overlay := a.Copy()
+	for y := range changed {
+		for x := range changed[y] {
+			if changed[y][x] {
+				overlay.setPixel(x, y, highlight)
+			}
+		}
+	}
+
+	regions := changedRegions(changed)
+	if options.Boxes {
+		for _, r := range regions {
+			overlay.strokeRect(r, options.BoxColor)
+		}
+	}
+
+	return overlay, regions, nil
+}
+
+// changedRegions renvoie les rectangles englobants des composantes 8-connexes du masque.
+func changedRegions(mask [][]bool) []Rect {
+	visited := make([][]bool, len(mask))
+	for y := range visited {
+		visited[y] = make([]bool, len(mask[y]))
+	}
+
+	var regions []Rect
+	for y := range mask {
+		for x := range mask[y] {
+			if !mask[y][x] || visited[y][x] {
+				continue
+			}
+
+			minX, minY, maxX, maxY := x, y, x, y
+			stack := []Point{{X: x, Y: y}}
+			visited[y][x] = true
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				minX, maxX = min(minX, p.X), max(maxX, p.X)
+				minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := p.X+dx, p.Y+dy
+						if ny >= 0 && ny < len(mask) && nx >= 0 && nx < len(mask[ny]) && mask[ny][nx] && !visited[ny][nx] {
+							visited[ny][nx] = true
+							stack = append(stack, Point{X: nx, Y: ny})
+						}
+					}
+				}
+			}
+
+			regions = append(regions, Rect{X: minX, Y: minY, Width: maxX - minX + 1, Height: maxY - minY + 1})
+		}
+	}
+
+	return regions
+}
+
+// strokeRect dessine le contour d'un rectangle, en ignorant les pixels hors de l'image.
+func (ppm *PPM) strokeRect(r Rect, color Pixel) {
+	right, bottom := r.X+r.Width-1, r.Y+r.Height-1
+	ppm.drawHorizontalLine(r.Y, r.X, right, color)
+	ppm.drawHorizontalLine(bottom, r.X, right, color)
+	for y := r.Y; y <= bottom; y++ {
+		ppm.setPixel(r.X, y, color)
+		ppm.setPixel(right, y, color)
+	}
+}
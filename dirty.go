@@ -0,0 +1,197 @@
+package netpbm
+
+// Suivi des zones modifiées : chaque image note la plus petite zone qui contient tous les pixels
+// modifiés depuis le dernier appel à ClearDirty, pour qu'un aperçu ou un éditeur interactif ne
+// réencode ou ne réaffiche que ce qui a changé. Set et les méthodes de dessin notent les pixels
+// qu'elles touchent ; les opérations qui modifient l'image entière (voir own) la marquent en entier.
+//
+// Les fonctions enregistrées par OnChange sont appelées à chaque modification. Une méthode qui
+// modifie de nombreux pixels regroupe ses notifications (voir batch) : les fonctions ne sont alors
+// appelées qu'une fois, à la fin de l'opération, avec la zone qu'elle a modifiée.
+
+// Empty indique si la zone ne contient aucun pixel.
+func (r Rect) Empty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+// Union renvoie la plus petite zone contenant r et other.
+func (r Rect) Union(other Rect) Rect {
+	if r.Empty() {
+		return other
+	}
+	if other.Empty() {
+		return r
+	}
+	x0, y0 := min(r.X, other.X), min(r.Y, other.Y)
+	x1, y1 := max(r.X+r.Width, other.X+other.Width), max(r.Y+r.Height, other.Y+other.Height)
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// Intersect renvoie la partie commune de r et other (vide s'il n'y en a pas).
+func (r Rect) Intersect(other Rect) Rect {
+	x0, y0 := max(r.X, other.X), max(r.Y, other.Y)
+	x1, y1 := min(r.X+r.Width, other.X+other.Width), min(r.Y+r.Height, other.Y+other.Height)
+	if x1 <= x0 || y1 <= y0 {
+		return Rect{}
+	}
+	return Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// changeTracker note les zones modifiées d'une image et prévient les abonnés.
+type changeTracker struct {
+	dirty Rect
+	all   bool // L'image entière a été modifiée (ses dimensions ont pu changer)
+
+	hooks      []changeHook
+	nextHook   int
+	batches    int  // Nombre de regroupements en cours
+	pending    Rect // Zone modifiée pendant le regroupement, pas encore notifiée
+	pendingAll bool
+}
+
+// changeHook est une fonction enregistrée par OnChange.
+type changeHook struct {
+	id int
+	fn func(Rect)
+}
+
+// mark ajoute la zone r aux zones modifiées.
+func (tracker *changeTracker) mark(r Rect) {
+	tracker.dirty = tracker.dirty.Union(r)
+	if len(tracker.hooks) == 0 {
+		return
+	}
+	if tracker.batches > 0 {
+		tracker.pending = tracker.pending.Union(r)
+		return
+	}
+	tracker.notify(r)
+}
+
+// markAll marque l'image entière comme modifiée. Les abonnés sont prévenus à la fin du regroupement
+// en cours, une fois l'image modifiée et ses nouvelles dimensions connues, ou aussitôt, avec la zone
+// de l'image de taille size, si aucun regroupement n'est ouvert.
+func (tracker *changeTracker) markAll(size func() (int, int)) {
+	tracker.all = true
+	if tracker.batches > 0 {
+		tracker.pendingAll = true
+		return
+	}
+	if len(tracker.hooks) == 0 {
+		return
+	}
+	if width, height := size(); width > 0 && height > 0 {
+		tracker.notify(Rect{Width: width, Height: height})
+	}
+}
+
+// batch commence un regroupement des notifications et renvoie la fonction qui le termine, à différer
+// en tête des méthodes qui modifient de nombreux pixels :
+//
+//	defer ppm.changes.batch(ppm.Size)()
+//
+// size donne la taille de l'image à la fin de l'opération.
+func (tracker *changeTracker) batch(size func() (int, int)) func() {
+	tracker.batches++
+	return func() {
+		tracker.batches--
+		if tracker.batches > 0 {
+			return
+		}
+		width, height := size()
+		bounds := Rect{Width: width, Height: height}
+		r := tracker.pending.Intersect(bounds)
+		if tracker.pendingAll {
+			r = bounds
+		}
+		tracker.pending, tracker.pendingAll = Rect{}, false
+		if !r.Empty() {
+			tracker.notify(r)
+		}
+	}
+}
+
+// notify appelle les abonnés avec la zone modifiée r.
+func (tracker *changeTracker) notify(r Rect) {
+	for _, hook := range tracker.hooks {
+		hook.fn(r)
+	}
+}
+
+// subscribe enregistre fn et renvoie la fonction qui l'annule.
+func (tracker *changeTracker) subscribe(fn func(Rect)) func() {
+	tracker.nextHook++
+	id := tracker.nextHook
+	tracker.hooks = append(tracker.hooks, changeHook{id: id, fn: fn})
+	return func() {
+		for i, hook := range tracker.hooks {
+			if hook.id == id {
+				tracker.hooks = append(tracker.hooks[:i:i], tracker.hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// region renvoie la zone modifiée, bornée à une image de la taille donnée.
+func (tracker *changeTracker) region(width, height int) Rect {
+	bounds := Rect{Width: width, Height: height}
+	if tracker.all {
+		return bounds
+	}
+	return tracker.dirty.Intersect(bounds)
+}
+
+// clear oublie les zones modifiées.
+func (tracker *changeTracker) clear() {
+	tracker.dirty, tracker.all = Rect{}, false
+}
+
+// Dirty renvoie la plus petite zone contenant tous les pixels modifiés depuis le dernier appel à
+// ClearDirty (ou depuis la création de l'image), vide si aucun ne l'a été.
+func (ppm *PPM) Dirty() Rect {
+	return ppm.changes.region(ppm.width, ppm.height)
+}
+
+// ClearDirty oublie les modifications passées, typiquement après avoir réaffiché la zone Dirty.
+func (ppm *PPM) ClearDirty() {
+	ppm.changes.clear()
+}
+
+// Dirty renvoie la plus petite zone contenant tous les pixels modifiés depuis le dernier ClearDirty.
+func (pgm *PGM) Dirty() Rect {
+	return pgm.changes.region(pgm.width, pgm.height)
+}
+
+// ClearDirty oublie les modifications passées.
+func (pgm *PGM) ClearDirty() {
+	pgm.changes.clear()
+}
+
+// Dirty renvoie la plus petite zone contenant tous les pixels modifiés depuis le dernier ClearDirty.
+func (pbm *PBM) Dirty() Rect {
+	return pbm.changes.region(pbm.width, pbm.height)
+}
+
+// ClearDirty oublie les modifications passées.
+func (pbm *PBM) ClearDirty() {
+	pbm.changes.clear()
+}
+
+// OnChange enregistre une fonction appelée avec la zone modifiée chaque fois que l'image PPM change,
+// par exemple pour rafraîchir l'affichage d'une interface graphique, et renvoie la fonction qui
+// annule l'abonnement. La fonction est appelée dans la goroutine qui modifie l'image et ne doit
+// pas elle-même la modifier.
+func (ppm *PPM) OnChange(fn func(rect Rect)) func() {
+	return ppm.changes.subscribe(fn)
+}
+
+// OnChange enregistre une fonction appelée avec la zone modifiée chaque fois que l'image PGM change.
+func (pgm *PGM) OnChange(fn func(rect Rect)) func() {
+	return pgm.changes.subscribe(fn)
+}
+
+// OnChange enregistre une fonction appelée avec la zone modifiée chaque fois que l'image PBM change.
+func (pbm *PBM) OnChange(fn func(rect Rect)) func() {
+	return pbm.changes.subscribe(fn)
+}
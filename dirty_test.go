@@ -0,0 +1,69 @@
+package netpbm
+
+import "testing"
+
+func TestDirtySet(t *testing.T) {
+	pgm := NewPGM(10, 10, 255)
+	if !pgm.Dirty().Empty() {
+		t.Fatalf("image neuve modifiée: %v", pgm.Dirty())
+	}
+	pgm.Set(2, 3, 1)
+	pgm.Set(5, 1, 1)
+	if got, want := pgm.Dirty(), (Rect{X: 2, Y: 1, Width: 4, Height: 3}); got != want {
+		t.Errorf("zone %v au lieu de %v", got, want)
+	}
+	pgm.ClearDirty()
+	if !pgm.Dirty().Empty() {
+		t.Errorf("zone %v après ClearDirty", pgm.Dirty())
+	}
+}
+
+func TestDirtyDrawing(t *testing.T) {
+	ppm := NewPPM(20, 20, 255)
+	ppm.DrawFilledCircle(Point{X: 10, Y: 10}, 3, Pixel{Red: 255})
+	// La zone notée est exactement celle des pixels peints.
+	var painted Rect
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			if pixel[0] != 0 {
+				painted = painted.Union(Rect{X: x, Y: y, Width: 1, Height: 1})
+			}
+		}
+	}
+	if got := ppm.Dirty(); painted.Empty() || got != painted {
+		t.Errorf("zone %v au lieu de %v", got, painted)
+	}
+}
+
+func TestOnChangeBatch(t *testing.T) {
+	ppm := NewPPM(6, 4, 255)
+	var rects []Rect
+	cancel := ppm.OnChange(func(r Rect) { rects = append(rects, r) })
+
+	ppm.Set(1, 1, []uint8{1, 2, 3})
+	// Une opération sur toute l'image ne notifie qu'une fois, avec ses nouvelles dimensions.
+	ppm.Rotate90CW()
+	want := []Rect{{X: 1, Y: 1, Width: 1, Height: 1}, {Width: 4, Height: 6}}
+	if len(rects) != len(want) || rects[0] != want[0] || rects[1] != want[1] {
+		t.Errorf("notifications %v au lieu de %v", rects, want)
+	}
+
+	cancel()
+	ppm.Invert()
+	if len(rects) != len(want) {
+		t.Errorf("notification après annulation: %v", rects)
+	}
+}
+
+func TestOnChangeOwnOutsideBatch(t *testing.T) {
+	pgm := NewPGM(5, 3, 255)
+	var rects []Rect
+	pgm.OnChange(func(r Rect) { rects = append(rects, r) })
+	pgm.own()
+	if want := (Rect{Width: 5, Height: 3}); len(rects) != 1 || rects[0] != want {
+		t.Errorf("notifications %v au lieu de [%v]", rects, want)
+	}
+	if got := pgm.Dirty(); got != (Rect{Width: 5, Height: 3}) {
+		t.Errorf("zone %v au lieu de l'image entière", got)
+	}
+}
@@ -0,0 +1,107 @@
+package netpbm
+
+import "math"
+
+// distanceInfinity représente une distance inconnue (aucun pixel cible).
+const distanceInfinity = 1e20
+
+// squaredDistance1D calcule la transformée de distance euclidienne au carré d'un signal
+// (algorithme de Felzenszwalb et Huttenlocher, par enveloppe inférieure de paraboles).
+func squaredDistance1D(f []float64) []float64 {
+	n := len(f)
+	d := make([]float64, n)
+	if n == 0 {
+		return d
+	}
+	v := make([]int, n)
+	z := make([]float64, n+1)
+
+	k := 0
+	z[0], z[1] = math.Inf(-1), math.Inf(1)
+	for q := 1; q < n; q++ {
+		s := ((f[q] + float64(q*q)) - (f[v[k]] + float64(v[k]*v[k]))) / float64(2*q-2*v[k])
+		for s <= z[k] {
+			k--
+			s = ((f[q] + float64(q*q)) - (f[v[k]] + float64(v[k]*v[k]))) / float64(2*q-2*v[k])
+		}
+		k++
+		v[k] = q
+		z[k] = s
+		z[k+1] = math.Inf(1)
+	}
+
+	k = 0
+	for q := 0; q < n; q++ {
+		for z[k+1] < float64(q) {
+			k++
+		}
+		d[q] = float64((q-v[k])*(q-v[k])) + f[v[k]]
+	}
+	return d
+}
+
+// distanceTo renvoie, pour chaque pixel, la distance euclidienne exacte au plus proche pixel
+// dont la valeur vaut target.
+func (pbm *PBM) distanceTo(target bool) [][]float64 {
+	grid := make([][]float64, pbm.height)
+	for y := range grid {
+		grid[y] = make([]float64, pbm.width)
+		for x := range grid[y] {
+			if pbm.data[y][x] != target {
+				grid[y][x] = distanceInfinity
+			}
+		}
+	}
+
+	// Passe sur les colonnes puis sur les lignes.
+	column := make([]float64, pbm.height)
+	for x := 0; x < pbm.width; x++ {
+		for y := 0; y < pbm.height; y++ {
+			column[y] = grid[y][x]
+		}
+		for y, d := range squaredDistance1D(column) {
+			grid[y][x] = d
+		}
+	}
+	for y := 0; y < pbm.height; y++ {
+		grid[y] = squaredDistance1D(grid[y])
+		for x := range grid[y] {
+			grid[y][x] = math.Sqrt(grid[y][x])
+		}
+	}
+	return grid
+}
+
+// DistanceTransform renvoie, pour chaque pixel, la distance euclidienne au plus proche pixel noir (à 1).
+// Les pixels noirs sont à distance 0.
+func (pbm *PBM) DistanceTransform() [][]float64 {
+	return pbm.distanceTo(true)
+}
+
+// GenerateSDF renvoie le champ de distance signé de la forme (pixels noirs) sous forme d'image PGM :
+// 128 correspond au contour, les valeurs supérieures à l'intérieur et inférieures à l'extérieur.
+// spread est la distance (en pixels) représentée par l'écart entre 128 et 0 ou 255.
+func (pbm *PBM) GenerateSDF(spread float64) *PGM {
+	if spread <= 0 {
+		spread = 1
+	}
+	outside := pbm.distanceTo(true)
+	inside := pbm.distanceTo(false)
+
+	sdf := NewPGM(pbm.width, pbm.height, 255)
+	sdf.meta = pbm.meta.derive("sdf %g", spread)
+	for y := 0; y < pbm.height; y++ {
+		for x := 0; x < pbm.width; x++ {
+			// Le contour passe entre les pixels : on retranche un demi-pixel de chaque côté.
+			var distance float64
+			if pbm.data[y][x] {
+				distance = inside[y][x] - 0.5
+			} else {
+				distance = -(outside[y][x] - 0.5)
+			}
+			value := 127.5 + distance/spread*127.5
+			sdf.data[y][x] = uint8(clampFloat(math.Round(value), 0, 255))
+		}
+	}
+	return sdf
+}
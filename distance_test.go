@@ -0,0 +1,45 @@
+package netpbm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceTransform(t *testing.T) {
+	pbm := NewPBM(5, 4)
+	pbm.data[1][1] = true
+	distances := pbm.DistanceTransform()
+	for y, row := range distances {
+		for x, d := range row {
+			want := math.Hypot(float64(x-1), float64(y-1))
+			if math.Abs(d-want) > 1e-9 {
+				t.Errorf("(%d, %d): %g au lieu de %g", x, y, d, want)
+			}
+		}
+	}
+}
+
+func TestDistanceTransformEmpty(t *testing.T) {
+	for _, size := range [][2]int{{5, 0}, {0, 5}, {0, 0}} {
+		pbm := NewPBM(size[0], size[1])
+		if distances := pbm.DistanceTransform(); len(distances) != size[1] {
+			t.Errorf("%dx%d: %d lignes au lieu de %d", size[0], size[1], len(distances), size[1])
+		}
+		if w, h := pbm.GenerateSDF(4).Size(); w != size[0] || h != size[1] {
+			t.Errorf("%dx%d: champ de distance de %dx%d", size[0], size[1], w, h)
+		}
+	}
+}
+
+func TestGenerateSDFSigns(t *testing.T) {
+	pbm := NewPBM(9, 9)
+	for y := 3; y < 6; y++ {
+		for x := 3; x < 6; x++ {
+			pbm.data[y][x] = true
+		}
+	}
+	sdf := pbm.GenerateSDF(4)
+	if inside, outside := sdf.At(4, 4), sdf.At(0, 0); inside <= 128 || outside >= 128 {
+		t.Errorf("intérieur %d, extérieur %d", inside, outside)
+	}
+}
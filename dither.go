@@ -0,0 +1,176 @@
+package netpbm
+
+import "fmt"
+
+// DiffusionWeight représente la part d'erreur transmise au voisin situé en (DX, DY).
+// DX est exprimé dans le sens du parcours : il est inversé sur les lignes parcourues de droite à gauche.
+type DiffusionWeight struct {
+	DX, DY int
+	Weight float64
+}
+
+// DiffusionKernel représente un noyau de diffusion d'erreur.
+// La somme des poids divisée par Divisor peut être inférieure à 1 (Atkinson ne diffuse que 3/4 de l'erreur).
+type DiffusionKernel struct {
+	Weights []DiffusionWeight
+	Divisor float64
+}
+
+var (
+	// FloydSteinberg est le noyau classique de Floyd et Steinberg.
+	FloydSteinberg = DiffusionKernel{
+		Weights: []DiffusionWeight{
+			{1, 0, 7},
+			{-1, 1, 3}, {0, 1, 5}, {1, 1, 1},
+		},
+		Divisor: 16,
+	}
+
+	// JarvisJudiceNinke est le noyau de Jarvis, Judice et Ninke, réparti sur deux lignes.
+	JarvisJudiceNinke = DiffusionKernel{
+		Weights: []DiffusionWeight{
+			{1, 0, 7}, {2, 0, 5},
+			{-2, 1, 3}, {-1, 1, 5}, {0, 1, 7}, {1, 1, 5}, {2, 1, 3},
+			{-2, 2, 1}, {-1, 2, 3}, {0, 2, 5}, {1, 2, 3}, {2, 2, 1},
+		},
+		Divisor: 48,
+	}
+
+	// Stucki est une variante plus nette du noyau de Jarvis, Judice et Ninke.
+	Stucki = DiffusionKernel{
+		Weights: []DiffusionWeight{
+			{1, 0, 8}, {2, 0, 4},
+			{-2, 1, 2}, {-1, 1, 4}, {0, 1, 8}, {1, 1, 4}, {2, 1, 2},
+			{-2, 2, 1}, {-1, 2, 2}, {0, 2, 4}, {1, 2, 2}, {2, 2, 1},
+		},
+		Divisor: 42,
+	}
+
+	// Atkinson est le noyau du Macintosh d'origine, qui ne diffuse que 3/4 de l'erreur.
+	Atkinson = DiffusionKernel{
+		Weights: []DiffusionWeight{
+			{1, 0, 1}, {2, 0, 1},
+			{-1, 1, 1}, {0, 1, 1}, {1, 1, 1},
+			{0, 2, 1},
+		},
+		Divisor: 8,
+	}
+)
+
+// DitherOptions règle le tramage par diffusion d'erreur.
+type DitherOptions struct {
+	Kernel     DiffusionKernel // FloydSteinberg si vide
+	Serpentine bool            // Parcourir une ligne sur deux de droite à gauche
+}
+
+// kernel renvoie le noyau à utiliser.
+func (options DitherOptions) kernel() DiffusionKernel {
+	if len(options.Kernel.Weights) == 0 || options.Kernel.Divisor == 0 {
+		return FloydSteinberg
+	}
+	return options.Kernel
+}
+
+// diffuse parcourt les canaux (valeurs flottantes modifiables) en quantifiant chaque pixel avec
+// quantize, qui reçoit les valeurs courantes et renvoie les valeurs retenues, puis répartit l'erreur.
+func diffuse(channels [][][]float64, width, height int, options DitherOptions, quantize func(x, y int, values []float64) []float64) {
+	kernel := options.kernel()
+	values := make([]float64, len(channels))
+
+	for y := 0; y < height; y++ {
+		reverse := options.Serpentine && y%2 == 1
+		for i := 0; i < width; i++ {
+			x, direction := i, 1
+			if reverse {
+				x, direction = width-1-i, -1
+			}
+
+			for c := range channels {
+				values[c] = channels[c][y][x]
+			}
+			chosen := quantize(x, y, values)
+
+			for c := range channels {
+				errorValue := values[c] - chosen[c]
+				channels[c][y][x] = chosen[c]
+				for _, w := range kernel.Weights {
+					nx, ny := x+w.DX*direction, y+w.DY
+					if nx >= 0 && nx < width && ny < height {
+						channels[c][ny][nx] += errorValue * w.Weight / kernel.Divisor
+					}
+				}
+			}
+		}
+	}
+}
+
+// Dither convertit l'image PGM en PBM par diffusion d'erreur (les pixels sombres deviennent noirs).
+func (pgm *PGM) Dither(options DitherOptions) *PBM {
+	gray := make([][]float64, pgm.height)
+	for y := range gray {
+		gray[y] = make([]float64, pgm.width)
+		for x := range gray[y] {
+			gray[y][x] = float64(pgm.data[y][x])
+		}
+	}
+
+	result := NewPBM(pgm.width, pgm.height)
+	result.meta = pgm.meta.derive("dither")
+	threshold := float64(pgm.max) / 2
+	diffuse([][][]float64{gray}, pgm.width, pgm.height, options, func(x, y int, values []float64) []float64 {
+		if values[0] > threshold {
+			return []float64{float64(pgm.max)}
+		}
+		result.data[y][x] = true
+		return []float64{0}
+	})
+
+	return result
+}
+
+// DitherPalette réduit l'image PPM aux seules couleurs de la palette, en diffusant l'erreur de
+// quantification pour préserver les nuances moyennes. Chaque pixel reçoit la couleur la plus proche
+// (distance euclidienne dans l'espace RVB).
+func (ppm *PPM) DitherPalette(palette []Pixel, options DitherOptions) (*PPM, error) {
+	if len(palette) == 0 {
+		return nil, fmt.Errorf("palette vide")
+	}
+
+	channels := make([][][]float64, 3)
+	for c := range channels {
+		channels[c] = make([][]float64, ppm.height)
+		for y := range channels[c] {
+			channels[c][y] = make([]float64, ppm.width)
+			for x := range channels[c][y] {
+				channels[c][y][x] = float64(ppm.data[y][x][c])
+			}
+		}
+	}
+
+	result := NewPPM(ppm.width, ppm.height, ppm.max)
+	result.magicNumber = ppm.magicNumber
+	result.meta = ppm.meta.derive("dither palette %d", len(palette))
+	chosen := make([]float64, 3)
+	diffuse(channels, ppm.width, ppm.height, options, func(x, y int, values []float64) []float64 {
+		color := nearestColor(palette, values[0], values[1], values[2])
+		result.setPixel(x, y, color)
+		chosen[0], chosen[1], chosen[2] = float64(color.Red), float64(color.Green), float64(color.Blue)
+		return chosen
+	})
+
+	return result, nil
+}
+
+// nearestColor renvoie la couleur de la palette la plus proche de (r, g, b).
+func nearestColor(palette []Pixel, r, g, b float64) Pixel {
+	best := palette[0]
+	bestDistance := -1.0
+	for _, color := range palette {
+		dr, dg, db := r-float64(color.Red), g-float64(color.Green), b-float64(color.Blue)
+		distance := dr*dr + dg*dg + db*db
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = color, distance
+		}
+	}
+	return best
+}
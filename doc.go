@@ -0,0 +1,12 @@
+// Package netpbm lit, écrit et manipule les images Netpbm : PBM (bitonales), PGM (niveaux de gris)
+// et PPM (couleurs), dans leurs variantes ASCII (P1 à P3) et binaires (P4 à P6).
+//
+// Les images se lisent avec ReadPBM, ReadPGM et ReadPPM (ou DecodePBM… depuis un io.Reader), se
+// créent avec NewPBM, NewPGM et NewPPM, et s'enregistrent avec Save ou Encode. Des programmes
+// d'exemple se trouvent dans cmd/, ainsi que netpbmd, qui convertit les fichiers déposés dans un
+// répertoire.
+//
+// Les fonctions qui tirent d'autres dépendances sont dans des sous-paquets facultatifs : httpserve
+// sert des images transformées par HTTP, preview les affiche en direct dans un navigateur et
+// testutil les compare à des images de référence dans les tests.
+package netpbm
@@ -0,0 +1,118 @@
+package netpbm
+
+import "math"
+
+// DocumentOrientation est la correction proposée par DetectOrientation pour redresser un document
+// numérisé : la rotation à appliquer dans le sens des aiguilles d'une montre (0, 90, 180 ou 270
+// degrés) et la confiance dans cette proposition, entre 0 (aucune) et 1.
+type DocumentOrientation struct {
+	Rotation   int
+	Confidence float64
+}
+
+// textLines mesure, à partir du profil de projection d'un document (nombre de pixels noirs par
+// ligne ou par colonne, dans l'ordre de lecture supposé), à quel point il est composé de lignes de
+// texte perpendiculaires à ce profil, et dans quel sens elles sont écrites.
+//
+// La régularité des lignes se lit dans la dispersion du profil : des lignes de texte séparées par
+// des interlignes blancs le font alterner fortement. Le sens se lit dans chaque ligne : en écriture
+// latine, les hampes (b, d, h, l, majuscules) dépassent du corps des minuscules plus souvent que
+// les jambages (g, p, q, y), si bien que la zone au-dessus du corps est plus chargée que celle
+// en dessous. ascent et descent totalisent les pixels noirs de ces deux zones sur toutes les lignes.
+func textLines(profile []int) (regularity, ascent, descent float64) {
+	total := 0
+	for _, count := range profile {
+		total += count
+	}
+	if total == 0 {
+		return 0, 0, 0
+	}
+	mean := float64(total) / float64(len(profile))
+	for _, count := range profile {
+		d := float64(count) - mean
+		regularity += d * d
+	}
+	regularity /= float64(len(profile)) * mean * mean
+
+	for start := 0; start < len(profile); {
+		if profile[start] == 0 {
+			start++
+			continue
+		}
+		end, peak := start, 0
+		for end < len(profile) && profile[end] > 0 {
+			peak = max(peak, profile[end])
+			end++
+		}
+		if end-start >= 4 {
+			// Le corps de la ligne est la bande où la densité dépasse la moitié du maximum.
+			top, bottom := start, end-1
+			for 2*profile[top] < peak {
+				top++
+			}
+			for 2*profile[bottom] < peak {
+				bottom--
+			}
+			for y := start; y < top; y++ {
+				ascent += float64(profile[y])
+			}
+			for y := bottom + 1; y < end; y++ {
+				descent += float64(profile[y])
+			}
+		}
+		start = end
+	}
+	return regularity, ascent, descent
+}
+
+// DetectOrientation devine l'orientation d'un document texte numérisé par ses profils de
+// projection, sans métadonnées : les lignes de texte donnent la direction (horizontale ou
+// verticale), la forme des lettres le sens. La confiance est faible pour une page sans texte
+// ou presque, et sur une écriture sans hampes ni jambages (chiffres, capitales seules).
+func (pbm *PBM) DetectOrientation() DocumentOrientation {
+	rows := make([]int, pbm.height)
+	columns := make([]int, pbm.width)
+	for y, row := range pbm.data {
+		for x, black := range row {
+			if black {
+				rows[y]++
+				columns[x]++
+			}
+		}
+	}
+
+	// Lire les colonnes de gauche à droite revient à lire les lignes de l'image tournée de 90°.
+	rowRegularity, rowAscent, rowDescent := textLines(rows)
+	columnRegularity, columnAscent, columnDescent := textLines(columns)
+	upright := 0
+	regularity, ascent, descent := rowRegularity, rowAscent, rowDescent
+	if columnRegularity > rowRegularity {
+		upright = 90
+		regularity, ascent, descent = columnRegularity, columnAscent, columnDescent
+	}
+	if regularity == 0 || ascent+descent == 0 {
+		return DocumentOrientation{}
+	}
+
+	result := DocumentOrientation{Rotation: upright}
+	if descent > ascent {
+		result.Rotation = upright + 180
+	}
+	direction := (regularity - math.Min(rowRegularity, columnRegularity)) / regularity
+	sense := math.Abs(ascent-descent) / (ascent + descent)
+	result.Confidence = math.Min(direction, sense)
+	return result
+}
+
+// AutoOrient redresse l'image PBM selon DetectOrientation si la confiance atteint minConfidence,
+// et renvoie l'orientation détectée. Dans une chaîne de traitement par lots, une confiance minimale
+// de 0.2 à 0.3 laisse en l'état les pages douteuses plutôt que de les retourner à tort.
+func (pbm *PBM) AutoOrient(minConfidence float64) DocumentOrientation {
+	orientation := pbm.DetectOrientation()
+	if orientation.Rotation != 0 && orientation.Confidence >= minConfidence {
+		for i := 0; i < orientation.Rotation/90; i++ {
+			pbm.Rotate90CW()
+		}
+	}
+	return orientation
+}
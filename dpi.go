@@ -0,0 +1,69 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// dpiPrefix commence les commentaires de résolution.
+const dpiPrefix = "dpi: "
+
+// parseDPI reconnaît un commentaire de résolution.
+func parseDPI(comment string) (float64, bool) {
+	value, ok := strings.CutPrefix(comment, dpiPrefix)
+	if !ok {
+		return 0, false
+	}
+	dpi, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || !(dpi > 0) || math.IsInf(dpi, 0) {
+		return 0, false
+	}
+	return dpi, true
+}
+
+// Unit est une unité de longueur physique.
+type Unit int
+
+const (
+	UnitInch       Unit = iota // Pouce (25,4 mm)
+	UnitMillimeter             // Millimètre
+	UnitCentimeter             // Centimètre
+	UnitPoint                  // Point typographique (1/72 de pouce)
+)
+
+// inches renvoie la longueur d'une unité en pouces.
+func (unit Unit) inches() float64 {
+	switch unit {
+	case UnitMillimeter:
+		return 1 / 25.4
+	case UnitCentimeter:
+		return 1 / 2.54
+	case UnitPoint:
+		return 1.0 / 72
+	}
+	return 1
+}
+
+// PixelsToUnits renvoie la longueur physique de pixels pixels imprimés à la résolution dpi.
+func PixelsToUnits(pixels int, dpi float64, unit Unit) float64 {
+	return float64(pixels) / dpi / unit.inches()
+}
+
+// UnitsToPixels renvoie le nombre de pixels, arrondi, qui couvre length à la résolution dpi :
+// UnitsToPixels(210, 300, UnitMillimeter) donne la largeur d'une page A4 à 300 dpi.
+func UnitsToPixels(length float64, dpi float64, unit Unit) int {
+	return int(math.Round(length * unit.inches() * dpi))
+}
+
+// PhysicalSize renvoie la taille d'impression de l'image dans l'unité donnée, d'après la résolution
+// de ses métadonnées (voir Metadata.DPI), ou une erreur si elle n'en a pas.
+func PhysicalSize(img Image, unit Unit) (float64, float64, error) {
+	meta := metadataOf(img)
+	if meta == nil || meta.DPI <= 0 {
+		return 0, 0, fmt.Errorf("résolution de l'image inconnue")
+	}
+	width, height := img.Size()
+	return PixelsToUnits(width, meta.DPI, unit), PixelsToUnits(height, meta.DPI, unit), nil
+}
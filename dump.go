@@ -0,0 +1,118 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DumpFormat est la base dans laquelle DumpRegion écrit les valeurs des pixels.
+type DumpFormat int
+
+const (
+	DumpDecimal DumpFormat = iota // Valeurs décimales ; composantes PPM séparées par des virgules
+	DumpHex                       // Valeurs hexadécimales ; composantes PPM accolées, comme dans #rrggbb
+)
+
+// regionDumper écrit une grille de valeurs séparées par deux espaces, précédée des abscisses et
+// dont chaque ligne commence par son ordonnée.
+type regionDumper struct {
+	r      Rect
+	format DumpFormat
+	digits int // Nombre de chiffres d'une composante
+}
+
+// newRegionDumper prépare l'écriture de la zone r, bornée à une image de la taille donnée.
+func newRegionDumper(r Rect, width, height, max int, format DumpFormat) (*regionDumper, error) {
+	clipped := r.Intersect(Rect{Width: width, Height: height})
+	if clipped.Empty() {
+		return nil, fmt.Errorf("zone hors de l'image: %+v", r)
+	}
+	return &regionDumper{r: clipped, format: format, digits: len(formatLevel(max, format))}, nil
+}
+
+// formatLevel formate une valeur dans la base demandée.
+func formatLevel(value int, format DumpFormat) string {
+	if format == DumpHex {
+		return strconv.FormatInt(int64(value), 16)
+	}
+	return strconv.Itoa(value)
+}
+
+// cell formate un pixel de une ou plusieurs composantes, chacune sur dumper.digits caractères.
+func (dumper *regionDumper) cell(values ...uint8) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		if dumper.format == DumpHex {
+			parts[i] = fmt.Sprintf("%0*x", dumper.digits, value)
+		} else {
+			parts[i] = fmt.Sprintf("%*d", dumper.digits, value)
+		}
+	}
+	if dumper.format == DumpHex {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts, ",")
+}
+
+// dump écrit la grille ; cell(x, y) renvoie le texte du pixel (x, y), de largeur cellWidth.
+func (dumper *regionDumper) dump(w io.Writer, cellWidth int, cell func(x, y int) string) error {
+	r := dumper.r
+	label := len(strconv.Itoa(r.Y + r.Height - 1))
+	if width := len(strconv.Itoa(r.X + r.Width - 1)); width > cellWidth {
+		cellWidth = width
+	}
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "%*s |", label, "")
+	for x := r.X; x < r.X+r.Width; x++ {
+		fmt.Fprintf(writer, "  %*d", cellWidth, x)
+	}
+	fmt.Fprintf(writer, "\n%s-+%s\n", strings.Repeat("-", label), strings.Repeat("-", (cellWidth+2)*r.Width))
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		fmt.Fprintf(writer, "%*d |", label, y)
+		for x := r.X; x < r.X+r.Width; x++ {
+			fmt.Fprintf(writer, "  %*s", cellWidth, cell(x, y))
+		}
+		writer.WriteByte('\n')
+	}
+	return writer.Flush()
+}
+
+// DumpRegion écrit les valeurs des pixels de la zone r de l'image PPM sous forme de grille, avec les
+// coordonnées en marge : bien plus lisible que Display pour examiner quelques pixels d'une grande image.
+func (ppm *PPM) DumpRegion(r Rect, w io.Writer, format DumpFormat) error {
+	dumper, err := newRegionDumper(r, ppm.width, ppm.height, ppm.max, format)
+	if err != nil {
+		return err
+	}
+	cellWidth := len(dumper.cell(0, 0, 0))
+	return dumper.dump(w, cellWidth, func(x, y int) string {
+		pixel := ppm.data[y][x]
+		return dumper.cell(pixel[0], pixel[1], pixel[2])
+	})
+}
+
+// DumpRegion écrit les valeurs des pixels de la zone r de l'image PGM sous forme de grille.
+func (pgm *PGM) DumpRegion(r Rect, w io.Writer, format DumpFormat) error {
+	dumper, err := newRegionDumper(r, pgm.width, pgm.height, pgm.max, format)
+	if err != nil {
+		return err
+	}
+	return dumper.dump(w, dumper.digits, func(x, y int) string {
+		return dumper.cell(pgm.data[y][x])
+	})
+}
+
+// DumpRegion écrit les pixels de la zone r de l'image PBM sous forme de grille (1 pour noir).
+func (pbm *PBM) DumpRegion(r Rect, w io.Writer, format DumpFormat) error {
+	dumper, err := newRegionDumper(r, pbm.width, pbm.height, 1, format)
+	if err != nil {
+		return err
+	}
+	return dumper.dump(w, 1, func(x, y int) string {
+		return dumper.cell(uint8(boolToInt(pbm.data[y][x])))
+	})
+}
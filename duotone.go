@@ -0,0 +1,42 @@
+package netpbm
+
+import "math"
+
+// Duotone colore l'image PGM en l'étirant entre deux encres : les noirs deviennent dark, les
+// blancs light et les gris un mélange des deux, proportionnel au niveau. Le résultat est une image
+// PPM de valeur maximale 255, comme celle des couleurs données.
+func (pgm *PGM) Duotone(dark, light Pixel) *PPM {
+	return pgm.colorRamp([]Pixel{dark, light}, "duotone")
+}
+
+// Tritone colore l'image PGM comme Duotone, avec une troisième encre mid pour les gris moyens.
+func (pgm *PGM) Tritone(dark, mid, light Pixel) *PPM {
+	return pgm.colorRamp([]Pixel{dark, mid, light}, "tritone")
+}
+
+// colorRamp colore l'image PGM le long des couleurs données, réparties régulièrement du noir au blanc.
+func (pgm *PGM) colorRamp(stops []Pixel, operation string) *PPM {
+	// Une couleur par niveau de gris, calculée une fois.
+	ramp := make([][]uint8, pgm.max+1)
+	for level := range ramp {
+		t := 0.0
+		if pgm.max > 0 {
+			t = float64(level) / float64(pgm.max) * float64(len(stops)-1)
+		}
+		i := min(int(t), len(stops)-2)
+		a, b, f := stops[i], stops[i+1], t-float64(i)
+		mix := func(a, b uint8) uint8 {
+			return uint8(math.Round(float64(a) + (float64(b)-float64(a))*f))
+		}
+		ramp[level] = []uint8{mix(a.Red, b.Red), mix(a.Green, b.Green), mix(a.Blue, b.Blue)}
+	}
+
+	result := NewPPM(pgm.width, pgm.height, 255)
+	for y, row := range pgm.data {
+		for x, value := range row {
+			copy(result.data[y][x], ramp[min(int(value), pgm.max)])
+		}
+	}
+	result.meta = pgm.meta.derive(operation)
+	return result
+}
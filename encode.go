@@ -0,0 +1,162 @@
+package netpbm
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// EncodeOptions règle l'écriture d'une image par Encode et SaveImage, sans modifier l'image
+// elle-même (au contraire de SetMagicNumber ou SetMaxValue). La valeur zéro écrit l'image telle
+// quelle, dans le format de son nombre magique.
+type EncodeOptions struct {
+	// Format est le format de sortie ; s'il est nul, celui du nombre magique de l'image.
+	Format Format
+	// Raw et Plain forcent la variante binaire (P4 à P6) ou ASCII (P1 à P3) du format.
+	Raw, Plain bool
+	// MaxValue remplace la valeur maximale écrite, les niveaux étant convertis (celle de l'image si nulle).
+	MaxValue int
+	// Comments sont écrits dans l'en-tête, une ligne "# …" chacun.
+	Comments []string
+	// LineWidth limite la longueur des lignes des formats ASCII (une ligne par ligne d'image si nul).
+	// La spécification Netpbm recommande 70 caractères au plus.
+	LineWidth int
+	// Provenance ajoute à l'en-tête la liste des opérations appliquées à l'image (voir Metadata).
+	Provenance bool
+	// Checksum ajoute à l'en-tête l'empreinte SHA-256 des pixels écrits ("# sha256:…"), vérifiée
+	// par les lecteurs pour détecter la corruption silencieuse d'images archivées.
+	Checksum bool
+	// Preview, s'il n'est pas nil, reçoit avant l'image complète un aperçu dans le même format,
+	// réduit à une ligne et une colonne sur PreviewStep (8 si nul). Sur un stockage lent, une
+	// interface peut ainsi afficher l'aperçu sans attendre la fin de l'écriture.
+	Preview io.Writer
+	// PreviewStep est le pas d'échantillonnage de l'aperçu.
+	PreviewStep int
+}
+
+// format renvoie le format de sortie pour l'image.
+func (options EncodeOptions) format(img Image) Format {
+	format := options.Format
+	if format == 0 {
+		format = originalFormat(img)
+	}
+	switch {
+	case options.Raw && !format.Raw():
+		format += 3
+	case options.Plain && format.Raw():
+		format -= 3
+	}
+	return format
+}
+
+// previewStep renvoie le pas d'échantillonnage de l'aperçu.
+func (options EncodeOptions) previewStep() int {
+	if options.PreviewStep <= 0 {
+		return 8
+	}
+	return options.PreviewStep
+}
+
+// subsample renvoie une copie de l'image réduite à une ligne et une colonne sur step, sans
+// filtrage : c'est ce qui la rend assez rapide pour un aperçu.
+func subsample(img Image, step int) Image {
+	width, height := img.Size()
+	width, height = (width+step-1)/step, (height+step-1)/step
+	switch img := img.(type) {
+	case *PackedPBM:
+		result := NewPackedPBM(width, height)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				result.Set(x, y, img.At(x*step, y*step))
+			}
+		}
+		return result
+	case *PBM:
+		result := NewPBM(width, height)
+		result.magicNumber = img.magicNumber
+		for y, row := range result.data {
+			for x := range row {
+				row[x] = img.data[y*step][x*step]
+			}
+		}
+		return result
+	case *PGM:
+		result := NewPGM(width, height, img.max)
+		result.magicNumber = img.magicNumber
+		for y, row := range result.data {
+			for x := range row {
+				row[x] = img.data[y*step][x*step]
+			}
+		}
+		return result
+	case *PPM:
+		result := NewPPM(width, height, img.max)
+		result.magicNumber = img.magicNumber
+		for y, row := range result.data {
+			for x := range row {
+				copy(row[x], img.data[y*step][x*step])
+			}
+		}
+		return result
+	}
+	return img
+}
+
+// Encode écrit l'image selon les options, en la convertissant si nécessaire comme le fait Write.
+func Encode(w io.Writer, img Image, options EncodeOptions) error {
+	format := options.format(img)
+	if meta := metadataOf(img); meta != nil {
+		options.Comments = meta.headerComments(options)
+	}
+	if options.Preview != nil {
+		preview := options
+		preview.Preview, preview.Comments, preview.Checksum = nil, nil, false
+		if err := Encode(options.Preview, subsample(img, options.previewStep()), preview); err != nil {
+			return err
+		}
+	}
+	// Une image compacte écrite en P4 n'a pas besoin d'être dépaquetée.
+	if packed, ok := img.(*PackedPBM); ok && format == FormatP4 {
+		if options.Checksum {
+			options.Comments = append(options.Comments, packed.checksum())
+		}
+		writer := bufio.NewWriter(w)
+		writeHeader(writer, FormatP4, packed.width, packed.height, 0, options.Comments)
+		writer.Write(packed.bits)
+		return writer.Flush()
+	}
+	converted, err := Convert(img, format, ConvertOptions{MaxValue: options.MaxValue})
+	if err != nil {
+		return err
+	}
+	if options.Checksum {
+		options.Comments = append(options.Comments, rasterChecksum(converted))
+	}
+
+	writer := bufio.NewWriter(w)
+	switch converted := converted.(type) {
+	case *PBM:
+		err = writePBM(writer, converted, format.Raw(), options)
+	case *PGM:
+		err = writePGM(writer, converted, format.Raw(), options)
+	case *PPM:
+		err = writePPM(writer, converted, format.Raw(), options)
+	}
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// SaveImage enregistre l'image dans un fichier selon les options.
+func SaveImage(filename string, img Image, options EncodeOptions) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := Encode(file, img, options); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
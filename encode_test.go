@@ -0,0 +1,168 @@
+package netpbm
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// decodeAny lit une image de n'importe quel format d'après son nombre magique.
+func decodeAny(r io.Reader) (Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch format, _ := ParseFormat(string(data[:2])); format {
+	case FormatP1, FormatP4:
+		return DecodePBM(bytes.NewReader(data))
+	case FormatP2, FormatP5:
+		return DecodePGM(bytes.NewReader(data))
+	default:
+		return DecodePPM(bytes.NewReader(data))
+	}
+}
+
+// testSources renvoie une image de chaque type, noire à gauche et blanche à droite.
+func testSources() []Image {
+	pbm := NewPBM(4, 2)
+	pgm := NewPGM(4, 2, 255)
+	ppm := NewPPM(4, 2, 255)
+	for y := 0; y < 2; y++ {
+		pbm.Set(0, y, true)
+		pbm.Set(1, y, true)
+		for x := 2; x < 4; x++ {
+			pgm.Set(x, y, 255)
+			ppm.Set(x, y, []uint8{255, 255, 255})
+		}
+		pgm.Set(1, y, 60)
+		ppm.Set(1, y, []uint8{60, 60, 60})
+	}
+	return []Image{pbm, pgm, ppm}
+}
+
+// isBlack indique si le pixel (x, y) de l'image est noir.
+func isBlack(img Image, x, y int) bool {
+	switch img := img.(type) {
+	case *PBM:
+		return img.At(x, y)
+	case *PGM:
+		return img.At(x, y) == 0
+	case *PPM:
+		return bytes.Equal(img.At(x, y), []uint8{0, 0, 0})
+	}
+	return false
+}
+
+func TestConvertMatrix(t *testing.T) {
+	formats := []Format{FormatP1, FormatP2, FormatP3, FormatP4, FormatP5, FormatP6}
+	for _, source := range testSources() {
+		for _, target := range formats {
+			var buf bytes.Buffer
+			if err := Write(&buf, source, target); err != nil {
+				t.Fatalf("%T vers %s: %v", source, target, err)
+			}
+			if !strings.HasPrefix(buf.String(), target.MagicNumber()+"\n") {
+				t.Fatalf("%T vers %s: en-tête %q", source, target, buf.String()[:2])
+			}
+			got, err := decodeAny(&buf)
+			if err != nil {
+				t.Fatalf("%T vers %s: relecture: %v", source, target, err)
+			}
+			if width, height := got.Size(); width != 4 || height != 2 {
+				t.Fatalf("%T vers %s: taille %dx%d", source, target, width, height)
+			}
+			// Le noir et le blanc survivent à toutes les conversions.
+			if !isBlack(got, 0, 1) || isBlack(got, 3, 1) {
+				t.Errorf("%T vers %s: noir et blanc non conservés", source, target)
+			}
+		}
+	}
+}
+
+func TestEncodeOptions(t *testing.T) {
+	pgm := testPGM()
+	pgm.meta.record("invert")
+	encode := func(options EncodeOptions) string {
+		return encodeImage(t, pgm, options)
+	}
+
+	if got := encode(EncodeOptions{Raw: true}); !strings.HasPrefix(got, "P5\n") {
+		t.Errorf("Raw: en-tête %q", got[:2])
+	}
+	pgm.SetMagicNumber("P5")
+	if got := encode(EncodeOptions{Plain: true}); !strings.HasPrefix(got, "P2\n") {
+		t.Errorf("Plain: en-tête %q", got[:2])
+	}
+	pgm.SetMagicNumber("P2")
+
+	rescaled, err := DecodePGM(strings.NewReader(encode(EncodeOptions{MaxValue: 100})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rescaled.MaxValue() != 100 || rescaled.At(4, 0) != 100 {
+		t.Errorf("MaxValue: maximum %d, pixel %d", rescaled.MaxValue(), rescaled.At(4, 0))
+	}
+
+	if got := encode(EncodeOptions{Comments: []string{"a", "b\nc"}}); !strings.Contains(got, "P2\n# a\n# b\n# c\n5 3\n") {
+		t.Errorf("Comments: %q", got)
+	}
+
+	wide := NewPGM(40, 2, 255)
+	for _, line := range strings.Split(encodeImage(t, wide, EncodeOptions{LineWidth: 70}), "\n") {
+		if len(line) > 70 {
+			t.Errorf("LineWidth: ligne de %d caractères", len(line))
+		}
+	}
+
+	withProvenance := encode(EncodeOptions{Provenance: true})
+	if !strings.Contains(withProvenance, "# "+provenancePrefix+"invert\n") {
+		t.Errorf("Provenance: %q", withProvenance)
+	}
+	if strings.Contains(encode(EncodeOptions{}), provenancePrefix) {
+		t.Error("provenance écrite sans l'option Provenance")
+	}
+	decoded, err := DecodePGM(strings.NewReader(withProvenance))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provenance := decoded.Metadata().Provenance; len(provenance) != 1 || provenance[0] != "invert" {
+		t.Errorf("Provenance relue: %q", provenance)
+	}
+}
+
+// encodeImage renvoie l'image encodée selon les options.
+func encodeImage(t *testing.T, img Image, options EncodeOptions) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Encode(&buf, img, options); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestEncodeChecksum(t *testing.T) {
+	for _, img := range append(testSources(), testPBM(13, 5, 0).Pack()) {
+		for _, raw := range []bool{false, true} {
+			encoded := encodeImage(t, img, EncodeOptions{Checksum: true, Raw: raw, Plain: !raw})
+			if !strings.Contains(encoded, "# "+checksumPrefix) {
+				t.Fatalf("%T: empreinte absente", img)
+			}
+			if _, err := decodeAny(strings.NewReader(encoded)); err != nil {
+				t.Fatalf("%T: image intacte refusée: %v", img, err)
+			}
+
+			// Un pixel modifié est détecté : en binaire, le premier du dernier octet, les bits de
+			// poids faible d'un PBM pouvant n'être que du remplissage.
+			corrupted := []byte(encoded)
+			if raw {
+				corrupted[len(corrupted)-1] ^= 0x80
+			} else {
+				corrupted[len(corrupted)-2] ^= 1 // Avant le saut de ligne final
+			}
+			if _, err := decodeAny(bytes.NewReader(corrupted)); err == nil || !strings.Contains(err.Error(), "empreinte") {
+				t.Errorf("%T (raw %v): image corrompue non détectée: %v", img, raw, err)
+			}
+		}
+	}
+}
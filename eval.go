@@ -0,0 +1,352 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Program est une suite d'affectations compilée, évaluée pour chaque pixel, par exemple :
+//
+//	r = 255 - r; g = g * 0.5; b = max(b, (x + y) % 64)
+//
+// Les expressions utilisent les nombres réels, les opérateurs + - * / % et les parenthèses, ainsi
+// que les fonctions min, max, abs, sqrt, pow, floor, sin et cos. Variables disponibles : les canaux
+// (r, g, b pour une image PPM, v pour une image PGM), x et y, la taille w et h, et max, la valeur
+// maximale de l'image. Seuls les canaux peuvent être affectés ; les affectations s'enchaînent, la
+// suivante voit le résultat de la précédente. Les valeurs finales sont arrondies et bornées à [0, max].
+type Program struct {
+	assignments []assignment
+	slots       map[string]int
+}
+
+// assignment affecte le résultat d'une expression à une variable.
+type assignment struct {
+	slot int
+	expr expr
+}
+
+// expr est une expression compilée, évaluée sur les valeurs des variables.
+type expr func(env []float64) float64
+
+// Emplacements des variables communes dans l'environnement d'évaluation ; les canaux suivent.
+const (
+	slotX = iota
+	slotY
+	slotW
+	slotH
+	slotMax
+	slotChannels
+)
+
+// Compile compile un programme pour une image dont les canaux portent les noms donnés
+// ("r", "g", "b" pour une image PPM, "v" pour une image PGM).
+func Compile(source string, channels ...string) (*Program, error) {
+	program := &Program{slots: map[string]int{"x": slotX, "y": slotY, "w": slotW, "h": slotH, "max": slotMax}}
+	for i, name := range channels {
+		program.slots[name] = slotChannels + i
+	}
+
+	for _, statement := range strings.Split(source, ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		name, expression, ok := strings.Cut(statement, "=")
+		if !ok {
+			return nil, fmt.Errorf("affectation attendue: %s", strings.TrimSpace(statement))
+		}
+		name = strings.TrimSpace(name)
+		slot, known := program.slots[name]
+		if !known || slot < slotChannels {
+			return nil, fmt.Errorf("variable non affectable: %s", name)
+		}
+
+		parser := &exprParser{tokens: tokenize(expression), slots: program.slots}
+		compiled, err := parser.parse()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", strings.TrimSpace(statement), err)
+		}
+		program.assignments = append(program.assignments, assignment{slot: slot, expr: compiled})
+	}
+	return program, nil
+}
+
+// run exécute le programme sur l'environnement donné.
+func (program *Program) run(env []float64) {
+	for _, assignment := range program.assignments {
+		env[assignment.slot] = assignment.expr(env)
+	}
+}
+
+// toLevel arrondit et borne une valeur calculée.
+func toLevel(value float64, max int) uint8 {
+	if math.IsNaN(value) {
+		return 0
+	}
+	return uint8(clampFloat(math.Round(value), 0, float64(max)))
+}
+
+// Eval applique à chaque pixel de l'image PPM un programme portant sur les canaux r, g et b.
+func (ppm *PPM) Eval(source string) error {
+	program, err := Compile(source, "r", "g", "b")
+	if err != nil {
+		return err
+	}
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("eval %s", strings.Join(strings.Fields(source), " "))
+	env := make([]float64, slotChannels+3)
+	env[slotW], env[slotH], env[slotMax] = float64(ppm.width), float64(ppm.height), float64(ppm.max)
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			env[slotX], env[slotY] = float64(x), float64(y)
+			for c := 0; c < 3; c++ {
+				env[slotChannels+c] = float64(pixel[c])
+			}
+			program.run(env)
+			value := make([]uint8, 3)
+			for c := 0; c < 3; c++ {
+				value[c] = toLevel(env[slotChannels+c], ppm.max)
+			}
+			ppm.data[y][x] = value
+		}
+	}
+	return nil
+}
+
+// Eval applique à chaque pixel de l'image PGM un programme portant sur le niveau v.
+func (pgm *PGM) Eval(source string) error {
+	program, err := Compile(source, "v")
+	if err != nil {
+		return err
+	}
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("eval %s", strings.Join(strings.Fields(source), " "))
+	env := make([]float64, slotChannels+1)
+	env[slotW], env[slotH], env[slotMax] = float64(pgm.width), float64(pgm.height), float64(pgm.max)
+	for y, row := range pgm.data {
+		for x, value := range row {
+			env[slotX], env[slotY] = float64(x), float64(y)
+			env[slotChannels] = float64(value)
+			program.run(env)
+			row[x] = toLevel(env[slotChannels], pgm.max)
+		}
+	}
+	return nil
+}
+
+// tokenize découpe une expression en nombres, identifiants et opérateurs.
+func tokenize(source string) []string {
+	var tokens []string
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens
+}
+
+// exprParser est un analyseur par descente récursive qui compile une expression en fonction.
+type exprParser struct {
+	tokens []string
+	pos    int
+	slots  map[string]int
+}
+
+// peek renvoie le prochain symbole sans le consommer ("" en fin d'expression).
+func (parser *exprParser) peek() string {
+	if parser.pos < len(parser.tokens) {
+		return parser.tokens[parser.pos]
+	}
+	return ""
+}
+
+// next consomme et renvoie le prochain symbole.
+func (parser *exprParser) next() string {
+	token := parser.peek()
+	parser.pos++
+	return token
+}
+
+// expect consomme le symbole attendu.
+func (parser *exprParser) expect(token string) error {
+	if got := parser.next(); got != token {
+		return fmt.Errorf("%q attendu, %q trouvé", token, got)
+	}
+	return nil
+}
+
+// parse compile l'expression entière.
+func (parser *exprParser) parse() (expr, error) {
+	compiled, err := parser.sum()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return nil, fmt.Errorf("symbole inattendu: %q", parser.peek())
+	}
+	return compiled, nil
+}
+
+// sum analyse une somme ou une différence.
+func (parser *exprParser) sum() (expr, error) {
+	left, err := parser.product()
+	if err != nil {
+		return nil, err
+	}
+	for parser.peek() == "+" || parser.peek() == "-" {
+		operator := parser.next()
+		right, err := parser.product()
+		if err != nil {
+			return nil, err
+		}
+		a, b := left, right
+		if operator == "+" {
+			left = func(env []float64) float64 { return a(env) + b(env) }
+		} else {
+			left = func(env []float64) float64 { return a(env) - b(env) }
+		}
+	}
+	return left, nil
+}
+
+// product analyse un produit, un quotient ou un reste.
+func (parser *exprParser) product() (expr, error) {
+	left, err := parser.unary()
+	if err != nil {
+		return nil, err
+	}
+	for parser.peek() == "*" || parser.peek() == "/" || parser.peek() == "%" {
+		operator := parser.next()
+		right, err := parser.unary()
+		if err != nil {
+			return nil, err
+		}
+		a, b := left, right
+		switch operator {
+		case "*":
+			left = func(env []float64) float64 { return a(env) * b(env) }
+		case "/":
+			left = func(env []float64) float64 { return a(env) / b(env) }
+		default:
+			left = func(env []float64) float64 { return math.Mod(a(env), b(env)) }
+		}
+	}
+	return left, nil
+}
+
+// unary analyse un moins unaire éventuel.
+func (parser *exprParser) unary() (expr, error) {
+	if parser.peek() == "-" {
+		parser.next()
+		operand, err := parser.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(env []float64) float64 { return -operand(env) }, nil
+	}
+	return parser.primary()
+}
+
+// primary analyse un nombre, une variable, un appel de fonction ou une expression entre parenthèses.
+func (parser *exprParser) primary() (expr, error) {
+	token := parser.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("expression incomplète")
+	case token == "(":
+		inner, err := parser.sum()
+		if err != nil {
+			return nil, err
+		}
+		return inner, parser.expect(")")
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.':
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("nombre invalide: %s", token)
+		}
+		return func([]float64) float64 { return value }, nil
+	case parser.peek() == "(":
+		return parser.call(token)
+	}
+
+	slot, ok := parser.slots[token]
+	if !ok {
+		return nil, fmt.Errorf("variable inconnue: %s", token)
+	}
+	return func(env []float64) float64 { return env[slot] }, nil
+}
+
+// exprFunctions associe chaque fonction disponible à son nombre d'arguments.
+var exprFunctions = map[string]int{
+	"min": 2, "max": 2, "pow": 2,
+	"abs": 1, "sqrt": 1, "floor": 1, "sin": 1, "cos": 1,
+}
+
+// call analyse l'appel de la fonction name.
+func (parser *exprParser) call(name string) (expr, error) {
+	arity, ok := exprFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("fonction inconnue: %s", name)
+	}
+	parser.next() // "("
+	args := make([]expr, arity)
+	for i := range args {
+		if i > 0 {
+			if err := parser.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := parser.sum()
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg
+	}
+	if err := parser.expect(")"); err != nil {
+		return nil, err
+	}
+
+	a := args[0]
+	switch name {
+	case "abs":
+		return func(env []float64) float64 { return math.Abs(a(env)) }, nil
+	case "sqrt":
+		return func(env []float64) float64 { return math.Sqrt(a(env)) }, nil
+	case "floor":
+		return func(env []float64) float64 { return math.Floor(a(env)) }, nil
+	case "sin":
+		return func(env []float64) float64 { return math.Sin(a(env)) }, nil
+	case "cos":
+		return func(env []float64) float64 { return math.Cos(a(env)) }, nil
+	}
+	b := args[1]
+	switch name {
+	case "min":
+		return func(env []float64) float64 { return math.Min(a(env), b(env)) }, nil
+	case "max":
+		return func(env []float64) float64 { return math.Max(a(env), b(env)) }, nil
+	default:
+		return func(env []float64) float64 { return math.Pow(a(env), b(env)) }, nil
+	}
+}
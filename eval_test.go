@@ -0,0 +1,49 @@
+package netpbm
+
+import "testing"
+
+func TestEval(t *testing.T) {
+	ppm := NewPPM(2, 1, 255)
+	ppm.Set(1, 0, []uint8{10, 20, 30})
+	if err := ppm.Eval("r = 255 - r; g = g * 2 + x; b = max(b, 300)"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ppm.At(1, 0); got[0] != 245 || got[1] != 41 || got[2] != 255 {
+		t.Errorf("pixel %v au lieu de [245 41 255]", got)
+	}
+
+	pgm := NewPGM(3, 1, 10)
+	if err := pgm.Eval("v = w - x"); err != nil {
+		t.Fatal(err)
+	}
+	if got := pgm.data[0]; got[0] != 3 || got[1] != 2 || got[2] != 1 {
+		t.Errorf("niveaux %v au lieu de [3 2 1]", got)
+	}
+}
+
+func TestEvalSyntaxErrorLeavesImage(t *testing.T) {
+	for _, source := range []string{"v = (", "v = inconnu", "x = 1"} {
+		pgm := NewPGM(2, 2, 255)
+		notified := 0
+		pgm.OnChange(func(Rect) { notified++ })
+		if err := pgm.Eval(source); err == nil {
+			t.Errorf("%q: programme accepté", source)
+		}
+		if !pgm.Dirty().Empty() || notified != 0 {
+			t.Errorf("%q: image marquée modifiée", source)
+		}
+	}
+}
+
+func TestConvertPresetEval(t *testing.T) {
+	result, err := ConvertPreset{Format: FormatP2, Eval: "v = max - v"}.Apply(NewPGM(2, 1, 255))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.(*PGM).data[0][0]; got != 255 {
+		t.Errorf("niveau %d au lieu de 255", got)
+	}
+	if _, err := (ConvertPreset{Format: FormatP1, Eval: "v = 0"}).Apply(NewPGM(2, 1, 255)); err == nil {
+		t.Error("programme appliqué à une image PBM")
+	}
+}
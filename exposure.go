@@ -0,0 +1,102 @@
+package netpbm
+
+import "fmt"
+
+// ChannelExposure donne, pour un canal, la part des échantillons écrêtés : à 0 (ombres bouchées)
+// ou à la valeur maximale (hautes lumières brûlées), entre 0 et 1.
+type ChannelExposure struct {
+	Name       string
+	Shadows    float64
+	Highlights float64
+}
+
+// ExposureReport est le résultat d'AnalyzeExposure : un ChannelExposure par canal (rouge, vert et
+// bleu pour une image PPM, gris pour une image PGM).
+type ExposureReport struct {
+	Channels []ChannelExposure
+}
+
+// Warnings renvoie un avertissement par canal dont la part d'échantillons écrêtés d'un côté dépasse
+// limit (0.01 pour 1 %), par exemple à vérifier avant et après Brightness, Gamma ou Normalize.
+func (report ExposureReport) Warnings(limit float64) []string {
+	var warnings []string
+	for _, channel := range report.Channels {
+		if channel.Shadows > limit {
+			warnings = append(warnings, fmt.Sprintf("%s: %.1f %% des échantillons bouchés à 0", channel.Name, 100*channel.Shadows))
+		}
+		if channel.Highlights > limit {
+			warnings = append(warnings, fmt.Sprintf("%s: %.1f %% des échantillons brûlés au maximum", channel.Name, 100*channel.Highlights))
+		}
+	}
+	return warnings
+}
+
+// exposureOf compte les échantillons écrêtés d'un canal à partir de son histogramme.
+func exposureOf(name string, histogram [256]int, maxValue, total int) ChannelExposure {
+	exposure := ChannelExposure{Name: name}
+	if total == 0 {
+		return exposure
+	}
+	highlights := 0
+	for value := min(maxValue, 255); value < 256; value++ {
+		highlights += histogram[value]
+	}
+	exposure.Shadows = float64(histogram[0]) / float64(total)
+	exposure.Highlights = float64(highlights) / float64(total)
+	return exposure
+}
+
+// AnalyzeExposure mesure la part des échantillons écrêtés de chaque canal de l'image PPM.
+func (ppm *PPM) AnalyzeExposure() ExposureReport {
+	histogram := ppm.Histogram()
+	total := ppm.width * ppm.height
+	return ExposureReport{Channels: []ChannelExposure{
+		exposureOf("rouge", histogram[0], ppm.max, total),
+		exposureOf("vert", histogram[1], ppm.max, total),
+		exposureOf("bleu", histogram[2], ppm.max, total),
+	}}
+}
+
+// AnalyzeExposure mesure la part des échantillons écrêtés de l'image PGM.
+func (pgm *PGM) AnalyzeExposure() ExposureReport {
+	return ExposureReport{Channels: []ChannelExposure{
+		exposureOf("gris", pgm.Histogram(), pgm.max, pgm.width*pgm.height),
+	}}
+}
+
+// ClippingOverlay renvoie une copie de l'image PPM dans laquelle les pixels dont un canal au moins
+// est bouché à 0 sont peints avec la couleur shadow, et ceux dont un canal atteint la valeur
+// maximale avec la couleur highlight (qui l'emporte si les deux s'appliquent).
+func (ppm *PPM) ClippingOverlay(shadow, highlight Pixel) *PPM {
+	overlay := ppm.Copy()
+	overlay.meta.record("clipping overlay")
+	for y, row := range ppm.data {
+		for x, value := range row {
+			switch {
+			case int(value[0]) >= ppm.max || int(value[1]) >= ppm.max || int(value[2]) >= ppm.max:
+				overlay.setPixel(x, y, highlight)
+			case value[0] == 0 || value[1] == 0 || value[2] == 0:
+				overlay.setPixel(x, y, shadow)
+			}
+		}
+	}
+	return overlay
+}
+
+// ClippingOverlay renvoie une image PPM en niveaux de gris reprenant l'image PGM, dans laquelle les
+// pixels à 0 sont peints avec la couleur shadow et ceux à la valeur maximale avec highlight.
+func (pgm *PGM) ClippingOverlay(shadow, highlight Pixel) *PPM {
+	overlay, _ := convertToPPM(pgm, ConvertOptions{})
+	overlay.meta = pgm.meta.derive("clipping overlay")
+	for y, row := range pgm.data {
+		for x, value := range row {
+			switch {
+			case int(value) >= pgm.max:
+				overlay.setPixel(x, y, highlight)
+			case value == 0:
+				overlay.setPixel(x, y, shadow)
+			}
+		}
+	}
+	return overlay
+}
@@ -0,0 +1,82 @@
+package netpbm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// farbfeldMagic commence les fichiers farbfeld.
+const farbfeldMagic = "farbfeld"
+
+// Le format farbfeld (https://tools.suckless.org/farbfeld/) est l'un des plus simples qui soient :
+// "farbfeld", la largeur et la hauteur en entiers de 32 bits gros-boutistes, puis les pixels ligne
+// par ligne, quatre composantes RGBA de 16 bits gros-boutistes chacun.
+
+// DecodeFarbfeld lit une image farbfeld et renvoie une image PPM de valeur maximale 255. La
+// transparence, que le format PPM ne sait pas représenter, est ignorée.
+func DecodeFarbfeld(r io.Reader) (*PPM, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, len(farbfeldMagic)+8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("en-tête farbfeld illisible: %v", err)
+	}
+	if string(header[:len(farbfeldMagic)]) != farbfeldMagic {
+		return nil, fmt.Errorf("fichier farbfeld invalide")
+	}
+	width := binary.BigEndian.Uint32(header[8:])
+	height := binary.BigEndian.Uint32(header[12:])
+	if width == 0 || height == 0 || uint64(width)*uint64(height) > 1<<31 {
+		return nil, fmt.Errorf("dimensions farbfeld invalides: %dx%d", width, height)
+	}
+
+	ppm := NewPPM(int(width), int(height), 255)
+	line := make([]byte, 8*int(width))
+	for y, row := range ppm.data {
+		if _, err := io.ReadFull(reader, line); err != nil {
+			return nil, fmt.Errorf("ligne %d de l'image: %v", y+1, err)
+		}
+		for x, pixel := range row {
+			for c := 0; c < 3; c++ {
+				// Le poids fort de chaque composante de 16 bits, arrondi.
+				value := int(binary.BigEndian.Uint16(line[8*x+2*c:]))
+				pixel[c] = uint8((value*255 + 32767) / 65535)
+			}
+		}
+	}
+	return ppm, nil
+}
+
+// EncodeFarbfeld écrit l'image au format farbfeld, entièrement opaque. Les valeurs sont étendues
+// sur 16 bits : une image de valeur maximale 255 relue par DecodeFarbfeld est inchangée.
+func EncodeFarbfeld(w io.Writer, img Image) error {
+	if packed, ok := img.(*PackedPBM); ok {
+		img = packed.Unpack()
+	}
+	ppm, err := convertToPPM(img, ConvertOptions{})
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(w)
+	header := make([]byte, len(farbfeldMagic)+8)
+	copy(header, farbfeldMagic)
+	binary.BigEndian.PutUint32(header[8:], uint32(ppm.width))
+	binary.BigEndian.PutUint32(header[12:], uint32(ppm.height))
+	writer.Write(header)
+
+	line := make([]byte, 8*ppm.width)
+	for _, row := range ppm.data {
+		for x, pixel := range row {
+			for c := 0; c < 3; c++ {
+				binary.BigEndian.PutUint16(line[8*x+2*c:], uint16(scaleTo8(pixel[c], ppm.max))*257)
+			}
+			binary.BigEndian.PutUint16(line[8*x+6:], 0xffff)
+		}
+		if _, err := writer.Write(line); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,159 @@
+package netpbm
+
+import "math"
+
+// floatImage est une représentation intermédiaire en virgule flottante utilisée par les filtres :
+// les composantes sont normalisées entre 0 et 1 et rangées ligne par ligne, canal par canal entrelacés.
+type floatImage struct {
+	width, height int
+	channels      int
+	pix           []float64
+}
+
+// newFloatImage crée une image flottante noire.
+func newFloatImage(width, height, channels int) *floatImage {
+	return &floatImage{width: width, height: height, channels: channels, pix: make([]float64, width*height*channels)}
+}
+
+// index renvoie la position de la composante c du pixel (x, y) dans pix.
+func (img *floatImage) index(x, y, c int) int {
+	return (y*img.width+x)*img.channels + c
+}
+
+// at renvoie la composante c du pixel (x, y), en prolongeant les bords de l'image.
+func (img *floatImage) at(x, y, c int) float64 {
+	x = clampInt(x, 0, img.width-1)
+	y = clampInt(y, 0, img.height-1)
+	return img.pix[img.index(x, y, c)]
+}
+
+// bilinear renvoie la composante c interpolée au point (x, y), exprimé en coordonnées de pixels
+// (le centre du pixel (0, 0) est en (0, 0)). Les bords de l'image sont prolongés.
+func (img *floatImage) bilinear(x, y float64, c int) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+
+	top := img.at(ix, iy, c)*(1-fx) + img.at(ix+1, iy, c)*fx
+	bottom := img.at(ix, iy+1, c)*(1-fx) + img.at(ix+1, iy+1, c)*fx
+	return top*(1-fy) + bottom*fy
+}
+
+// contains indique si le point (x, y) se trouve dans l'image (à un demi-pixel près).
+func (img *floatImage) contains(x, y float64) bool {
+	return x >= -0.5 && x < float64(img.width)-0.5 && y >= -0.5 && y < float64(img.height)-0.5
+}
+
+// srgbToLinear convertit une composante sRGB normalisée en lumière linéaire.
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB convertit une composante en lumière linéaire en sRGB normalisé.
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// decodeTable renvoie la table de conversion des valeurs entières 0..max vers [0, 1],
+// en lumière linéaire si linear est vrai.
+func decodeTable(max int, linear bool) []float64 {
+	if max <= 0 {
+		max = 255
+	}
+	table := make([]float64, 256)
+	for v := range table {
+		table[v] = float64(v) / float64(max)
+		if linear {
+			table[v] = srgbToLinear(table[v])
+		}
+	}
+	return table
+}
+
+// encodeValue convertit une composante normalisée en valeur entière 0..max,
+// en repassant en sRGB si linear est vrai.
+func encodeValue(v float64, max int, linear bool) uint8 {
+	v = clampFloat(v, 0, 1)
+	if linear {
+		v = linearToSRGB(v)
+	}
+	return uint8(math.Round(v * float64(max)))
+}
+
+// toFloat convertit l'image PPM en image flottante, en lumière linéaire si linear est vrai.
+func (ppm *PPM) toFloat(linear bool) *floatImage {
+	table := decodeTable(ppm.max, linear)
+	img := newFloatImage(ppm.width, ppm.height, 3)
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			for c := 0; c < 3; c++ {
+				img.pix[img.index(x, y, c)] = table[ppm.data[y][x][c]]
+			}
+		}
+	}
+	return img
+}
+
+// toPPM convertit l'image flottante (3 canaux) en image PPM de valeur maximale max.
+func (img *floatImage) toPPM(max int, linear bool) *PPM {
+	ppm := NewPPM(img.width, img.height, max)
+	for y := 0; y < img.height; y++ {
+		for x := 0; x < img.width; x++ {
+			for c := 0; c < 3; c++ {
+				ppm.data[y][x][c] = encodeValue(img.pix[img.index(x, y, c)], max, linear)
+			}
+		}
+	}
+	return ppm
+}
+
+// toFloat convertit l'image PGM en image flottante à un canal, en lumière linéaire si linear est vrai.
+func (pgm *PGM) toFloat(linear bool) *floatImage {
+	table := decodeTable(pgm.max, linear)
+	img := newFloatImage(pgm.width, pgm.height, 1)
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			img.pix[img.index(x, y, 0)] = table[pgm.data[y][x]]
+		}
+	}
+	return img
+}
+
+// toPGM convertit l'image flottante (1 canal) en image PGM de valeur maximale max.
+func (img *floatImage) toPGM(max int, linear bool) *PGM {
+	pgm := NewPGM(img.width, img.height, max)
+	for y := 0; y < img.height; y++ {
+		for x := 0; x < img.width; x++ {
+			pgm.data[y][x] = encodeValue(img.pix[img.index(x, y, 0)], max, linear)
+		}
+	}
+	return pgm
+}
+
+// clampInt ramène v dans l'intervalle [low, high].
+func clampInt(v, low, high int) int {
+	if v < low {
+		return low
+	}
+	if v > high {
+		return high
+	}
+	return v
+}
+
+// clampFloat ramène v dans l'intervalle [low, high].
+func clampFloat(v, low, high float64) float64 {
+	if v < low {
+		return low
+	}
+	if v > high {
+		return high
+	}
+	return v
+}
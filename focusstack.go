@@ -0,0 +1,68 @@
+package netpbm
+
+import "math"
+
+// focusSigma est le rayon, en pixels, sur lequel StackFocus moyenne la netteté : assez grand pour
+// que les zones unies d'un objet net suivent leurs contours, assez petit pour séparer deux plans.
+const focusSigma = 2.0
+
+// StackFocus combine une série d'images de la même scène mises au point à des distances
+// différentes (« focus stacking ») en une image nette partout : chaque pixel est pris dans l'image
+// où le contraste local, mesuré par l'énergie du laplacien de la luminance, est le plus fort.
+// Les images doivent être alignées au préalable ; le résultat a la valeur maximale de la première.
+func StackFocus(frames []*PPM) (*PPM, error) {
+	if err := checkStack(frames); err != nil {
+		return nil, err
+	}
+	first, scales := frames[0], stackScales(frames)
+
+	best := make([]int, first.width*first.height)
+	bestEnergy := make([]float64, len(best))
+	for i := range bestEnergy {
+		bestEnergy[i] = math.Inf(-1)
+	}
+	for i, frame := range frames {
+		energy := laplacianEnergy(frame).blur(focusSigma)
+		for k, value := range energy.pix {
+			if value > bestEnergy[k] {
+				best[k], bestEnergy[k] = i, value
+			}
+		}
+	}
+
+	result := NewPPM(first.width, first.height, first.max)
+	for y, row := range result.data {
+		for x, pixel := range row {
+			i := best[y*first.width+x]
+			for c := range pixel {
+				value := float64(frames[i].data[y][x][c]) * scales[i]
+				pixel[c] = uint8(clampFloat(math.Round(value), 0, float64(first.max)))
+			}
+		}
+	}
+	result.meta = first.meta.derive("focus stack %d", len(frames))
+	return result, nil
+}
+
+// laplacianEnergy renvoie le carré du laplacien de la luminance de l'image, ramenée entre 0 et 1.
+func laplacianEnergy(ppm *PPM) *floatImage {
+	gray := newFloatImage(ppm.width, ppm.height, 1)
+	scale := 1.0
+	if ppm.max > 0 {
+		scale = 1 / float64(ppm.max)
+	}
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			gray.pix[gray.index(x, y, 0)] = luminance(pixel) * scale
+		}
+	}
+
+	energy := newFloatImage(ppm.width, ppm.height, 1)
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			laplacian := gray.at(x-1, y, 0) + gray.at(x+1, y, 0) + gray.at(x, y-1, 0) + gray.at(x, y+1, 0) - 4*gray.at(x, y, 0)
+			energy.pix[energy.index(x, y, 0)] = laplacian * laplacian
+		}
+	}
+	return energy
+}
@@ -0,0 +1,89 @@
+package netpbm
+
+import "unicode"
+
+// Police bitmap de 3×5 pixels pour les étiquettes et les légendes. Chaque glyphe s'écrit en octal,
+// un chiffre par ligne de haut en bas, dont les trois bits sont les pixels de gauche à droite :
+// 0o25755 est un A (010, 101, 111, 101, 101). Les minuscules s'affichent en capitales et les
+// caractères absents de la police comme un point d'interrogation.
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	glyphAdvance = glyphWidth + 1 // Un pixel d'espace entre deux caractères
+)
+
+var glyphs = map[rune]uint16{
+	' ': 0, '.': 0o00002, ',': 0o00024, ':': 0o02020, ';': 0o02024, '-': 0o00700, '_': 0o00007,
+	'+': 0o02720, '=': 0o07070, '!': 0o22202, '?': 0o71202, '\'': 0o22000, '"': 0o55000,
+	'%': 0o51245, '/': 0o11244, '(': 0o12221, ')': 0o42224, '[': 0o64446, ']': 0o31113,
+	'#': 0o57575, '*': 0o05250, '<': 0o12421, '>': 0o42124, '&': 0o25253, '@': 0o75743,
+	'0': 0o75557, '1': 0o26227, '2': 0o71747, '3': 0o71317, '4': 0o55711,
+	'5': 0o74717, '6': 0o74757, '7': 0o71122, '8': 0o75757, '9': 0o75717,
+	'A': 0o25755, 'B': 0o65656, 'C': 0o34443, 'D': 0o65556, 'E': 0o74647, 'F': 0o74644,
+	'G': 0o34553, 'H': 0o55755, 'I': 0o72227, 'J': 0o11152, 'K': 0o55655, 'L': 0o44447,
+	'M': 0o57755, 'N': 0o57775, 'O': 0o25552, 'P': 0o65644, 'Q': 0o25573, 'R': 0o65655,
+	'S': 0o34216, 'T': 0o72222, 'U': 0o55557, 'V': 0o55552, 'W': 0o55775, 'X': 0o55255,
+	'Y': 0o55222, 'Z': 0o71247,
+}
+
+// glyph renvoie le glyphe du caractère.
+func glyph(c rune) uint16 {
+	if g, ok := glyphs[unicode.ToUpper(c)]; ok {
+		return g
+	}
+	return glyphs['?']
+}
+
+// TextSize renvoie la taille en pixels du texte écrit par DrawText à l'échelle scale.
+func TextSize(text string, scale int) (int, int) {
+	count := 0
+	for range text {
+		count++
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return (count*glyphAdvance - 1) * scale, glyphHeight * scale
+}
+
+// DrawText écrit le texte sur l'image PPM avec la police bitmap de 3×5 pixels, chaque pixel de la
+// police devenant un carré de scale×scale pixels ; p est le coin supérieur gauche du texte. Les
+// pixels hors de l'image sont ignorés.
+func (ppm *PPM) DrawText(p Point, text string, scale int, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	scale = max(scale, 1)
+	x := p.X
+	for _, c := range text {
+		g := glyph(c)
+		for row := 0; row < glyphHeight; row++ {
+			bits := g >> (3 * (glyphHeight - 1 - row)) & 7
+			for column := 0; column < glyphWidth; column++ {
+				if bits&(4>>column) != 0 {
+					ppm.fillRect(Rect{X: x + column*scale, Y: p.Y + row*scale, Width: scale, Height: scale}, color)
+				}
+			}
+		}
+		x += glyphAdvance * scale
+	}
+}
+
+// fillRect remplit un rectangle, en ignorant les pixels hors de l'image ou de la zone de découpe.
+func (ppm *PPM) fillRect(r Rect, color Pixel) {
+	r = r.Intersect(Rect{Width: ppm.width, Height: ppm.height})
+	clip := ppm.clip()
+	if clip != nil {
+		r = r.Intersect(clip.bounds)
+	}
+	if r.Empty() {
+		return
+	}
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		row := ppm.writableRow(y)
+		for x := r.X; x < r.X+r.Width; x++ {
+			if clip == nil || clip.contains(x, y) {
+				row[x][0], row[x][1], row[x][2] = color.Red, color.Green, color.Blue
+			}
+		}
+	}
+	ppm.changes.mark(r)
+}
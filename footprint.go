@@ -0,0 +1,117 @@
+package netpbm
+
+import (
+	"bufio"
+	"unsafe"
+)
+
+// sliceHeaderSize est la taille d'un en-tête de tranche (pointeur, longueur et capacité).
+const sliceHeaderSize = int64(unsafe.Sizeof([]byte(nil)))
+
+// byteCounter est un io.Writer qui ne garde que le nombre d'octets écrits.
+type byteCounter int64
+
+// Write compte les octets de p.
+func (counter *byteCounter) Write(p []byte) (int, error) {
+	*counter += byteCounter(len(p))
+	return len(p), nil
+}
+
+// estimateEncodedSize renvoie la taille, en octets, de l'image écrite par Encode dans le format
+// demandé avec les options par défaut. Les formats binaires se calculent directement ; les
+// formats ASCII, dont la taille dépend du nombre de chiffres de chaque valeur, sont écrits à
+// blanc, ce qui coûte autant qu'une écriture mais sans toucher au disque.
+func estimateEncodedSize(img Image, format Format) (int64, error) {
+	if format == 0 {
+		format = originalFormat(img)
+	}
+	if !format.Raw() {
+		var counter byteCounter
+		err := Encode(&counter, img, EncodeOptions{Format: format})
+		return int64(counter), err
+	}
+
+	// Valeur maximale écrite après une éventuelle conversion (voir ConvertOptions.MaxValue).
+	maxValue := 255
+	switch img := img.(type) {
+	case *PGM:
+		maxValue = img.max
+	case *PPM:
+		maxValue = img.max
+	}
+	var comments []string
+	if meta := metadataOf(img); meta != nil {
+		comments = meta.headerComments(EncodeOptions{})
+	}
+
+	var counter byteCounter
+	header := bufio.NewWriter(&counter)
+	width, height := img.Size()
+	var body int64
+	switch format {
+	case FormatP4:
+		writeHeader(header, format, width, height, 0, comments)
+		body = int64(height) * int64((width+7)/8)
+	case FormatP5:
+		writeHeader(header, format, width, height, maxValue, comments)
+		body = int64(width) * int64(height)
+	default:
+		writeHeader(header, format, width, height, maxValue, comments)
+		body = 3 * int64(width) * int64(height)
+	}
+	header.Flush()
+	return int64(counter) + body, nil
+}
+
+// EstimateEncodedSize renvoie la taille du fichier que produirait l'écriture de l'image PPM dans
+// le format demandé (celui de son nombre magique si format est nul), par Encode avec les options
+// par défaut. Le calcul est immédiat pour P4, P5 et P6 ; pour P1, P2 et P3, il parcourt l'image.
+func (ppm *PPM) EstimateEncodedSize(format Format) (int64, error) {
+	return estimateEncodedSize(ppm, format)
+}
+
+// EstimateEncodedSize renvoie la taille du fichier que produirait l'écriture de l'image PGM dans
+// le format demandé, comme PPM.EstimateEncodedSize.
+func (pgm *PGM) EstimateEncodedSize(format Format) (int64, error) {
+	return estimateEncodedSize(pgm, format)
+}
+
+// EstimateEncodedSize renvoie la taille du fichier que produirait l'écriture de l'image PBM dans
+// le format demandé, comme PPM.EstimateEncodedSize.
+func (pbm *PBM) EstimateEncodedSize(format Format) (int64, error) {
+	return estimateEncodedSize(pbm, format)
+}
+
+// EstimateEncodedSize renvoie la taille du fichier que produirait l'écriture de l'image dans le
+// format demandé (P4 si format est nul), comme PPM.EstimateEncodedSize.
+func (packed *PackedPBM) EstimateEncodedSize(format Format) (int64, error) {
+	return estimateEncodedSize(packed, format)
+}
+
+// MemoryFootprint renvoie la mémoire occupée par les pixels de l'image PPM, en octets : chaque
+// pixel est une tranche de trois octets, soit bien plus que les trois octets eux-mêmes. Les
+// lignes partagées avec une copie (voir Copy) sont comptées pour chacune des images.
+func (ppm *PPM) MemoryFootprint() int64 {
+	pixels := int64(ppm.width) * int64(ppm.height)
+	return int64(unsafe.Sizeof(*ppm)) + int64(len(ppm.data))*sliceHeaderSize + pixels*(sliceHeaderSize+3)
+}
+
+// MemoryFootprint renvoie la mémoire occupée par les pixels de l'image PGM, en octets : un octet
+// par pixel et une tranche par ligne.
+func (pgm *PGM) MemoryFootprint() int64 {
+	pixels := int64(pgm.width) * int64(pgm.height)
+	return int64(unsafe.Sizeof(*pgm)) + int64(len(pgm.data))*sliceHeaderSize + pixels
+}
+
+// MemoryFootprint renvoie la mémoire occupée par les pixels de l'image PBM, en octets : un octet
+// par pixel, comme une image PGM. PackedPBM en occupe huit fois moins.
+func (pbm *PBM) MemoryFootprint() int64 {
+	pixels := int64(pbm.width) * int64(pbm.height)
+	return int64(unsafe.Sizeof(*pbm)) + int64(len(pbm.data))*sliceHeaderSize + pixels
+}
+
+// MemoryFootprint renvoie la mémoire occupée par l'image, en octets : un bit par pixel, les
+// lignes étant complétées à l'octet.
+func (packed *PackedPBM) MemoryFootprint() int64 {
+	return int64(unsafe.Sizeof(*packed)) + int64(len(packed.bits))
+}
@@ -1,3 +1,3 @@
-module github.com
+module github.com/eliiimk/Netpbm
 
 go 1.21.0
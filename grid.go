@@ -0,0 +1,56 @@
+package netpbm
+
+import "strconv"
+
+// DrawGrid trace sur l'image PPM une grille de lignes d'un pixel espacées de spacing pixels, la
+// première ligne de chaque sens passant par le bord haut ou gauche (coordonnée 0).
+func (ppm *PPM) DrawGrid(spacing int, color Pixel) {
+	if spacing < 1 {
+		return
+	}
+	defer ppm.changes.batch(ppm.Size)()
+	for x := 0; x < ppm.width; x += spacing {
+		ppm.fillRect(Rect{X: x, Width: 1, Height: ppm.height}, color)
+	}
+	for y := 0; y < ppm.height; y += spacing {
+		ppm.fillRect(Rect{Y: y, Width: ppm.width, Height: 1}, color)
+	}
+}
+
+// Longueur des graduations de DrawRulers, en pixels.
+const (
+	rulerMinorTick = 3
+	rulerMajorTick = 7
+)
+
+// DrawRulers trace des règles graduées le long des bords haut et gauche de l'image PPM : une
+// graduation tous les tickSpacing pixels, plus longue toutes les cinq graduations et, si labels
+// est vrai, accompagnée de sa coordonnée en pixels.
+func (ppm *PPM) DrawRulers(tickSpacing int, labels bool, color Pixel) {
+	if tickSpacing < 1 {
+		return
+	}
+	defer ppm.changes.batch(ppm.Size)()
+	for i, x := 0, 0; x < ppm.width; i, x = i+1, x+tickSpacing {
+		length := rulerMinorTick
+		if i%5 == 0 {
+			length = rulerMajorTick
+			if labels && x > 0 {
+				label := strconv.Itoa(x)
+				width, _ := TextSize(label, 1)
+				ppm.DrawText(Point{X: x - width/2, Y: rulerMajorTick + 2}, label, 1, color)
+			}
+		}
+		ppm.fillRect(Rect{X: x, Width: 1, Height: length}, color)
+	}
+	for i, y := 0, 0; y < ppm.height; i, y = i+1, y+tickSpacing {
+		length := rulerMinorTick
+		if i%5 == 0 {
+			length = rulerMajorTick
+			if labels && y > 0 {
+				ppm.DrawText(Point{X: rulerMajorTick + 2, Y: y - glyphHeight/2}, strconv.Itoa(y), 1, color)
+			}
+		}
+		ppm.fillRect(Rect{Y: y, Width: length, Height: 1}, color)
+	}
+}
@@ -0,0 +1,62 @@
+package netpbm
+
+import "math"
+
+// DotShape représente la forme des points d'une trame de similigravure.
+type DotShape int
+
+const (
+	DotRound DotShape = iota
+	DotSquare
+	DotEllipse
+	DotLine
+)
+
+// threshold renvoie le seuil d'obscurité (entre 0 et 1) à partir duquel la position (u, v)
+// d'une cellule, exprimée entre -1 et 1, est noircie. Les positions proches du centre noircissent en premier.
+func (shape DotShape) threshold(u, v float64) float64 {
+	switch shape {
+	case DotSquare:
+		m := math.Max(math.Abs(u), math.Abs(v))
+		return m * m
+	case DotEllipse:
+		return (u*u + v*v/0.49) / (1 + 1/0.49)
+	case DotLine:
+		return math.Abs(v)
+	}
+	return (u*u + v*v) / 2
+}
+
+// Halftone convertit l'image PGM en PBM par tramage d'amplitude modulée (AM) : l'image est
+// découpée en cellules de cellSize pixels, inclinées de angle degrés, dans lesquelles un point
+// de la forme donnée grossit avec l'obscurité.
+func (pgm *PGM) Halftone(cellSize float64, angle float64, shape DotShape) *PBM {
+	if cellSize < 1 {
+		cellSize = 1
+	}
+	scale := float64(pgm.max)
+	if scale <= 0 {
+		scale = 255
+	}
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	result := NewPBM(pgm.width, pgm.height)
+	result.meta = pgm.meta.derive("halftone %g %g", cellSize, angle)
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			// Coordonnées du centre du pixel dans le repère tourné de la trame.
+			px, py := float64(x)+0.5, float64(y)+0.5
+			rx := (px*cos + py*sin) / cellSize
+			ry := (-px*sin + py*cos) / cellSize
+
+			// Position dans la cellule, ramenée entre -1 et 1.
+			u := 2*(rx-math.Floor(rx)) - 1
+			v := 2*(ry-math.Floor(ry)) - 1
+
+			darkness := 1 - float64(pgm.data[y][x])/scale
+			result.data[y][x] = darkness > shape.threshold(u, v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,109 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// Heightmap construit un maillage de terrain à partir de l'image PGM : chaque pixel devient un
+// sommet (x, hauteur, y), la hauteur valant niveau/max × scale, et chaque case de 2×2 pixels deux
+// triangles orientés vers le haut (+Y).
+func (pgm *PGM) Heightmap(scale float64) *Model {
+	model := &Model{Vertices: make([]Vec3, 0, pgm.width*pgm.height)}
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			height := float64(pgm.data[y][x]) / float64(pgm.max) * scale
+			model.Vertices = append(model.Vertices, Vec3{float64(x), height, float64(y)})
+		}
+	}
+	for y := 0; y+1 < pgm.height; y++ {
+		for x := 0; x+1 < pgm.width; x++ {
+			a := y*pgm.width + x
+			b, c, d := a+1, a+pgm.width, a+pgm.width+1
+			model.Faces = append(model.Faces, []int{a, c, b}, []int{b, c, d})
+		}
+	}
+	return model
+}
+
+// PointCloud construit un nuage de points à partir d'une carte de profondeur : un sommet
+// (x, y, niveau/max × scale) par pixel, sans face. Les pixels de niveau 0 (pas de mesure) sont ignorés.
+func (pgm *PGM) PointCloud(scale float64) *Model {
+	model := &Model{}
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			if value := pgm.data[y][x]; value > 0 {
+				depth := float64(value) / float64(pgm.max) * scale
+				model.Vertices = append(model.Vertices, Vec3{float64(x), float64(y), depth})
+			}
+		}
+	}
+	return model
+}
+
+// SaveOBJ enregistre le modèle au format Wavefront OBJ.
+func (model *Model) SaveOBJ(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, v := range model.Vertices {
+		fmt.Fprintf(writer, "v %g %g %g\n", v.X, v.Y, v.Z)
+	}
+	for _, face := range model.Faces {
+		writer.WriteString("f")
+		for _, index := range face {
+			fmt.Fprintf(writer, " %d", index+1)
+		}
+		writer.WriteString("\n")
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// SavePLY enregistre le modèle au format PLY ASCII ; un modèle sans face donne un nuage de points.
+func (model *Model) SavePLY(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	fmt.Fprintf(writer, "ply\nformat ascii 1.0\nelement vertex %d\n", len(model.Vertices))
+	writer.WriteString("property float x\nproperty float y\nproperty float z\n")
+	if len(model.Faces) > 0 {
+		fmt.Fprintf(writer, "element face %d\nproperty list uchar int vertex_indices\n", len(model.Faces))
+	}
+	writer.WriteString("end_header\n")
+	for _, v := range model.Vertices {
+		fmt.Fprintf(writer, "%g %g %g\n", v.X, v.Y, v.Z)
+	}
+	for _, face := range model.Faces {
+		fmt.Fprintf(writer, "%d", len(face))
+		for _, index := range face {
+			fmt.Fprintf(writer, " %d", index)
+		}
+		writer.WriteString("\n")
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ExportHeightmapOBJ enregistre l'image PGM, vue comme une carte de hauteurs, en maillage OBJ.
+func (pgm *PGM) ExportHeightmapOBJ(filename string, scale float64) error {
+	return pgm.Heightmap(scale).SaveOBJ(filename)
+}
+
+// ExportHeightmapPLY enregistre l'image PGM, vue comme une carte de hauteurs, en maillage PLY.
+func (pgm *PGM) ExportHeightmapPLY(filename string, scale float64) error {
+	return pgm.Heightmap(scale).SavePLY(filename)
+}
@@ -0,0 +1,96 @@
+package netpbm
+
+// cumulativeHistogram renvoie la fonction de répartition (normalisée entre 0 et 1) d'un canal.
+func cumulativeHistogram(histogram [256]int) [256]float64 {
+	var total int
+	for _, count := range histogram {
+		total += count
+	}
+
+	var cdf [256]float64
+	running := 0
+	for v, count := range histogram {
+		running += count
+		if total > 0 {
+			cdf[v] = float64(running) / float64(total)
+		}
+	}
+	return cdf
+}
+
+// matchingTable renvoie la table qui associe à chaque niveau de la source le premier niveau
+// de la référence dont la fréquence cumulée atteint celle de la source.
+func matchingTable(source, reference [256]int) [256]uint8 {
+	sourceCDF := cumulativeHistogram(source)
+	referenceCDF := cumulativeHistogram(reference)
+
+	var table [256]uint8
+	level := 0
+	for v := 0; v < 256; v++ {
+		for level < 255 && referenceCDF[level] < sourceCDF[v] {
+			level++
+		}
+		table[v] = uint8(level)
+	}
+	return table
+}
+
+// Histogram renvoie l'histogramme de chaque canal (rouge, vert, bleu) de l'image PPM.
+func (ppm *PPM) Histogram() [3][256]int {
+	var histogram [3][256]int
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			for c := 0; c < 3; c++ {
+				histogram[c][ppm.data[y][x][c]]++
+			}
+		}
+	}
+	return histogram
+}
+
+// Histogram renvoie l'histogramme des niveaux de gris de l'image PGM.
+func (pgm *PGM) Histogram() [256]int {
+	var histogram [256]int
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			histogram[pgm.data[y][x]]++
+		}
+	}
+	return histogram
+}
+
+// MatchHistogram modifie chaque canal de l'image PPM pour que sa distribution tonale
+// corresponde à celle de l'image de référence. Les deux images doivent avoir la même valeur maximale.
+func (ppm *PPM) MatchHistogram(reference *PPM) {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("match histogram")
+	source := ppm.Histogram()
+	target := reference.Histogram()
+
+	var tables [3][256]uint8
+	for c := 0; c < 3; c++ {
+		tables[c] = matchingTable(source[c], target[c])
+	}
+
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			for c := 0; c < 3; c++ {
+				ppm.data[y][x][c] = tables[c][ppm.data[y][x][c]]
+			}
+		}
+	}
+}
+
+// MatchHistogram modifie l'image PGM pour que sa distribution tonale corresponde à celle de la référence.
+func (pgm *PGM) MatchHistogram(reference *PGM) {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("match histogram")
+	table := matchingTable(pgm.Histogram(), reference.Histogram())
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			pgm.data[y][x] = table[pgm.data[y][x]]
+		}
+	}
+}
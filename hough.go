@@ -0,0 +1,183 @@
+package netpbm
+
+import (
+	"math"
+	"sort"
+)
+
+// HoughLine représente une droite détectée sous forme normale : x·cos(Theta) + y·sin(Theta) = Rho.
+// Theta est exprimé en radians dans [0, π).
+type HoughLine struct {
+	Rho, Theta float64
+	Votes      int
+}
+
+// HoughCircle représente un cercle détecté.
+type HoughCircle struct {
+	Center Point
+	Radius int
+	Votes  int
+}
+
+// houghThetaSteps est le nombre d'angles échantillonnés (pas de 1°).
+const houghThetaSteps = 180
+
+// edgePoints renvoie les coordonnées des pixels allumés de l'image PBM.
+func (pbm *PBM) edgePoints() []Point {
+	var points []Point
+	for y := 0; y < pbm.height; y++ {
+		for x := 0; x < pbm.width; x++ {
+			if pbm.data[y][x] {
+				points = append(points, Point{X: x, Y: y})
+			}
+		}
+	}
+	return points
+}
+
+// edgePoints renvoie les coordonnées des pixels de l'image PGM dont la valeur atteint le seuil.
+func (pgm *PGM) edgePoints(level uint8) []Point {
+	var points []Point
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			if pgm.data[y][x] >= level {
+				points = append(points, Point{X: x, Y: y})
+			}
+		}
+	}
+	return points
+}
+
+// HoughLines détecte les droites de la carte de contours PBM.
+// Seules les droites ayant recueilli au moins threshold votes sont renvoyées, par votes décroissants.
+func (pbm *PBM) HoughLines(threshold int) []HoughLine {
+	return houghLines(pbm.edgePoints(), pbm.width, pbm.height, threshold)
+}
+
+// HoughLines détecte les droites de la carte de contours PGM ; un pixel est un contour si sa valeur atteint level.
+func (pgm *PGM) HoughLines(level uint8, threshold int) []HoughLine {
+	return houghLines(pgm.edgePoints(level), pgm.width, pgm.height, threshold)
+}
+
+// HoughCircles détecte les cercles de rayon compris entre minRadius et maxRadius dans la carte de contours PBM.
+func (pbm *PBM) HoughCircles(minRadius, maxRadius, threshold int) []HoughCircle {
+	return houghCircles(pbm.edgePoints(), pbm.width, pbm.height, minRadius, maxRadius, threshold)
+}
+
+// HoughCircles détecte les cercles de la carte de contours PGM ; un pixel est un contour si sa valeur atteint level.
+func (pgm *PGM) HoughCircles(level uint8, minRadius, maxRadius, threshold int) []HoughCircle {
+	return houghCircles(pgm.edgePoints(level), pgm.width, pgm.height, minRadius, maxRadius, threshold)
+}
+
+// houghLines remplit l'accumulateur (rho, theta) et en extrait les maxima locaux.
+func houghLines(points []Point, width, height, threshold int) []HoughLine {
+	maxRho := int(math.Ceil(math.Hypot(float64(width), float64(height))))
+	rhoSteps := 2*maxRho + 1
+
+	cos := make([]float64, houghThetaSteps)
+	sin := make([]float64, houghThetaSteps)
+	for t := 0; t < houghThetaSteps; t++ {
+		sin[t], cos[t] = math.Sincos(float64(t) * math.Pi / houghThetaSteps)
+	}
+
+	accumulator := make([][]int, houghThetaSteps)
+	for t := range accumulator {
+		accumulator[t] = make([]int, rhoSteps)
+	}
+	for _, p := range points {
+		for t := 0; t < houghThetaSteps; t++ {
+			rho := int(math.Round(float64(p.X)*cos[t]+float64(p.Y)*sin[t])) + maxRho
+			accumulator[t][rho]++
+		}
+	}
+
+	var lines []HoughLine
+	for t := 0; t < houghThetaSteps; t++ {
+		for r := 0; r < rhoSteps; r++ {
+			votes := accumulator[t][r]
+			if votes < threshold || !isLocalMaximum2D(accumulator, t, r) {
+				continue
+			}
+			lines = append(lines, HoughLine{
+				Rho:   float64(r - maxRho),
+				Theta: float64(t) * math.Pi / houghThetaSteps,
+				Votes: votes,
+			})
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Votes > lines[j].Votes })
+	return lines
+}
+
+// houghCircles remplit un accumulateur (x, y) par rayon et en extrait les maxima locaux.
+func houghCircles(points []Point, width, height, minRadius, maxRadius, threshold int) []HoughCircle {
+	if minRadius < 1 {
+		minRadius = 1
+	}
+
+	var circles []HoughCircle
+	for radius := minRadius; radius <= maxRadius; radius++ {
+		accumulator := make([][]int, height)
+		for y := range accumulator {
+			accumulator[y] = make([]int, width)
+		}
+
+		// Chaque point vote pour tous les centres situés à la distance radius (un vote par centre).
+		offsets := circleOffsets(radius)
+		for _, p := range points {
+			for _, offset := range offsets {
+				cx, cy := p.X+offset.X, p.Y+offset.Y
+				if cx >= 0 && cx < width && cy >= 0 && cy < height {
+					accumulator[cy][cx]++
+				}
+			}
+		}
+
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				votes := accumulator[y][x]
+				if votes >= threshold && isLocalMaximum2D(accumulator, y, x) {
+					circles = append(circles, HoughCircle{Center: Point{X: x, Y: y}, Radius: radius, Votes: votes})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(circles, func(i, j int) bool { return circles[i].Votes > circles[j].Votes })
+	return circles
+}
+
+// circleOffsets renvoie les décalages distincts des points d'un cercle de rayon donné.
+func circleOffsets(radius int) []Point {
+	seen := make(map[Point]bool)
+	var offsets []Point
+	steps := int(math.Ceil(2 * math.Pi * float64(radius)))
+	for i := 0; i < steps; i++ {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(steps))
+		p := Point{X: int(math.Round(float64(radius) * cos)), Y: int(math.Round(float64(radius) * sin))}
+		if !seen[p] {
+			seen[p] = true
+			offsets = append(offsets, p)
+		}
+	}
+	return offsets
+}
+
+// isLocalMaximum2D indique si la cellule (i, j) est supérieure ou égale à ses 8 voisines,
+// en départageant les égalités au profit de la première cellule rencontrée.
+func isLocalMaximum2D(grid [][]int, i, j int) bool {
+	value := grid[i][j]
+	for di := -1; di <= 1; di++ {
+		for dj := -1; dj <= 1; dj++ {
+			ni, nj := i+di, j+dj
+			if (di == 0 && dj == 0) || ni < 0 || ni >= len(grid) || nj < 0 || nj >= len(grid[ni]) {
+				continue
+			}
+			if grid[ni][nj] > value || (grid[ni][nj] == value && (di < 0 || (di == 0 && dj < 0))) {
+				return false
+			}
+		}
+	}
+	return true
+}
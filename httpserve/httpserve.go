@@ -0,0 +1,252 @@
+// Package httpserve sert par HTTP les images Netpbm d'un répertoire, transformées à la volée
+// selon les paramètres de la requête. Il est séparé du paquet netpbm pour que celui-ci ne dépende
+// pas de net/http.
+package httpserve
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	netpbm "github.com/eliiimk/Netpbm"
+)
+
+// DefaultMaxPixels est le nombre maximal de pixels d'une image redimensionnée quand
+// Server.MaxPixels est nul : 16 mégapixels.
+const DefaultMaxPixels = 1 << 24
+
+// Server est un gestionnaire HTTP qui sert les images Netpbm d'un répertoire en appliquant les
+// transformations demandées dans la requête, par exemple :
+//
+//	/photos/chat.ppm?resize=400x300&format=png&invert=1
+//
+// Paramètres reconnus : resize=LxH, invert=1, flip=1, flop=1, rotate=90|180|270 et
+// format=png|jpeg|p1…p6 (le format d'origine par défaut). Les résultats encodés sont gardés en cache
+// tant que le fichier source n'est pas modifié.
+type Server struct {
+	Root       string // Répertoire servi
+	MaxEntries int    // Nombre maximal de résultats en cache (64 si nul)
+	MaxPixels  int    // Nombre maximal de pixels demandé par resize (DefaultMaxPixels si nul)
+
+	mu    sync.Mutex
+	cache map[string]*cachedImage
+	order []string // Clés du cache, de la plus ancienne à la plus récente
+}
+
+// cachedImage est un résultat encodé conservé en cache.
+type cachedImage struct {
+	contentType string
+	body        []byte
+	modTime     time.Time
+}
+
+// NewServer crée un serveur d'images pour le répertoire root.
+func NewServer(root string) *Server {
+	return &Server{Root: root}
+}
+
+// ServeHTTP répond à une requête en renvoyant l'image transformée.
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "méthode non autorisée", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Nettoyer le chemin pour interdire toute sortie du répertoire servi.
+	name := filepath.Join(server.Root, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+	info, err := os.Stat(name)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	key := name + "?" + r.URL.Query().Encode()
+	if entry := server.lookup(key, info.ModTime()); entry != nil {
+		server.write(w, r, entry)
+		return
+	}
+
+	entry, err := server.render(name, r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entry.modTime = info.ModTime()
+	server.store(key, entry)
+	server.write(w, r, entry)
+}
+
+// write envoie un résultat encodé.
+func (server *Server) write(w http.ResponseWriter, r *http.Request, entry *cachedImage) {
+	w.Header().Set("Content-Type", entry.contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(entry.body)))
+	w.Header().Set("Last-Modified", entry.modTime.UTC().Format(http.TimeFormat))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(entry.body)
+}
+
+// lookup renvoie le résultat en cache s'il est encore à jour.
+func (server *Server) lookup(key string, modTime time.Time) *cachedImage {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	entry, ok := server.cache[key]
+	if !ok || !entry.modTime.Equal(modTime) {
+		return nil
+	}
+	return entry
+}
+
+// store ajoute un résultat au cache en évinçant les plus anciens si nécessaire.
+func (server *Server) store(key string, entry *cachedImage) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	limit := server.MaxEntries
+	if limit <= 0 {
+		limit = 64
+	}
+	if server.cache == nil {
+		server.cache = make(map[string]*cachedImage)
+	}
+	if _, ok := server.cache[key]; !ok {
+		server.order = append(server.order, key)
+	}
+	server.cache[key] = entry
+
+	for len(server.order) > limit {
+		delete(server.cache, server.order[0])
+		server.order = server.order[1:]
+	}
+}
+
+// maxPixels renvoie le nombre maximal de pixels d'une image redimensionnée.
+func (server *Server) maxPixels() int {
+	if server.MaxPixels <= 0 {
+		return DefaultMaxPixels
+	}
+	return server.MaxPixels
+}
+
+// toGray convertit une image PBM en PGM, pour les transformations que PBM ne propose pas.
+func toGray(img netpbm.Image) (*netpbm.PGM, error) {
+	gray, err := netpbm.Convert(img, netpbm.FormatP2, netpbm.ConvertOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return gray.(*netpbm.PGM), nil
+}
+
+// render lit le fichier, applique les transformations et encode le résultat.
+func (server *Server) render(name string, query map[string][]string) (*cachedImage, error) {
+	img, err := netpbm.ReadImage(name)
+	if err != nil {
+		return nil, err
+	}
+	get := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	if size := get("resize"); size != "" {
+		var width, height int
+		if _, err := fmt.Sscanf(size, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+			return nil, fmt.Errorf("taille invalide: %s", size)
+		}
+		// Chaque dimension est bornée avant le produit, qui ne peut ainsi pas déborder.
+		limit := server.maxPixels()
+		if width > limit || height > limit || int64(width)*int64(height) > int64(limit) {
+			return nil, fmt.Errorf("taille trop grande: %s (%d pixels au plus)", size, limit)
+		}
+		switch typed := img.(type) {
+		case *netpbm.PPM:
+			img = typed.Resize(width, height, true)
+		case *netpbm.PGM:
+			img = typed.Resize(width, height, true)
+		case *netpbm.PBM:
+			gray, err := toGray(typed)
+			if err != nil {
+				return nil, err
+			}
+			img = gray.Resize(width, height, false)
+		}
+	}
+
+	editable := img.(interface {
+		Invert()
+		Flip()
+		Flop()
+	})
+	if get("invert") == "1" {
+		editable.Invert()
+	}
+	if get("flip") == "1" {
+		editable.Flip()
+	}
+	if get("flop") == "1" {
+		editable.Flop()
+	}
+
+	if rotate := get("rotate"); rotate != "" {
+		angle, err := strconv.Atoi(rotate)
+		if err != nil || angle%90 != 0 {
+			return nil, fmt.Errorf("rotation invalide: %s", rotate)
+		}
+		rotatable := img.(interface{ Rotate90CW() })
+		for i := 0; i < ((angle/90)%4+4)%4; i++ {
+			rotatable.Rotate90CW()
+		}
+	}
+
+	return encodeForWeb(img, strings.ToLower(get("format")))
+}
+
+// encodeForWeb encode l'image dans le format demandé (png, jpeg ou un format Netpbm).
+func encodeForWeb(img netpbm.Image, format string) (*cachedImage, error) {
+	var buffer bytes.Buffer
+	switch format {
+	case "png":
+		encoder := img.(interface{ EncodePNG(io.Writer) error })
+		err := encoder.EncodePNG(&buffer)
+		return &cachedImage{contentType: "image/png", body: buffer.Bytes()}, err
+	case "jpeg", "jpg":
+		// JPEG n'a pas de variante bitonale : les images PBM sont envoyées en niveaux de gris.
+		if pbm, ok := img.(*netpbm.PBM); ok {
+			gray, err := toGray(pbm)
+			if err != nil {
+				return nil, err
+			}
+			img = gray
+		}
+		encoder := img.(interface {
+			EncodeJPEG(io.Writer, int) error
+		})
+		err := encoder.EncodeJPEG(&buffer, netpbm.DefaultJPEGQuality)
+		return &cachedImage{contentType: "image/jpeg", body: buffer.Bytes()}, err
+	}
+
+	// Un format nul garde celui du nombre magique de l'image.
+	var options netpbm.EncodeOptions
+	if format != "" {
+		target, err := netpbm.ParseFormat(strings.ToUpper(format))
+		if err != nil {
+			return nil, err
+		}
+		options.Format = target
+	}
+	if err := netpbm.Encode(&buffer, img, options); err != nil {
+		return nil, err
+	}
+	return &cachedImage{contentType: "image/x-portable-anymap", body: buffer.Bytes()}, nil
+}
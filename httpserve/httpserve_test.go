@@ -0,0 +1,41 @@
+package httpserve
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	netpbm "github.com/eliiimk/Netpbm"
+)
+
+func TestServeP5(t *testing.T) {
+	root := t.TempDir()
+	pgm := netpbm.NewPGM(4, 2, 255)
+	pgm.Set(1, 0, 10)
+	pgm.SetMagicNumber("P5")
+	if err := pgm.Save(filepath.Join(root, "scan.pgm")); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(root)
+	for _, query := range []string{"", "?format=png", "?resize=8x4&invert=1"} {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/scan.pgm"+query, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%q: statut %d (%s)", query, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+func TestServeRejectsHugeResize(t *testing.T) {
+	root := t.TempDir()
+	if err := netpbm.NewPGM(2, 2, 255).Save(filepath.Join(root, "a.pgm")); err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	NewServer(root).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a.pgm?resize=100000x100000", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("statut %d au lieu de %d", recorder.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,52 @@
+package netpbm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/png"
+	"os"
+)
+
+// DefaultICOSizes sont les tailles d'icône écrites par ExportICO si aucune n'est donnée.
+var DefaultICOSizes = []int{16, 32, 48}
+
+// ExportICO enregistre l'image PPM dans un fichier .ico (favicon) contenant une icône carrée par
+// taille demandée, entre 1 et 256 pixels. Une image qui n'est pas carrée est d'abord recadrée en
+// son centre, puis chaque icône est réduite en lumière linéaire et encodée en PNG, ce que tous les
+// systèmes et navigateurs actuels acceptent.
+func (ppm *PPM) ExportICO(filename string, sizes []int) error {
+	if len(sizes) == 0 {
+		sizes = DefaultICOSizes
+	}
+	if ppm.width == 0 || ppm.height == 0 {
+		return fmt.Errorf("image vide")
+	}
+	side := min(ppm.width, ppm.height)
+	square := ppm.crop(Rect{X: (ppm.width - side) / 2, Y: (ppm.height - side) / 2, Width: side, Height: side})
+
+	// En-tête, un répertoire de 16 octets par icône, puis les images.
+	var directory, images bytes.Buffer
+	binary.Write(&directory, binary.LittleEndian, [3]uint16{0, 1, uint16(len(sizes))})
+	offset := 6 + 16*len(sizes)
+	for _, size := range sizes {
+		if size < 1 || size > 256 {
+			return fmt.Errorf("taille d'icône invalide: %d (entre 1 et 256)", size)
+		}
+		std, err := toStdImage(square.Resize(size, size, true))
+		if err != nil {
+			return err
+		}
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, std); err != nil {
+			return err
+		}
+		// Une dimension de 256 s'écrit 0.
+		directory.Write([]byte{byte(size), byte(size), 0, 0})
+		binary.Write(&directory, binary.LittleEndian, [2]uint16{1, 32})
+		binary.Write(&directory, binary.LittleEndian, [2]uint32{uint32(encoded.Len()), uint32(offset)})
+		offset += encoded.Len()
+		images.Write(encoded.Bytes())
+	}
+	return os.WriteFile(filename, append(directory.Bytes(), images.Bytes()...), 0644)
+}
@@ -0,0 +1,80 @@
+package netpbm
+
+import (
+	"fmt"
+	"image/jpeg"
+	"io"
+	"os"
+)
+
+// DefaultJPEGQuality est la qualité JPEG utilisée quand aucune n'est précisée (par exemple par le paquet httpserve).
+const DefaultJPEGQuality = 85
+
+// checkJPEGQuality vérifie que la qualité est comprise entre 1 (fichier le plus petit) et 100
+// (meilleure image).
+func checkJPEGQuality(quality int) error {
+	if quality < 1 || quality > 100 {
+		return fmt.Errorf("qualité JPEG invalide: %d (entre 1 et 100)", quality)
+	}
+	return nil
+}
+
+// encodeJPEG écrit l'image au format JPEG avec la qualité donnée. Les valeurs sont ramenées sur
+// 8 bits ; une image PGM reste en niveaux de gris.
+func encodeJPEG(w io.Writer, img Image, quality int) error {
+	if err := checkJPEGQuality(quality); err != nil {
+		return err
+	}
+	std, err := toStdImage(img)
+	if err != nil {
+		return err
+	}
+	return jpeg.Encode(w, std, &jpeg.Options{Quality: quality})
+}
+
+// saveJPEG enregistre l'image dans un fichier JPEG ; une qualité invalide ne crée pas de fichier.
+func saveJPEG(filename string, img Image, quality int) error {
+	if err := checkJPEGQuality(quality); err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := encodeJPEG(file, img, quality); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// EncodeJPEG écrit l'image PPM dans w au format JPEG, avec une qualité de 1 à 100.
+func (ppm *PPM) EncodeJPEG(w io.Writer, quality int) error {
+	return encodeJPEG(w, ppm, quality)
+}
+
+// SaveJPEG enregistre l'image PPM dans un fichier JPEG, avec une qualité de 1 à 100 : vers 75 à 90,
+// les rendus volumineux deviennent légers à publier sans perte visible.
+func (ppm *PPM) SaveJPEG(filename string, quality int) error {
+	return saveJPEG(filename, ppm, quality)
+}
+
+// EncodeJPEG écrit l'image PGM dans w au format JPEG en niveaux de gris, avec une qualité de 1 à 100.
+func (pgm *PGM) EncodeJPEG(w io.Writer, quality int) error {
+	return encodeJPEG(w, pgm, quality)
+}
+
+// SaveJPEG enregistre l'image PGM dans un fichier JPEG en niveaux de gris, avec une qualité de 1 à 100.
+func (pgm *PGM) SaveJPEG(filename string, quality int) error {
+	return saveJPEG(filename, pgm, quality)
+}
+
+// FromJPEG lit une image JPEG et la convertit en image PPM de valeur maximale maxValue (voir
+// FromImage). Une photo en niveaux de gris donne une image PPM dont les trois composantes sont égales.
+func FromJPEG(r io.Reader, maxValue int) (*PPM, error) {
+	img, err := jpeg.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("JPEG illisible: %v", err)
+	}
+	return FromImage(img, maxValue)
+}
@@ -0,0 +1,140 @@
+package netpbm
+
+import "fmt"
+
+// BlendMode représente la manière dont un calque est fusionné avec ceux qui se trouvent en dessous.
+type BlendMode int
+
+const (
+	BlendNormal BlendMode = iota
+	BlendMultiply
+	BlendScreen
+	BlendOverlay
+)
+
+// String renvoie le nom du mode de fusion.
+func (mode BlendMode) String() string {
+	switch mode {
+	case BlendNormal:
+		return "normal"
+	case BlendMultiply:
+		return "multiply"
+	case BlendScreen:
+		return "screen"
+	case BlendOverlay:
+		return "overlay"
+	}
+	return fmt.Sprintf("BlendMode(%d)", int(mode))
+}
+
+// blend applique le mode de fusion à deux composantes normalisées entre 0 et 1.
+func (mode BlendMode) blend(base, top float64) float64 {
+	switch mode {
+	case BlendMultiply:
+		return base * top
+	case BlendScreen:
+		return 1 - (1-base)*(1-top)
+	case BlendOverlay:
+		if base < 0.5 {
+			return 2 * base * top
+		}
+		return 1 - 2*(1-base)*(1-top)
+	}
+	return top
+}
+
+// Layer représente un calque : une image, son opacité (entre 0 et 1) et son mode de fusion.
+type Layer struct {
+	Image   *PPM
+	Opacity float64
+	Mode    BlendMode
+	Hidden  bool
+}
+
+// Layers représente une pile de calques composée de bas en haut.
+// Si LinearLight est vrai, la fusion est calculée en lumière linéaire plutôt que sur les valeurs sRGB.
+type Layers struct {
+	LinearLight bool
+
+	width, height int
+	max           int
+	layers        []*Layer
+}
+
+// NewLayers crée une pile de calques vide pour une image finale de la taille donnée.
+func NewLayers(width, height, max int) *Layers {
+	return &Layers{width: width, height: height, max: max}
+}
+
+// Add ajoute un calque au sommet de la pile et le renvoie pour permettre de le modifier ensuite.
+func (layers *Layers) Add(image *PPM, opacity float64, mode BlendMode) *Layer {
+	layer := &Layer{Image: image, Opacity: opacity, Mode: mode}
+	layers.layers = append(layers.layers, layer)
+	return layer
+}
+
+// Len renvoie le nombre de calques.
+func (layers *Layers) Len() int {
+	return len(layers.layers)
+}
+
+// Layer renvoie le calque à l'indice donné (0 est le calque du bas).
+func (layers *Layers) Layer(index int) *Layer {
+	return layers.layers[index]
+}
+
+// Remove retire le calque à l'indice donné.
+func (layers *Layers) Remove(index int) {
+	layers.layers = append(layers.layers[:index], layers.layers[index+1:]...)
+}
+
+// Move déplace le calque de l'indice from vers l'indice to.
+func (layers *Layers) Move(from, to int) {
+	layer := layers.layers[from]
+	layers.Remove(from)
+	layers.layers = append(layers.layers[:to], append([]*Layer{layer}, layers.layers[to:]...)...)
+}
+
+// Flatten compose tous les calques visibles sur un fond noir et renvoie l'image PPM obtenue.
+// Les calques ne sont pas modifiés.
+func (layers *Layers) Flatten() *PPM {
+	canvas := make([][][3]float64, layers.height)
+	for i := range canvas {
+		canvas[i] = make([][3]float64, layers.width)
+	}
+
+	for _, layer := range layers.layers {
+		if layer.Hidden || layer.Image == nil || layer.Opacity <= 0 {
+			continue
+		}
+		opacity := layer.Opacity
+		if opacity > 1 {
+			opacity = 1
+		}
+		table := decodeTable(layer.Image.max, layers.LinearLight)
+
+		height := min(layers.height, layer.Image.height)
+		width := min(layers.width, layer.Image.width)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				value := layer.Image.data[y][x]
+				for k := 0; k < 3; k++ {
+					base := canvas[y][x][k]
+					blended := layer.Mode.blend(base, table[value[k]])
+					canvas[y][x][k] = base + (blended-base)*opacity
+				}
+			}
+		}
+	}
+
+	result := NewPPM(layers.width, layers.height, layers.max)
+	for y := 0; y < layers.height; y++ {
+		for x := 0; x < layers.width; x++ {
+			for k := 0; k < 3; k++ {
+				result.data[y][x][k] = encodeValue(canvas[y][x][k], layers.max, layers.LinearLight)
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,107 @@
+package netpbm
+
+import "math"
+
+// normalizedRadius renvoie la distance du point (x, y) au centre de l'image, rapportée à la demi-diagonale.
+func normalizedRadius(x, y float64, width, height int) (dx, dy, r float64) {
+	cx, cy := float64(width-1)/2, float64(height-1)/2
+	halfDiagonal := math.Hypot(float64(width), float64(height)) / 2
+	if halfDiagonal == 0 {
+		return 0, 0, 0
+	}
+	dx, dy = (x-cx)/halfDiagonal, (y-cy)/halfDiagonal
+	return dx, dy, math.Hypot(dx, dy)
+}
+
+// smoothstep renvoie une transition douce de 0 à 1 lorsque v passe de edge0 à edge1.
+func smoothstep(edge0, edge1, v float64) float64 {
+	if edge1 <= edge0 {
+		if v < edge0 {
+			return 0
+		}
+		return 1
+	}
+	t := clampFloat((v-edge0)/(edge1-edge0), 0, 1)
+	return t * t * (3 - 2*t)
+}
+
+// Vignette assombrit progressivement les bords de l'image PPM. radius (entre 0 et 1, rapporté à
+// la demi-diagonale) est la distance au centre à partir de laquelle l'assombrissement commence ;
+// strength (entre 0 et 1) est l'assombrissement atteint dans les coins.
+func (ppm *PPM) Vignette(strength, radius float64) {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("vignette %g %g", strength, radius)
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			_, _, r := normalizedRadius(float64(x), float64(y), ppm.width, ppm.height)
+			factor := 1 - clampFloat(strength, 0, 1)*smoothstep(radius, 1, r)
+			value := []uint8{0, 0, 0}
+			for c := 0; c < 3; c++ {
+				value[c] = uint8(math.Round(float64(ppm.data[y][x][c]) * factor))
+			}
+			ppm.data[y][x] = value
+		}
+	}
+}
+
+// Distort applique une distorsion radiale (modèle de Brown) à l'image PPM : chaque pixel de
+// destination à la distance r du centre est lu à la distance r·(1 + k1·r² + k2·r⁴) par
+// interpolation bilinéaire. Des coefficients positifs donnent une distorsion en barillet,
+// des coefficients négatifs une distorsion en coussinet. Les zones hors de l'image source sont noires.
+func (ppm *PPM) Distort(k1, k2 float64) {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("distort %g %g", k1, k2)
+	source := ppm.toFloat(false)
+	cx, cy := float64(ppm.width-1)/2, float64(ppm.height-1)/2
+	halfDiagonal := math.Hypot(float64(ppm.width), float64(ppm.height)) / 2
+
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			dx, dy, r := normalizedRadius(float64(x), float64(y), ppm.width, ppm.height)
+			scale := 1 + k1*r*r + k2*r*r*r*r
+			sx, sy := cx+dx*scale*halfDiagonal, cy+dy*scale*halfDiagonal
+
+			value := []uint8{0, 0, 0}
+			if source.contains(sx, sy) {
+				for c := 0; c < 3; c++ {
+					value[c] = encodeValue(source.bilinear(sx, sy, c), ppm.max, false)
+				}
+			}
+			ppm.data[y][x] = value
+		}
+	}
+}
+
+// BarrelDistort applique une distorsion en barillet (k1, k2 positifs ou nuls).
+func (ppm *PPM) BarrelDistort(k1, k2 float64) {
+	ppm.Distort(math.Abs(k1), math.Abs(k2))
+}
+
+// PincushionDistort applique une distorsion en coussinet (k1, k2 positifs ou nuls).
+// Elle permet aussi de corriger une distorsion en barillet de l'objectif.
+func (ppm *PPM) PincushionDistort(k1, k2 float64) {
+	ppm.Distort(-math.Abs(k1), -math.Abs(k2))
+}
+
+// ShiftChannels décale le canal rouge de (dRx, dRy) et le canal bleu de (dBx, dBy) pixels par
+// rapport au canal vert, avec interpolation bilinéaire pour les décalages fractionnaires.
+// Cela permet de simuler une aberration chromatique latérale, ou d'en corriger une légère en
+// appliquant les décalages opposés. Les bords de l'image sont prolongés.
+func (ppm *PPM) ShiftChannels(dRx, dRy, dBx, dBy float64) {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("shift channels %g %g %g %g", dRx, dRy, dBx, dBy)
+	source := ppm.toFloat(false)
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			fx, fy := float64(x), float64(y)
+			ppm.data[y][x] = []uint8{
+				encodeValue(source.bilinear(fx-dRx, fy-dRy, 0), ppm.max, false),
+				ppm.data[y][x][1],
+				encodeValue(source.bilinear(fx-dBx, fy-dBy, 2), ppm.max, false),
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// LSystem décrit un système de Lindenmayer : à chaque itération, chaque symbole de la chaîne est
+// remplacé par sa règle (ou gardé s'il n'en a pas), en partant de l'axiome. La chaîne obtenue est
+// ensuite interprétée par une tortue :
+//
+//	F, G  avance d'un pas en traçant
+//	f, g  avance d'un pas sans tracer
+//	+, -  tourne de Angle degrés vers la gauche, vers la droite
+//	|     fait demi-tour
+//	[, ]  mémorise, rétablit la position et la direction (pour les branches)
+//
+// Les autres symboles (X, Y…) ne servent qu'à la réécriture. La tortue part vers le haut.
+type LSystem struct {
+	Axiom      string
+	Rules      map[rune]string
+	Angle      float64 // En degrés
+	Iterations int
+}
+
+// maxLSystemLength est la longueur maximale d'une chaîne développée : la taille croît de façon
+// exponentielle avec le nombre d'itérations.
+const maxLSystemLength = 1 << 24
+
+// Expand renvoie la chaîne obtenue après Iterations réécritures de l'axiome.
+func (ls LSystem) Expand() (string, error) {
+	current := ls.Axiom
+	for i := 0; i < ls.Iterations; i++ {
+		var next strings.Builder
+		for _, symbol := range current {
+			if rule, ok := ls.Rules[symbol]; ok {
+				next.WriteString(rule)
+			} else {
+				next.WriteRune(symbol)
+			}
+			if next.Len() > maxLSystemLength {
+				return "", fmt.Errorf("L-système trop long après %d itérations", i+1)
+			}
+		}
+		current = next.String()
+	}
+	return current, nil
+}
+
+// turtleState est la position et la direction de la tortue.
+type turtleState struct {
+	position Vec2
+	heading  float64 // En radians, dans le sens inverse des aiguilles d'une montre à l'écran
+}
+
+// Path renvoie le dessin de la tortue, avec des pas de longueur 1 à partir de l'origine.
+func (ls LSystem) Path() (*Path, error) {
+	symbols, err := ls.Expand()
+	if err != nil {
+		return nil, err
+	}
+	angle := ls.Angle * math.Pi / 180
+	turtle := turtleState{heading: math.Pi / 2}
+	var stack []turtleState
+
+	path := NewPath()
+	path.MoveTo(0, 0)
+	drawing := true // La position courante du tracé est celle de la tortue
+	for _, symbol := range symbols {
+		switch symbol {
+		case 'F', 'G', 'f', 'g':
+			// L'axe Y de l'image est vers le bas.
+			sin, cos := math.Sincos(turtle.heading)
+			turtle.position.X += cos
+			turtle.position.Y -= sin
+			if symbol == 'F' || symbol == 'G' {
+				if !drawing {
+					path.MoveTo(turtle.position.X-cos, turtle.position.Y+sin)
+					drawing = true
+				}
+				path.LineTo(turtle.position.X, turtle.position.Y)
+			} else {
+				drawing = false
+			}
+		case '+':
+			turtle.heading += angle
+		case '-':
+			turtle.heading -= angle
+		case '|':
+			turtle.heading += math.Pi
+		case '[':
+			stack = append(stack, turtle)
+		case ']':
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("L-système: ']' sans '[' correspondant")
+			}
+			turtle, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			drawing = false
+		}
+	}
+	return path, nil
+}
+
+// DrawLSystem dessine le L-système dans la zone area, agrandi ou réduit pour l'occuper au mieux
+// sans déformation, et centré. Chaque pas est tracé avec DrawLine.
+func (ppm *PPM) DrawLSystem(ls LSystem, area Rect, color Pixel) error {
+	defer ppm.changes.batch(ppm.Size)()
+	path, err := ls.Path()
+	if err != nil {
+		return err
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, sub := range path.subpaths {
+		if len(sub.points) < 2 {
+			continue
+		}
+		for _, p := range sub.points {
+			minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+			minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+		}
+	}
+	if minX > maxX {
+		return nil
+	}
+
+	// Les extrémités des pas tombent sur des pixels de area, d'où les Width-1 et Height-1.
+	scale := math.Inf(1)
+	if maxX > minX {
+		scale = float64(area.Width-1) / (maxX - minX)
+	}
+	if maxY > minY {
+		scale = math.Min(scale, float64(area.Height-1)/(maxY-minY))
+	}
+	if math.IsInf(scale, 1) {
+		scale = 0
+	}
+	offsetX := float64(area.X) + (float64(area.Width-1)-(maxX-minX)*scale)/2 - minX*scale
+	offsetY := float64(area.Y) + (float64(area.Height-1)-(maxY-minY)*scale)/2 - minY*scale
+	toArea := func(p Vec2) Point {
+		return Point{X: int(math.Round(p.X*scale + offsetX)), Y: int(math.Round(p.Y*scale + offsetY))}
+	}
+	for _, sub := range path.subpaths {
+		for i := 1; i < len(sub.points); i++ {
+			ppm.DrawLine(toArea(sub.points[i-1]), toArea(sub.points[i]), color)
+		}
+	}
+	return nil
+}
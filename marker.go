@@ -0,0 +1,79 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// MarkerStyle est la forme d'un repère dessiné par DrawMarker.
+type MarkerStyle int
+
+const (
+	MarkerCrosshair MarkerStyle = iota // Croix dont le centre est laissé libre, pour viser un pixel
+	MarkerPlus                         // Croix droite (+)
+	MarkerX                            // Croix diagonale (×)
+	MarkerCircle                       // Cercle
+)
+
+// String renvoie le nom du style de repère.
+func (style MarkerStyle) String() string {
+	switch style {
+	case MarkerCrosshair:
+		return "viseur"
+	case MarkerPlus:
+		return "plus"
+	case MarkerX:
+		return "croix"
+	case MarkerCircle:
+		return "cercle"
+	}
+	return fmt.Sprintf("MarkerStyle(%d)", int(style))
+}
+
+// DrawMarker dessine un repère centré sur p, de size pixels de demi-largeur, pour signaler un point
+// mesuré ou détecté. Le repère peut déborder de l'image : les pixels hors de l'image sont ignorés.
+func (ppm *PPM) DrawMarker(p Point, style MarkerStyle, size int, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	if ppm.repeatSymmetric(func() { ppm.DrawMarker(p, style, size, color) }) {
+		return
+	}
+	size = max(size, 1)
+	switch style {
+	case MarkerCrosshair:
+		gap := max(size/3, 1)
+		ppm.DrawLine(Point{X: p.X - size, Y: p.Y}, Point{X: p.X - gap, Y: p.Y}, color)
+		ppm.DrawLine(Point{X: p.X + gap, Y: p.Y}, Point{X: p.X + size, Y: p.Y}, color)
+		ppm.DrawLine(Point{X: p.X, Y: p.Y - size}, Point{X: p.X, Y: p.Y - gap}, color)
+		ppm.DrawLine(Point{X: p.X, Y: p.Y + gap}, Point{X: p.X, Y: p.Y + size}, color)
+	case MarkerPlus:
+		ppm.DrawLine(Point{X: p.X - size, Y: p.Y}, Point{X: p.X + size, Y: p.Y}, color)
+		ppm.DrawLine(Point{X: p.X, Y: p.Y - size}, Point{X: p.X, Y: p.Y + size}, color)
+	case MarkerX:
+		ppm.DrawLine(Point{X: p.X - size, Y: p.Y - size}, Point{X: p.X + size, Y: p.Y + size}, color)
+		ppm.DrawLine(Point{X: p.X - size, Y: p.Y + size}, Point{X: p.X + size, Y: p.Y - size}, color)
+	case MarkerCircle:
+		ppm.strokeCircle(p, size, color)
+	}
+}
+
+// arrowHeadAngle est l'angle entre la hampe d'une flèche et chaque trait de sa pointe.
+const arrowHeadAngle = 25 * math.Pi / 180
+
+// DrawArrow dessine une flèche de p1 vers p2, dont la pointe, en p2, a des traits de headSize pixels.
+func (ppm *PPM) DrawArrow(p1, p2 Point, headSize int, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.DrawLine(p1, p2, color)
+	if p1 == p2 || headSize <= 0 {
+		return
+	}
+	// Direction de p2 vers p1, tournée de part et d'autre de la hampe.
+	back := math.Atan2(float64(p1.Y-p2.Y), float64(p1.X-p2.X))
+	for _, side := range []float64{-1, 1} {
+		angle := back + side*arrowHeadAngle
+		tip := Point{
+			X: p2.X + int(math.Round(float64(headSize)*math.Cos(angle))),
+			Y: p2.Y + int(math.Round(float64(headSize)*math.Sin(angle))),
+		}
+		ppm.DrawLine(p2, tip, color)
+	}
+}
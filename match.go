@@ -0,0 +1,89 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// TemplateMatch contient le résultat d'une recherche de motif.
+// Scores[y][x] est la corrélation croisée normalisée (entre -1 et 1) du motif placé
+// avec son coin supérieur gauche en (x, y).
+type TemplateMatch struct {
+	Scores    [][]float64
+	Best      Point
+	BestScore float64
+}
+
+// MatchTemplate recherche le motif dans l'image PGM par corrélation croisée normalisée
+// et renvoie la carte des scores ainsi que la meilleure position.
+func (pgm *PGM) MatchTemplate(template *PGM) (*TemplateMatch, error) {
+	if template.width == 0 || template.height == 0 {
+		return nil, fmt.Errorf("motif vide")
+	}
+	if template.width > pgm.width || template.height > pgm.height {
+		return nil, fmt.Errorf("le motif (%dx%d) est plus grand que l'image (%dx%d)", template.width, template.height, pgm.width, pgm.height)
+	}
+
+	// Pré-calculer le motif centré et sa norme.
+	n := float64(template.width * template.height)
+	var templateMean float64
+	for _, row := range template.data {
+		for _, value := range row {
+			templateMean += float64(value)
+		}
+	}
+	templateMean /= n
+
+	centered := make([][]float64, template.height)
+	var templateNorm float64
+	for i, row := range template.data {
+		centered[i] = make([]float64, template.width)
+		for j, value := range row {
+			centered[i][j] = float64(value) - templateMean
+			templateNorm += centered[i][j] * centered[i][j]
+		}
+	}
+	templateNorm = math.Sqrt(templateNorm)
+
+	result := &TemplateMatch{
+		Scores:    make([][]float64, pgm.height-template.height+1),
+		BestScore: math.Inf(-1),
+	}
+
+	for y := range result.Scores {
+		result.Scores[y] = make([]float64, pgm.width-template.width+1)
+		for x := range result.Scores[y] {
+			// Moyenne de la fenêtre de l'image sous le motif.
+			var windowMean float64
+			for i := 0; i < template.height; i++ {
+				for _, value := range pgm.data[y+i][x : x+template.width] {
+					windowMean += float64(value)
+				}
+			}
+			windowMean /= n
+
+			var dot, windowNorm float64
+			for i := 0; i < template.height; i++ {
+				for j, value := range pgm.data[y+i][x : x+template.width] {
+					v := float64(value) - windowMean
+					dot += v * centered[i][j]
+					windowNorm += v * v
+				}
+			}
+
+			// Une zone (ou un motif) uniforme n'a pas de corrélation définie : on lui attribue 0.
+			score := 0.0
+			if denominator := math.Sqrt(windowNorm) * templateNorm; denominator > 0 {
+				score = dot / denominator
+			}
+			result.Scores[y][x] = score
+
+			if score > result.BestScore {
+				result.BestScore = score
+				result.Best = Point{X: x, Y: y}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,165 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// Vec2 représente un point du plan à coordonnées réelles.
+type Vec2 struct {
+	X, Y float64
+}
+
+// WarpGrid représente une grille de contrôle de (Cols+1)×(Rows+1) nœuds posée sur une image.
+// Déplacer les nœuds déforme les cellules, et l'image avec elles.
+type WarpGrid struct {
+	Cols, Rows int
+	nodes      []Vec2
+}
+
+// NewWarpGrid crée une grille régulière de cols×rows cellules couvrant une image width×height.
+func NewWarpGrid(width, height, cols, rows int) *WarpGrid {
+	cols, rows = max(cols, 1), max(rows, 1)
+	grid := &WarpGrid{Cols: cols, Rows: rows, nodes: make([]Vec2, (cols+1)*(rows+1))}
+	for j := 0; j <= rows; j++ {
+		for i := 0; i <= cols; i++ {
+			grid.nodes[j*(cols+1)+i] = Vec2{
+				X: float64(i) * float64(width-1) / float64(cols),
+				Y: float64(j) * float64(height-1) / float64(rows),
+			}
+		}
+	}
+	return grid
+}
+
+// Node renvoie la position du nœud (i, j), i étant la colonne et j la ligne.
+func (grid *WarpGrid) Node(i, j int) Vec2 {
+	return grid.nodes[j*(grid.Cols+1)+i]
+}
+
+// SetNode place le nœud (i, j) à la position donnée.
+func (grid *WarpGrid) SetNode(i, j int, position Vec2) {
+	grid.nodes[j*(grid.Cols+1)+i] = position
+}
+
+// Move déplace le nœud (i, j) de (dx, dy).
+func (grid *WarpGrid) Move(i, j int, dx, dy float64) {
+	node := grid.Node(i, j)
+	grid.SetNode(i, j, Vec2{X: node.X + dx, Y: node.Y + dy})
+}
+
+// Copy renvoie une copie de la grille.
+func (grid *WarpGrid) Copy() *WarpGrid {
+	result := &WarpGrid{Cols: grid.Cols, Rows: grid.Rows, nodes: make([]Vec2, len(grid.nodes))}
+	copy(result.nodes, grid.nodes)
+	return result
+}
+
+// Lerp renvoie la grille intermédiaire entre grid (t = 0) et other (t = 1).
+func (grid *WarpGrid) Lerp(other *WarpGrid, t float64) (*WarpGrid, error) {
+	if grid.Cols != other.Cols || grid.Rows != other.Rows {
+		return nil, fmt.Errorf("les grilles n'ont pas la même taille: %dx%d et %dx%d", grid.Cols, grid.Rows, other.Cols, other.Rows)
+	}
+	result := grid.Copy()
+	for k := range result.nodes {
+		a, b := grid.nodes[k], other.nodes[k]
+		result.nodes[k] = Vec2{X: a.X + (b.X-a.X)*t, Y: a.Y + (b.Y-a.Y)*t}
+	}
+	return result, nil
+}
+
+// triangles renvoie, pour chaque cellule, ses deux triangles sous forme d'indices de nœuds.
+func (grid *WarpGrid) triangles() [][3]int {
+	var result [][3]int
+	for j := 0; j < grid.Rows; j++ {
+		for i := 0; i < grid.Cols; i++ {
+			topLeft := j*(grid.Cols+1) + i
+			topRight := topLeft + 1
+			bottomLeft := topLeft + grid.Cols + 1
+			bottomRight := bottomLeft + 1
+			result = append(result, [3]int{topLeft, topRight, bottomRight}, [3]int{topLeft, bottomRight, bottomLeft})
+		}
+	}
+	return result
+}
+
+// warpMesh remplit result en projetant chaque triangle de dst sur le triangle correspondant de src.
+func warpMesh(source *floatImage, result *floatImage, src, dst *WarpGrid) {
+	for _, tri := range dst.triangles() {
+		d0, d1, d2 := dst.nodes[tri[0]], dst.nodes[tri[1]], dst.nodes[tri[2]]
+		s0, s1, s2 := src.nodes[tri[0]], src.nodes[tri[1]], src.nodes[tri[2]]
+
+		det := (d1.Y-d2.Y)*(d0.X-d2.X) + (d2.X-d1.X)*(d0.Y-d2.Y)
+		if math.Abs(det) < 1e-12 {
+			continue
+		}
+
+		minX := max(int(math.Floor(math.Min(d0.X, math.Min(d1.X, d2.X)))), 0)
+		maxX := min(int(math.Ceil(math.Max(d0.X, math.Max(d1.X, d2.X)))), result.width-1)
+		minY := max(int(math.Floor(math.Min(d0.Y, math.Min(d1.Y, d2.Y)))), 0)
+		maxY := min(int(math.Ceil(math.Max(d0.Y, math.Max(d1.Y, d2.Y)))), result.height-1)
+
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				px, py := float64(x), float64(y)
+				// Coordonnées barycentriques du pixel dans le triangle de destination.
+				w0 := ((d1.Y-d2.Y)*(px-d2.X) + (d2.X-d1.X)*(py-d2.Y)) / det
+				w1 := ((d2.Y-d0.Y)*(px-d2.X) + (d0.X-d2.X)*(py-d2.Y)) / det
+				w2 := 1 - w0 - w1
+				const epsilon = -1e-9
+				if w0 < epsilon || w1 < epsilon || w2 < epsilon {
+					continue
+				}
+
+				sx := w0*s0.X + w1*s1.X + w2*s2.X
+				sy := w0*s0.Y + w1*s1.Y + w2*s2.Y
+				for c := 0; c < result.channels; c++ {
+					result.pix[result.index(x, y, c)] = source.bilinear(sx, sy, c)
+				}
+			}
+		}
+	}
+}
+
+// MeshWarp renvoie une copie de l'image PPM déformée : le contenu situé sous chaque cellule de la
+// grille src est déplacé dans la cellule correspondante de la grille dst (interpolation affine par
+// triangle et échantillonnage bilinéaire). Les zones non couvertes par dst sont noires.
+func (ppm *PPM) MeshWarp(src, dst *WarpGrid) (*PPM, error) {
+	if src.Cols != dst.Cols || src.Rows != dst.Rows {
+		return nil, fmt.Errorf("les grilles n'ont pas la même taille: %dx%d et %dx%d", src.Cols, src.Rows, dst.Cols, dst.Rows)
+	}
+
+	result := newFloatImage(ppm.width, ppm.height, 3)
+	warpMesh(ppm.toFloat(false), result, src, dst)
+
+	image := result.toPPM(ppm.max, false)
+	image.magicNumber = ppm.magicNumber
+	image.meta = ppm.meta.derive("mesh warp %dx%d", src.Cols, src.Rows)
+	return image, nil
+}
+
+// Morph renvoie l'étape t (entre 0 et 1) du morphing de l'image a vers l'image b : les deux images
+// sont déformées vers la grille intermédiaire entre gridA et gridB, puis fondues l'une dans l'autre.
+// Les grilles marquent les points correspondants (yeux, contours…) dans chaque image.
+func Morph(a, b *PPM, gridA, gridB *WarpGrid, t float64) (*PPM, error) {
+	if a.width != b.width || a.height != b.height {
+		return nil, fmt.Errorf("les images n'ont pas la même taille: %dx%d et %dx%d", a.width, a.height, b.width, b.height)
+	}
+	middle, err := gridA.Lerp(gridB, t)
+	if err != nil {
+		return nil, err
+	}
+
+	warpedA := newFloatImage(a.width, a.height, 3)
+	warpMesh(a.toFloat(false), warpedA, gridA, middle)
+	warpedB := newFloatImage(b.width, b.height, 3)
+	warpMesh(b.toFloat(false), warpedB, gridB, middle)
+
+	for k := range warpedA.pix {
+		warpedA.pix[k] = warpedA.pix[k]*(1-t) + warpedB.pix[k]*t
+	}
+
+	result := warpedA.toPPM(a.max, false)
+	result.magicNumber = a.magicNumber
+	return result, nil
+}
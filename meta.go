@@ -0,0 +1,122 @@
+package netpbm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Metadata regroupe les informations d'une image conservées dans les commentaires de son en-tête.
+// Les lecteurs remplissent les métadonnées à partir des commentaires qu'ils reconnaissent ; les
+// images dérivées d'une autre (Copy, Resize, Convert…) en héritent.
+type Metadata struct {
+	// Comments sont les commentaires de l'en-tête lu qui ne sont pas des métadonnées reconnues.
+	Comments []string
+	// Provenance est la liste des opérations appliquées à l'image, de la plus ancienne à la plus
+	// récente, par exemple "resize 800x600". Elle est écrite dans l'en-tête, un commentaire
+	// "# netpbm-go: …" par opération, si EncodeOptions.Provenance est vrai, et relue au chargement.
+	// Toutes les méthodes qui modifient l'image entière ou en dérivent une autre (filtres, retouches,
+	// transformations, conversions…) y ajoutent une ligne. Set et les méthodes de dessin (Draw…,
+	// FillPath, CompositeSprite, Builder) n'en ajoutent pas, pas plus que les masques renvoyés par
+	// SelectRegion, qui partent de métadonnées vides ; PackedPBM n'a pas de métadonnées.
+	Provenance []string
+	// Checksum est l'empreinte SHA-256 des pixels lue dans l'en-tête ("sha256:…"), vérifiée au
+	// chargement ; elle est oubliée dès que l'image est modifiée (voir EncodeOptions.Checksum).
+	Checksum string
+	// Orientation est l'orientation des pixels, numérotée comme celle de l'EXIF (1 à 8, 0 si
+	// inconnue) : elle est écrite dans l'en-tête ("# orientation: 6") et relue au chargement, et
+	// ApplyOrientation l'applique aux pixels.
+	Orientation Orientation
+	// DPI est la résolution d'impression en pixels par pouce (0 si inconnue). Elle est écrite dans
+	// l'en-tête ("# dpi: 300") et relue au chargement ; voir PhysicalSize et UnitsToPixels.
+	DPI float64
+}
+
+// provenancePrefix commence les commentaires de provenance.
+const provenancePrefix = "netpbm-go: "
+
+// record ajoute une opération à la provenance.
+func (meta *Metadata) record(format string, args ...any) {
+	meta.Checksum = ""
+	meta.Provenance = append(meta.Provenance, fmt.Sprintf(format, args...))
+}
+
+// clone renvoie une copie indépendante des métadonnées.
+func (meta Metadata) clone() Metadata {
+	meta.Comments = append([]string(nil), meta.Comments...)
+	meta.Provenance = append([]string(nil), meta.Provenance...)
+	return meta
+}
+
+// derive renvoie les métadonnées d'une image obtenue par l'opération décrite à partir de celle-ci.
+func (meta Metadata) derive(format string, args ...any) Metadata {
+	derived := meta.clone()
+	derived.record(format, args...)
+	return derived
+}
+
+// parseComment range un commentaire d'en-tête, sans le "#" initial, dans les métadonnées.
+func (meta *Metadata) parseComment(comment string) {
+	if orientation, ok := parseOrientation(comment); ok {
+		meta.Orientation = orientation
+		return
+	}
+	if dpi, ok := parseDPI(comment); ok {
+		meta.DPI = dpi
+		return
+	}
+	if strings.HasPrefix(comment, checksumPrefix) {
+		meta.Checksum = comment
+		return
+	}
+	if operation, ok := strings.CutPrefix(comment, provenancePrefix); ok {
+		meta.Provenance = append(meta.Provenance, operation)
+		return
+	}
+	meta.Comments = append(meta.Comments, comment)
+}
+
+// headerComments renvoie les commentaires à écrire dans l'en-tête selon les options.
+func (meta Metadata) headerComments(options EncodeOptions) []string {
+	comments := append([]string(nil), options.Comments...)
+	if meta.Orientation.valid() {
+		comments = append(comments, fmt.Sprintf("%s%d", orientationPrefix, int(meta.Orientation)))
+	}
+	if meta.DPI > 0 {
+		comments = append(comments, dpiPrefix+strconv.FormatFloat(meta.DPI, 'g', -1, 64))
+	}
+	if options.Provenance {
+		for _, operation := range meta.Provenance {
+			comments = append(comments, provenancePrefix+operation)
+		}
+	}
+	return comments
+}
+
+// metadataOf renvoie les métadonnées de l'image, nil pour un type qui n'en a pas.
+func metadataOf(img Image) *Metadata {
+	switch img := img.(type) {
+	case *PPM:
+		return &img.meta
+	case *PGM:
+		return &img.meta
+	case *PBM:
+		return &img.meta
+	}
+	return nil
+}
+
+// Metadata renvoie les métadonnées de l'image PPM, modifiables.
+func (ppm *PPM) Metadata() *Metadata {
+	return &ppm.meta
+}
+
+// Metadata renvoie les métadonnées de l'image PGM, modifiables.
+func (pgm *PGM) Metadata() *Metadata {
+	return &pgm.meta
+}
+
+// Metadata renvoie les métadonnées de l'image PBM, modifiables.
+func (pbm *PBM) Metadata() *Metadata {
+	return &pbm.meta
+}
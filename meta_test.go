@@ -0,0 +1,110 @@
+package netpbm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// lastProvenance renvoie la dernière opération notée dans les métadonnées.
+func lastProvenance(meta Metadata) string {
+	if len(meta.Provenance) == 0 {
+		return ""
+	}
+	return meta.Provenance[len(meta.Provenance)-1]
+}
+
+func TestProvenanceRecorded(t *testing.T) {
+	sample := func() *PPM {
+		ppm := NewPPM(8, 6, 255)
+		ppm.Set(2, 2, []uint8{200, 100, 50})
+		return ppm
+	}
+	gray := func() *PGM {
+		pgm := NewPGM(8, 6, 255)
+		pgm.Set(3, 3, 180)
+		return pgm
+	}
+	identity := NewKernel([][]float64{{1}})
+
+	tests := []struct {
+		want string
+		run  func() Metadata
+	}{
+		{"blend 0.5", func() Metadata { ppm := sample(); ppm.Blend(sample(), 0.5); return ppm.meta }},
+		{"blend 0.5", func() Metadata { pgm := gray(); pgm.Blend(gray(), 0.5); return pgm.meta }},
+		{"calibrate", func() Metadata { pgm := gray(); pgm.Calibrate(NewPGM(8, 6, 255), nil); return pgm.meta }},
+		{"eval r = 255 - r", func() Metadata { ppm := sample(); ppm.Eval("r = 255\n- r"); return ppm.meta }},
+		{"match histogram", func() Metadata { ppm := sample(); ppm.MatchHistogram(sample()); return ppm.meta }},
+		{"match histogram", func() Metadata { pgm := gray(); pgm.MatchHistogram(gray()); return pgm.meta }},
+		{"vignette 0.5 1", func() Metadata { ppm := sample(); ppm.Vignette(0.5, 1); return ppm.meta }},
+		{"distort 0.1 0", func() Metadata { ppm := sample(); ppm.Distort(0.1, 0); return ppm.meta }},
+		{"shift channels 1 0 -1 0", func() Metadata { ppm := sample(); ppm.ShiftChannels(1, 0, -1, 0); return ppm.meta }},
+		{"convolve 1x1", func() Metadata { return sample().Convolve(identity).meta }},
+		{"convolve 1x1", func() Metadata { return gray().Convolve(identity).meta }},
+		{"emboss 45", func() Metadata { return sample().Emboss(45).meta }},
+		{"motion blur 0 3", func() Metadata { return sample().MotionBlur(0, 3).meta }},
+		{"median 1", func() Metadata { return sample().Median(1).meta }},
+		{"median 1", func() Metadata { return gray().Median(1).meta }},
+		{"kuwahara 2", func() Metadata { return sample().Kuwahara(2).meta }},
+		{"oilpaint 1 4", func() Metadata { return sample().OilPaint(1, 4).meta }},
+		{"dither", func() Metadata { return gray().Dither(DitherOptions{}).meta }},
+		{"halftone 4 45", func() Metadata { return gray().Halftone(4, 45, 0).meta }},
+		{"stipple 2 6", func() Metadata { return gray().Stipple(2, 6, 1).meta }},
+		{"sdf 4", func() Metadata { return gray().ToPBM().GenerateSDF(4).meta }},
+		{"convert P1", func() Metadata { return gray().ToPBM().meta }},
+		{"clipping overlay", func() Metadata { return sample().ClippingOverlay(Pixel{}, Pixel{}).meta }},
+		{"clipping overlay", func() Metadata { return gray().ClippingOverlay(Pixel{}, Pixel{}).meta }},
+		{"demosaic RGGB", func() Metadata { m, _ := gray().Demosaic("rggb", 0); return m.meta }},
+		{"warp", func() Metadata {
+			corners := [4]Point{{0, 0}, {7, 0}, {7, 5}, {0, 5}}
+			warped, _ := sample().WarpPerspective(corners, corners)
+			return warped.meta
+		}},
+		{"map quad", func() Metadata {
+			ppm := sample()
+			corners := [4]Point{{0, 0}, {7, 0}, {7, 5}, {0, 5}}
+			ppm.MapQuad(sample(), corners, corners)
+			return ppm.meta
+		}},
+	}
+	for _, test := range tests {
+		if got := lastProvenance(test.run()); got != test.want {
+			t.Errorf("provenance %q au lieu de %q", got, test.want)
+		}
+	}
+}
+
+func TestProvenanceNotRecordedForDrawing(t *testing.T) {
+	ppm := NewPPM(8, 8, 255)
+	ppm.Invert()
+	ppm.DrawLine(Point{0, 0}, Point{7, 7}, Pixel{Red: 255})
+	ppm.DrawFilledCircle(Point{4, 4}, 2, Pixel{Green: 255})
+	ppm.Set(1, 1, []uint8{1, 2, 3})
+	if len(ppm.meta.Provenance) != 1 || ppm.meta.Provenance[0] != "invert" {
+		t.Errorf("provenance %v au lieu de [invert]", ppm.meta.Provenance)
+	}
+}
+
+func TestProvenanceWritten(t *testing.T) {
+	pgm := NewPGM(4, 4, 255)
+	pgm.Invert()
+	resized := pgm.Resize(2, 2, false)
+	var buffer bytes.Buffer
+	if err := Encode(&buffer, resized, EncodeOptions{Provenance: true}); err != nil {
+		t.Fatal(err)
+	}
+	header := buffer.String()
+	for _, line := range []string{"# netpbm-go: invert\n", "# netpbm-go: resize 2x2\n"} {
+		if !strings.Contains(header, line) {
+			t.Errorf("%q absent de l'en-tête:\n%s", line, header)
+		}
+	}
+	decoded, err := DecodePGM(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.meta.Provenance; len(got) != 2 || got[1] != "resize 2x2" {
+		t.Errorf("provenance relue %v", got)
+	}
+}
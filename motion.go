@@ -0,0 +1,114 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// heatColors est l'échelle de fausses couleurs des cartes de mouvement : du noir (immobile) au
+// blanc (écart maximal), en passant par le bleu, le rouge et le jaune.
+var heatColors = []Pixel{
+	{Red: 0, Green: 0, Blue: 0},
+	{Red: 0, Green: 0, Blue: 255},
+	{Red: 255, Green: 0, Blue: 0},
+	{Red: 255, Green: 255, Blue: 0},
+	{Red: 255, Green: 255, Blue: 255},
+}
+
+// MotionHeatmap renvoie l'écart absolu entre deux images successives d'une séquence, pixel par
+// pixel, en fausses couleurs : noir là où rien n'a bougé, bleu, rouge, jaune puis blanc à mesure
+// que l'écart grandit. Les niveaux sont ramenés à la valeur maximale de chaque image avant la
+// comparaison ; le résultat est une image PPM de valeur maximale 255.
+func MotionHeatmap(prev, cur *PGM) (*PPM, error) {
+	if err := checkSameSize(prev, cur); err != nil {
+		return nil, err
+	}
+	difference := NewPGM(cur.width, cur.height, 255)
+	for y, row := range difference.data {
+		for x := range row {
+			row[x] = uint8(math.Round(frameDifference(prev, cur, x, y) * 255))
+		}
+	}
+	difference.meta = cur.meta
+	return difference.colorRamp(heatColors, "motion heatmap"), nil
+}
+
+// checkSameSize vérifie que deux images d'une séquence ont la même taille.
+func checkSameSize(a, b *PGM) error {
+	if a.width != b.width || a.height != b.height {
+		return fmt.Errorf("les images n'ont pas la même taille: %dx%d et %dx%d", a.width, a.height, b.width, b.height)
+	}
+	return nil
+}
+
+// frameDifference renvoie l'écart entre les pixels (x, y) des deux images, entre 0 et 1.
+func frameDifference(prev, cur *PGM, x, y int) float64 {
+	return math.Abs(normalizedLevel(cur, x, y) - normalizedLevel(prev, x, y))
+}
+
+// normalizedLevel renvoie le niveau du pixel (x, y) ramené entre 0 et 1.
+func normalizedLevel(pgm *PGM, x, y int) float64 {
+	if pgm.max <= 0 {
+		return 0
+	}
+	return math.Min(float64(pgm.data[y][x])/float64(pgm.max), 1)
+}
+
+// MotionAccumulator cumule les écarts d'une séquence d'images pour en garder la trace : chaque
+// nouvelle paire d'images ravive les pixels qui bougent, tandis que l'énergie des autres décroît
+// d'un facteur Decay à chaque paire. La carte obtenue montre ainsi le mouvement récent en clair
+// et les traînées plus anciennes en teintes de plus en plus sombres.
+type MotionAccumulator struct {
+	Decay  float64 // Entre 0 (pas de mémoire, comme MotionHeatmap) et 1 (aucun effacement)
+	energy [][]float64
+	width  int
+}
+
+// NewMotionAccumulator crée un accumulateur vide ; decay est ramené entre 0 et 1.
+func NewMotionAccumulator(decay float64) *MotionAccumulator {
+	return &MotionAccumulator{Decay: math.Max(0, math.Min(decay, 1))}
+}
+
+// Add ajoute l'écart entre deux images successives. Toutes les images d'une même accumulation
+// doivent avoir la même taille.
+func (acc *MotionAccumulator) Add(prev, cur *PGM) error {
+	if err := checkSameSize(prev, cur); err != nil {
+		return err
+	}
+	if acc.energy == nil {
+		acc.energy = make([][]float64, cur.height)
+		for y := range acc.energy {
+			acc.energy[y] = make([]float64, cur.width)
+		}
+		acc.width = cur.width
+	} else if acc.width != cur.width || len(acc.energy) != cur.height {
+		return fmt.Errorf("taille %dx%d différente de celle des images déjà accumulées (%dx%d)", cur.width, cur.height, acc.width, len(acc.energy))
+	}
+
+	for y, row := range acc.energy {
+		for x := range row {
+			row[x] = math.Max(row[x]*acc.Decay, frameDifference(prev, cur, x, y))
+		}
+	}
+	return nil
+}
+
+// Reset vide l'accumulateur, qui peut ensuite servir pour des images d'une autre taille.
+func (acc *MotionAccumulator) Reset() {
+	acc.energy = nil
+}
+
+// Heatmap renvoie la carte de mouvement accumulée, avec les couleurs de MotionHeatmap, ou nil
+// si aucune paire d'images n'a encore été ajoutée.
+func (acc *MotionAccumulator) Heatmap() *PPM {
+	if acc.energy == nil {
+		return nil
+	}
+	levels := NewPGM(acc.width, len(acc.energy), 255)
+	for y, row := range acc.energy {
+		for x, value := range row {
+			levels.data[y][x] = uint8(math.Round(value * 255))
+		}
+	}
+	return levels.colorRamp(heatColors, "motion heatmap")
+}
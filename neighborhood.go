@@ -0,0 +1,75 @@
+package netpbm
+
+import "sort"
+
+// forEachNeighbor appelle visit pour chaque pixel de la fenêtre [x0, x1]×[y0, y1] contenue dans l'image.
+func (ppm *PPM) forEachNeighbor(x0, y0, x1, y1 int, visit func(value []uint8)) {
+	for y := max(y0, 0); y <= min(y1, ppm.height-1); y++ {
+		for x := max(x0, 0); x <= min(x1, ppm.width-1); x++ {
+			visit(ppm.data[y][x])
+		}
+	}
+}
+
+// mapNeighborhoods renvoie une nouvelle image dont chaque pixel est calculé par filter à partir
+// de l'image source et de la position du pixel.
+func (ppm *PPM) mapNeighborhoods(filter func(x, y int) []uint8) *PPM {
+	result := NewPPM(ppm.width, ppm.height, ppm.max)
+	result.magicNumber = ppm.magicNumber
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			result.data[y][x] = filter(x, y)
+		}
+	}
+	return result
+}
+
+// Median renvoie une copie de l'image PPM filtrée par un filtre médian (canal par canal) sur une
+// fenêtre carrée de côté 2·radius+1, efficace contre le bruit impulsionnel. Un rayon négatif
+// compte pour 0 (copie de l'image).
+func (ppm *PPM) Median(radius int) *PPM {
+	radius = max(radius, 0)
+	var channels [3][]int
+	result := ppm.mapNeighborhoods(func(x, y int) []uint8 {
+		for c := range channels {
+			channels[c] = channels[c][:0]
+		}
+		ppm.forEachNeighbor(x-radius, y-radius, x+radius, y+radius, func(value []uint8) {
+			for c := range channels {
+				channels[c] = append(channels[c], int(value[c]))
+			}
+		})
+
+		value := make([]uint8, 3)
+		for c := range channels {
+			sort.Ints(channels[c])
+			value[c] = uint8(channels[c][len(channels[c])/2])
+		}
+		return value
+	})
+	result.meta = ppm.meta.derive("median %d", radius)
+	return result
+}
+
+// Median renvoie une copie de l'image PGM filtrée par un filtre médian de rayon radius (0 si négatif).
+func (pgm *PGM) Median(radius int) *PGM {
+	radius = max(radius, 0)
+	result := NewPGM(pgm.width, pgm.height, pgm.max)
+	result.magicNumber = pgm.magicNumber
+	result.meta = pgm.meta.derive("median %d", radius)
+
+	var values []int
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			values = values[:0]
+			for ny := max(y-radius, 0); ny <= min(y+radius, pgm.height-1); ny++ {
+				for nx := max(x-radius, 0); nx <= min(x+radius, pgm.width-1); nx++ {
+					values = append(values, int(pgm.data[ny][nx]))
+				}
+			}
+			sort.Ints(values)
+			result.data[y][x] = uint8(values[len(values)/2])
+		}
+	}
+	return result
+}
@@ -0,0 +1,188 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Vec3 représente un sommet dans l'espace 3D.
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+// Matrix4 représente une matrice de transformation homogène 4x4.
+type Matrix4 [4][4]float64
+
+// Model représente un modèle Wavefront OBJ : ses sommets et ses faces.
+type Model struct {
+	Vertices []Vec3
+	Faces    [][]int // Indices (à partir de 0) des sommets de chaque face
+}
+
+// LoadOBJ lit un fichier Wavefront OBJ et renvoie le modèle correspondant.
+// Seules les lignes "v" (sommets) et "f" (faces) sont prises en compte.
+func LoadOBJ(filename string) (*Model, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	model := &Model{}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "v":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("sommet incomplet à la ligne %d", lineNumber)
+			}
+			var coords [3]float64
+			for i := 0; i < 3; i++ {
+				coords[i], err = strconv.ParseFloat(fields[i+1], 64)
+				if err != nil {
+					return nil, fmt.Errorf("coordonnée invalide à la ligne %d: %v", lineNumber, err)
+				}
+			}
+			model.Vertices = append(model.Vertices, Vec3{coords[0], coords[1], coords[2]})
+		case "f":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("face incomplète à la ligne %d", lineNumber)
+			}
+			face := make([]int, 0, len(fields)-1)
+			for _, field := range fields[1:] {
+				// Les formes "v/vt/vn" sont acceptées : seul l'indice du sommet est conservé.
+				index, err := strconv.Atoi(strings.SplitN(field, "/", 2)[0])
+				if err != nil {
+					return nil, fmt.Errorf("indice de face invalide à la ligne %d: %v", lineNumber, err)
+				}
+				// Les indices négatifs sont relatifs au dernier sommet lu.
+				if index < 0 {
+					index = len(model.Vertices) + index
+				} else {
+					index--
+				}
+				if index < 0 || index >= len(model.Vertices) {
+					return nil, fmt.Errorf("indice de sommet hors limites à la ligne %d", lineNumber)
+				}
+				face = append(face, index)
+			}
+			model.Faces = append(model.Faces, face)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// Identity renvoie la matrice identité.
+func Identity() Matrix4 {
+	return Matrix4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Perspective renvoie une matrice de projection perspective.
+// fov est l'angle de vue vertical en degrés.
+func Perspective(fov, aspect, near, far float64) Matrix4 {
+	f := 1 / math.Tan(fov*math.Pi/360)
+	return Matrix4{
+		{f / aspect, 0, 0, 0},
+		{0, f, 0, 0},
+		{0, 0, (far + near) / (near - far), 2 * far * near / (near - far)},
+		{0, 0, -1, 0},
+	}
+}
+
+// Translation renvoie une matrice de translation.
+func Translation(x, y, z float64) Matrix4 {
+	m := Identity()
+	m[0][3], m[1][3], m[2][3] = x, y, z
+	return m
+}
+
+// RotationY renvoie une matrice de rotation autour de l'axe Y (angle en degrés).
+func RotationY(angle float64) Matrix4 {
+	s, c := math.Sincos(angle * math.Pi / 180)
+	return Matrix4{
+		{c, 0, s, 0},
+		{0, 1, 0, 0},
+		{-s, 0, c, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Mul renvoie le produit des matrices m et n.
+func (m Matrix4) Mul(n Matrix4) Matrix4 {
+	var result Matrix4
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				result[i][j] += m[i][k] * n[k][j]
+			}
+		}
+	}
+	return result
+}
+
+// Project applique la matrice au sommet et renvoie les coordonnées normalisées (après division par w).
+// Le booléen est faux si le sommet se trouve derrière la caméra.
+func (m Matrix4) Project(v Vec3) (Vec3, bool) {
+	x := m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z + m[0][3]
+	y := m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z + m[1][3]
+	z := m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z + m[2][3]
+	w := m[3][0]*v.X + m[3][1]*v.Y + m[3][2]*v.Z + m[3][3]
+	if w <= 0 {
+		return Vec3{}, false
+	}
+	return Vec3{x / w, y / w, z / w}, true
+}
+
+// DrawWireframe dessine les arêtes du modèle dans l'image PPM après projection par la matrice.
+// Les coordonnées normalisées [-1, 1] sont ramenées aux dimensions de l'image.
+func (ppm *PPM) DrawWireframe(model *Model, projection Matrix4, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+
+	points := make([]Point, len(model.Vertices))
+	visible := make([]bool, len(model.Vertices))
+	for i, v := range model.Vertices {
+		p, ok := projection.Project(v)
+		if !ok {
+			continue
+		}
+		points[i] = Point{
+			X: int((p.X + 1) / 2 * float64(ppm.width-1)),
+			Y: int((1 - p.Y) / 2 * float64(ppm.height-1)),
+		}
+		visible[i] = true
+	}
+
+	for _, face := range model.Faces {
+		if len(face) == 3 && visible[face[0]] && visible[face[1]] && visible[face[2]] {
+			ppm.DrawTriangle(points[face[0]], points[face[1]], points[face[2]], color)
+			continue
+		}
+		for i := range face {
+			a, b := face[i], face[(i+1)%len(face)]
+			if visible[a] && visible[b] {
+				ppm.DrawLine(points[a], points[b], color)
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package netpbm
+
+import "math"
+
+// Deskew estime l'inclinaison des lignes de texte de l'image PGM, entre -maxAngle et maxAngle
+// degrés, et renvoie l'image redressée (les coins découverts sont blancs) avec l'angle corrigé.
+// L'angle retenu est celui qui rend le plus contrasté le profil de projection horizontal des
+// pixels sombres : les lignes de texte y forment alors des pics nets.
+func (pgm *PGM) Deskew(maxAngle float64) (*PGM, float64) {
+	var points []Point
+	limit := uint8(pgm.max / 2)
+	for y, row := range pgm.data {
+		for x, value := range row {
+			if value < limit {
+				points = append(points, Point{X: x, Y: y})
+			}
+		}
+	}
+	if len(points) == 0 || maxAngle <= 0 {
+		return pgm.Copy(), 0
+	}
+
+	// Score d'un angle : somme des carrés du profil des points projetés perpendiculairement à
+	// des lignes inclinées de cet angle. Le profil couvre la projection des quatre coins de l'image.
+	var bins []float64
+	width, height := float64(pgm.width-1), float64(pgm.height-1)
+	score := func(angle float64) float64 {
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		low := int(math.Floor(math.Min(0, -width*sin) + math.Min(0, height*cos)))
+		size := int(math.Ceil(width*math.Abs(sin)+height*math.Abs(cos))) + 2
+		if cap(bins) < size {
+			bins = make([]float64, size)
+		}
+		bins = bins[:size]
+		clear(bins)
+		for _, p := range points {
+			bins[int(math.Floor(float64(p.Y)*cos-float64(p.X)*sin))-low]++
+		}
+		total := 0.0
+		for _, count := range bins {
+			total += count * count
+		}
+		return total
+	}
+	// Recherche grossière au demi-degré, puis fine au vingtième de degré.
+	best, bestScore := 0.0, score(0)
+	search := func(low, high, step float64) {
+		for angle := low; angle <= high+step/2; angle += step {
+			if s := score(angle); s > bestScore {
+				best, bestScore = angle, s
+			}
+		}
+	}
+	search(-maxAngle, maxAngle, 0.5)
+	search(best-0.5, best+0.5, 0.05)
+	best = math.Round(best*20) / 20
+	if math.Abs(best) < 0.05 {
+		return pgm.Copy(), 0
+	}
+
+	source := pgm.toFloat(false)
+	result := newFloatImage(pgm.width, pgm.height, 1)
+	sin, cos := math.Sincos(best * math.Pi / 180)
+	cx, cy := float64(pgm.width-1)/2, float64(pgm.height-1)/2
+	for y := 0; y < pgm.height; y++ {
+		for x := 0; x < pgm.width; x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			sx, sy := cx+dx*cos-dy*sin, cy+dx*sin+dy*cos
+			value := 1.0
+			if source.contains(sx, sy) {
+				value = source.bilinear(sx, sy, 0)
+			}
+			result.pix[result.index(x, y, 0)] = value
+		}
+	}
+	deskewed := result.toPGM(pgm.max, false)
+	deskewed.magicNumber = pgm.magicNumber
+	deskewed.meta = pgm.meta.derive("deskew %.2f", best)
+	return deskewed, best
+}
+
+// AdaptiveThreshold convertit l'image PGM en PBM avec un seuil local : un pixel devient noir s'il
+// est plus sombre de offset (0.15 pour 15 %) que la moyenne de son voisinage de rayon radius. Au
+// contraire d'un seuil global, il résiste aux éclairages inégaux et aux ombres d'une numérisation.
+func (pgm *PGM) AdaptiveThreshold(radius int, offset float64) *PBM {
+	// Table des sommes cumulées : la somme d'un rectangle se lit en quatre accès.
+	stride := pgm.width + 1
+	sums := make([]int64, stride*(pgm.height+1))
+	for y, row := range pgm.data {
+		var rowSum int64
+		for x, value := range row {
+			rowSum += int64(value)
+			sums[(y+1)*stride+x+1] = sums[y*stride+x+1] + rowSum
+		}
+	}
+
+	result := NewPBM(pgm.width, pgm.height)
+	for y, row := range pgm.data {
+		y0, y1 := max(y-radius, 0), min(y+radius+1, pgm.height)
+		for x, value := range row {
+			x0, x1 := max(x-radius, 0), min(x+radius+1, pgm.width)
+			sum := sums[y1*stride+x1] - sums[y0*stride+x1] - sums[y1*stride+x0] + sums[y0*stride+x0]
+			count := int64((y1 - y0) * (x1 - x0))
+			result.data[y][x] = float64(value)*float64(count) < float64(sum)*(1-offset)
+		}
+	}
+	result.meta = pgm.meta.derive("threshold %d %g", radius, offset)
+	return result
+}
+
+// Despeckle renvoie une copie de l'image PBM sans ses taches : les groupes de pixels noirs
+// connexes (en 8-connexité) d'au plus maxSize pixels sont effacés.
+func (pbm *PBM) Despeckle(maxSize int) *PBM {
+	result := pbm.Copy()
+	result.own()
+	result.meta.record("despeckle %d", maxSize)
+	visited := make([][]bool, pbm.height)
+	for y := range visited {
+		visited[y] = make([]bool, pbm.width)
+	}
+	var component, stack []Point
+	for y, row := range pbm.data {
+		for x, black := range row {
+			if !black || visited[y][x] {
+				continue
+			}
+			component = component[:0]
+			stack = append(stack[:0], Point{X: x, Y: y})
+			visited[y][x] = true
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				component = append(component, p)
+				for _, n := range mooreNeighbors {
+					nx, ny := p.X+n.X, p.Y+n.Y
+					if pbm.isSet(nx, ny) && !visited[ny][nx] {
+						visited[ny][nx] = true
+						stack = append(stack, Point{X: nx, Y: ny})
+					}
+				}
+			}
+			if len(component) <= maxSize {
+				for _, p := range component {
+					result.data[p.Y][p.X] = false
+				}
+			}
+		}
+	}
+	return result
+}
+
+// OCROptions règle PrepareForOCR. La valeur zéro donne les réglages par défaut, adaptés à une page
+// de texte numérisée à 300 dpi.
+type OCROptions struct {
+	// Weights sont les coefficients de luminance du passage en niveaux de gris (Rec601 si nuls).
+	Weights LumaWeights
+	// MaxSkew est l'inclinaison maximale corrigée, en degrés (5 si nulle, pas de redressement si négative).
+	MaxSkew float64
+	// ThresholdRadius et ThresholdOffset règlent le seuil local (voir AdaptiveThreshold ; 15 et 0.15 si nuls).
+	ThresholdRadius int
+	ThresholdOffset float64
+	// SpeckleSize est la taille des taches effacées, en pixels (4 si nulle, aucune si négative).
+	SpeckleSize int
+	// KeepMargins conserve les marges blanches de la page au lieu de les rogner.
+	KeepMargins bool
+	// Margin est la largeur de la bordure blanche laissée autour du texte après rognage.
+	Margin int
+}
+
+// PrepareForOCR prépare une page numérisée pour la reconnaissance de caractères en un appel : passage
+// en niveaux de gris, redressement, seuil local, nettoyage des taches et rognage des marges.
+func PrepareForOCR(img Image, options OCROptions) (*PBM, error) {
+	if packed, ok := img.(*PackedPBM); ok {
+		img = packed.Unpack()
+	}
+	gray, err := convertToPGM(img, ConvertOptions{Weights: options.Weights})
+	if err != nil {
+		return nil, err
+	}
+
+	maxSkew := options.MaxSkew
+	if maxSkew == 0 {
+		maxSkew = 5
+	}
+	if maxSkew > 0 {
+		gray, _ = gray.Deskew(maxSkew)
+	}
+
+	radius, offset := options.ThresholdRadius, options.ThresholdOffset
+	if radius <= 0 {
+		radius = 15
+	}
+	if offset == 0 {
+		offset = 0.15
+	}
+	page := gray.AdaptiveThreshold(radius, offset)
+
+	speckle := options.SpeckleSize
+	if speckle == 0 {
+		speckle = 4
+	}
+	if speckle > 0 {
+		page = page.Despeckle(speckle)
+	}
+
+	if options.KeepMargins {
+		return page, nil
+	}
+	page = page.Trim()
+	if options.Margin > 0 {
+		framed := NewPBM(page.width+2*options.Margin, page.height+2*options.Margin)
+		for y, row := range page.data {
+			copy(framed.data[y+options.Margin][options.Margin:], row)
+		}
+		framed.meta = page.meta.clone()
+		page = framed
+	}
+	return page, nil
+}
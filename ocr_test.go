@@ -0,0 +1,57 @@
+package netpbm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDeskewDarkCorners(t *testing.T) {
+	// Page large, sombre dans ses quatre coins : les projections extrêmes doivent rester dans le profil.
+	pgm := NewPGM(200, 100, 255)
+	pgm.Invert()
+	for _, corner := range []Point{{0, 0}, {199, 0}, {0, 99}, {199, 99}} {
+		pgm.Set(corner.X, corner.Y, 0)
+	}
+	for _, maxAngle := range []float64{5, 45, 90} {
+		deskewed, _ := pgm.Deskew(maxAngle)
+		if w, h := deskewed.Size(); w != 200 || h != 100 {
+			t.Errorf("angle %g: taille %dx%d au lieu de 200x100", maxAngle, w, h)
+		}
+	}
+
+	// Page entièrement noire : tous les pixels sont projetés.
+	NewPGM(200, 100, 255).Deskew(5)
+}
+
+func TestDeskewRecoversSkew(t *testing.T) {
+	// Lignes de texte simulées, inclinées de 2° : Deskew doit trouver un angle proche.
+	pgm := NewPGM(300, 120, 255)
+	pgm.Invert()
+	slope := math.Tan(2 * math.Pi / 180)
+	for _, line := range []int{30, 60, 90} {
+		for x := 20; x < 280; x++ {
+			pgm.Set(x, line+int(math.Round(float64(x)*slope))-5, 0)
+		}
+	}
+	_, angle := pgm.Deskew(5)
+	if math.Abs(math.Abs(angle)-2) > 0.3 {
+		t.Errorf("angle estimé %g au lieu de ±2", angle)
+	}
+}
+
+func TestPrepareForOCR(t *testing.T) {
+	page := NewPGM(40, 25, 255)
+	page.Invert()
+	for y := 5; y < 20; y++ {
+		for x := 5; x < 35; x += 3 {
+			page.Set(x, y, 0)
+		}
+	}
+	result, err := PrepareForOCR(page, OCROptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, h := result.Size(); w == 0 || h == 0 {
+		t.Errorf("page vide après préparation: %dx%d", w, h)
+	}
+}
@@ -0,0 +1,223 @@
+package netpbm
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Op est une opération sur une image, utilisable dans une recette. Apply peut modifier l'image
+// reçue et renvoyer la même, ou en renvoyer une nouvelle (éventuellement d'un autre type).
+type Op interface {
+	Apply(img Image) (Image, error)
+}
+
+// OpFunc permet d'utiliser une simple fonction comme opération.
+type OpFunc func(img Image) (Image, error)
+
+// Apply appelle la fonction.
+func (f OpFunc) Apply(img Image) (Image, error) {
+	return f(img)
+}
+
+// Params contient les paramètres numériques d'une opération, par nom.
+type Params map[string]float64
+
+// Get renvoie la valeur d'un paramètre, ou fallback s'il est absent.
+func (params Params) Get(name string, fallback float64) float64 {
+	if value, ok := params[name]; ok {
+		return value
+	}
+	return fallback
+}
+
+// OpFactory construit une opération à partir de ses paramètres.
+type OpFactory func(params Params) (Op, error)
+
+var (
+	opsMu sync.RWMutex
+	ops   = make(map[string]OpFactory)
+)
+
+// Register rend une opération disponible sous le nom donné, pour les recettes et les outils en
+// ligne de commande. Elle est destinée à être appelée depuis une fonction init ; enregistrer deux
+// fois le même nom ou une fabrique nil provoque une panique.
+func Register(name string, factory OpFactory) {
+	opsMu.Lock()
+	defer opsMu.Unlock()
+	if factory == nil {
+		panic("netpbm: fabrique nil pour l'opération " + name)
+	}
+	if _, exists := ops[name]; exists {
+		panic("netpbm: opération déjà enregistrée: " + name)
+	}
+	ops[name] = factory
+}
+
+// NewOp construit l'opération enregistrée sous le nom donné.
+func NewOp(name string, params Params) (Op, error) {
+	opsMu.RLock()
+	factory, ok := ops[name]
+	opsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("opération inconnue: %s", name)
+	}
+	op, err := factory(params)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return op, nil
+}
+
+// Ops renvoie les noms des opérations enregistrées, triés.
+func Ops() []string {
+	opsMu.RLock()
+	defer opsMu.RUnlock()
+	names := make([]string, 0, len(ops))
+	for name := range ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Opérations fournies par le paquet :
+//
+//	trim (tolerance), resize (width, height, linear), gamma (value), blur (sigma),
+//	invert, flip, flop et rotate (angle, multiple de 90).
+func init() {
+	Register("trim", trimOp)
+	Register("resize", resizeOp)
+	Register("gamma", gammaOp)
+	Register("blur", blurOp)
+	Register("invert", orientationOp("invert"))
+	Register("flip", orientationOp("flip"))
+	Register("flop", orientationOp("flop"))
+	Register("rotate", rotateOp)
+}
+
+// trimOp retire les bords uniformes de l'image.
+func trimOp(params Params) (Op, error) {
+	tolerance := int(params.Get("tolerance", 0))
+	return OpFunc(func(img Image) (Image, error) {
+		switch img := img.(type) {
+		case *PPM:
+			return img.Trim(tolerance), nil
+		case *PGM:
+			return img.Trim(tolerance), nil
+		case *PBM:
+			return img.Trim(), nil
+		}
+		return nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+	}), nil
+}
+
+// resizeOp redimensionne l'image ; une image PBM est convertie en niveaux de gris.
+func resizeOp(params Params) (Op, error) {
+	width, height := int(params.Get("width", 0)), int(params.Get("height", 0))
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("taille invalide: %dx%d", width, height)
+	}
+	linear := params.Get("linear", 1) != 0
+	return OpFunc(func(img Image) (Image, error) {
+		switch img := img.(type) {
+		case *PPM:
+			return img.Resize(width, height, linear), nil
+		case *PGM:
+			return img.Resize(width, height, linear), nil
+		case *PBM:
+			gray, err := convertToPGM(img, ConvertOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return gray.Resize(width, height, false), nil
+		}
+		return nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+	}), nil
+}
+
+// gammaOp applique une correction gamma ; les images PBM sont laissées telles quelles.
+func gammaOp(params Params) (Op, error) {
+	gamma := params.Get("value", 1)
+	if gamma <= 0 {
+		return nil, fmt.Errorf("gamma invalide: %g", gamma)
+	}
+	return OpFunc(func(img Image) (Image, error) {
+		switch img := img.(type) {
+		case *PPM:
+			return img, img.Gamma(gamma)
+		case *PGM:
+			return img, img.Gamma(gamma)
+		}
+		return img, nil
+	}), nil
+}
+
+// blurOp applique un flou gaussien.
+func blurOp(params Params) (Op, error) {
+	sigma := params.Get("sigma", 1)
+	if sigma <= 0 {
+		return nil, fmt.Errorf("sigma invalide: %g", sigma)
+	}
+	return OpFunc(func(img Image) (Image, error) {
+		switch img := img.(type) {
+		case *PPM:
+			return img.GaussianBlur(sigma, true), nil
+		case *PGM:
+			return img.GaussianBlur(sigma, true), nil
+		}
+		return nil, fmt.Errorf("flou non pris en charge pour %T", img)
+	}), nil
+}
+
+// orientationOp renvoie la fabrique d'une opération sans paramètre commune aux trois types : invert, flip ou flop.
+func orientationOp(name string) OpFactory {
+	return func(params Params) (Op, error) {
+		return OpFunc(func(img Image) (Image, error) {
+			editable, ok := img.(interface {
+				Invert()
+				Flip()
+				Flop()
+			})
+			if !ok {
+				return nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+			}
+			switch name {
+			case "invert":
+				editable.Invert()
+			case "flip":
+				editable.Flip()
+			default:
+				editable.Flop()
+			}
+			return img, nil
+		}), nil
+	}
+}
+
+// rotateOp tourne l'image d'un multiple de 90° dans le sens horaire.
+func rotateOp(params Params) (Op, error) {
+	angle := int(params.Get("angle", 90))
+	if angle%90 != 0 {
+		return nil, fmt.Errorf("rotation invalide: %d", angle)
+	}
+	return OpFunc(func(img Image) (Image, error) {
+		rotatable, ok := img.(interface {
+			Rotate90CW()
+			Rotate180()
+			Rotate90CCW()
+		})
+		if !ok {
+			return nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+		}
+		switch ((angle/90)%4 + 4) % 4 {
+		case 1:
+			rotatable.Rotate90CW()
+		case 2:
+			rotatable.Rotate180()
+		case 3:
+			rotatable.Rotate90CCW()
+		}
+		return img, nil
+	}), nil
+}
@@ -0,0 +1,34 @@
+package netpbm
+
+import "testing"
+
+func TestRotateOpKeepsPBM(t *testing.T) {
+	for _, angle := range []float64{90, 180, 270, -90, 0} {
+		op, err := NewOp("rotate", Params{"angle": angle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pbm := NewPBM(3, 2)
+		pbm.data[0][0] = true
+		img, err := op.Apply(pbm)
+		if err != nil {
+			t.Fatalf("%g°: %v", angle, err)
+		}
+		rotated, ok := img.(*PBM)
+		if !ok {
+			t.Fatalf("%g°: %T au lieu de *PBM", angle, img)
+		}
+
+		// Coin noir attendu après rotation de (0, 0) dans le sens horaire.
+		want := map[float64]Point{90: {1, 0}, 180: {2, 1}, 270: {0, 2}, -90: {0, 2}, 0: {0, 0}}[angle]
+		if !rotated.data[want.Y][want.X] {
+			t.Errorf("%g°: pixel noir absent en %v", angle, want)
+		}
+	}
+}
+
+func TestRotateOpInvalid(t *testing.T) {
+	if _, err := NewOp("rotate", Params{"angle": 45}); err == nil {
+		t.Error("rotation de 45° acceptée")
+	}
+}
@@ -0,0 +1,122 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ThresholdMatrix est une matrice de seuils pour le tramage ordonné, répétée sur toute l'image.
+// Ses valeurs sont des rangs : le pixel de plus petit rang noircit le premier quand le gris fonce.
+// Les rangs vont en général de 0 à lignes × colonnes - 1, mais toute plage d'entiers convient :
+// elle est ramenée à la plage des niveaux de l'image.
+type ThresholdMatrix [][]int
+
+// BayerMatrix renvoie la matrice de Bayer de taille size×size (size puissance de 2), dont les points
+// sont dispersés : les trames sont fines et régulières, adaptées à l'écran.
+func BayerMatrix(size int) (ThresholdMatrix, error) {
+	if size < 1 || size&(size-1) != 0 {
+		return nil, fmt.Errorf("taille de matrice de Bayer invalide: %d (puissance de 2 attendue)", size)
+	}
+	matrix := ThresholdMatrix{{0}}
+	for n := 1; n < size; n *= 2 {
+		// M(2n) = [4M, 4M+2; 4M+3, 4M+1]
+		next := make(ThresholdMatrix, 2*n)
+		for y := range next {
+			next[y] = make([]int, 2*n)
+			for x := range next[y] {
+				quadrant := [2][2]int{{0, 2}, {3, 1}}[y/n][x/n]
+				next[y][x] = 4*matrix[y%n][x%n] + quadrant
+			}
+		}
+		matrix = next
+	}
+	return matrix, nil
+}
+
+// ClusteredDot4 est une matrice 4×4 dont les points grossissent depuis le centre de la cellule :
+// les trames sont plus grossières mais résistent mieux à l'impression et à la photocopie.
+var ClusteredDot4 = ThresholdMatrix{
+	{12, 5, 6, 13},
+	{4, 0, 1, 7},
+	{11, 3, 2, 8},
+	{15, 10, 9, 14},
+}
+
+// ParseThresholdMatrix lit une matrice de seuils en texte : une ligne de la matrice par ligne de
+// texte, entiers séparés par des blancs. Les lignes vides et celles commençant par "#" sont ignorées.
+func ParseThresholdMatrix(r io.Reader) (ThresholdMatrix, error) {
+	var matrix ThresholdMatrix
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		row := make([]int, len(fields))
+		for i, field := range fields {
+			value, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("ligne %d de la matrice: %v", len(matrix)+1, err)
+			}
+			row[i] = value
+		}
+		matrix = append(matrix, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := matrix.validate(); err != nil {
+		return nil, err
+	}
+	return matrix, nil
+}
+
+// validate vérifie que la matrice est rectangulaire et non vide.
+func (matrix ThresholdMatrix) validate() error {
+	if len(matrix) == 0 || len(matrix[0]) == 0 {
+		return fmt.Errorf("matrice de seuils vide")
+	}
+	for i, row := range matrix {
+		if len(row) != len(matrix[0]) {
+			return fmt.Errorf("ligne %d de la matrice: %d valeurs, %d attendues", i+1, len(row), len(matrix[0]))
+		}
+	}
+	return nil
+}
+
+// OrderedDither convertit l'image PGM en PBM par tramage ordonné : chaque pixel est comparé au
+// seuil que lui attribue la matrice, répétée sur toute l'image, et devient noir s'il est plus sombre.
+// Au contraire de Dither, le résultat d'un pixel ne dépend pas de ses voisins.
+func (pgm *PGM) OrderedDither(matrix ThresholdMatrix) (*PBM, error) {
+	if err := matrix.validate(); err != nil {
+		return nil, err
+	}
+	low, high := matrix[0][0], matrix[0][0]
+	for _, row := range matrix {
+		for _, value := range row {
+			low, high = min(low, value), max(high, value)
+		}
+	}
+	// Le rang r donne le seuil (high - r + 0,5) / (high - low + 1) de la valeur maximale : le plus
+	// petit rang a le seuil le plus haut, et noircit donc le premier.
+	thresholds := make([][]float64, len(matrix))
+	for y, row := range matrix {
+		thresholds[y] = make([]float64, len(row))
+		for x, value := range row {
+			thresholds[y][x] = (float64(high-value) + 0.5) / float64(high-low+1) * float64(pgm.max)
+		}
+	}
+
+	result := NewPBM(pgm.width, pgm.height)
+	result.meta = pgm.meta.derive("ordered dither")
+	for y, row := range pgm.data {
+		line := thresholds[y%len(thresholds)]
+		for x, value := range row {
+			result.data[y][x] = float64(value) < line[x%len(line)]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,102 @@
+package netpbm
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Orientation indique comment afficher des pixels enregistrés tels que les a produits le capteur
+// d'un appareil photo, avec la numérotation de l'étiquette Orientation de l'EXIF.
+type Orientation int
+
+const (
+	OrientationUnknown      Orientation = iota // Non précisée
+	OrientationNormal                          // Pixels à afficher tels quels
+	OrientationMirrored                        // Miroir gauche-droite
+	OrientationRotated180                      // Rotation de 180°
+	OrientationFlipped                         // Miroir haut-bas
+	OrientationTransposed                      // Miroir selon la diagonale principale
+	OrientationRotated90CW                     // Rotation de 90° dans le sens des aiguilles d'une montre
+	OrientationTransverse                      // Miroir selon l'anti-diagonale
+	OrientationRotated90CCW                    // Rotation de 90° dans le sens inverse
+)
+
+// orientationPrefix commence les commentaires d'orientation.
+const orientationPrefix = "orientation: "
+
+// valid indique si l'orientation est l'une des huit orientations de l'EXIF.
+func (orientation Orientation) valid() bool {
+	return orientation >= OrientationNormal && orientation <= OrientationRotated90CCW
+}
+
+// parseOrientation reconnaît un commentaire d'orientation.
+func parseOrientation(comment string) (Orientation, bool) {
+	value, ok := strings.CutPrefix(comment, orientationPrefix)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || !Orientation(n).valid() {
+		return 0, false
+	}
+	return Orientation(n), true
+}
+
+// orientable est implémentée par les images auxquelles ApplyOrientation s'applique.
+type orientable interface {
+	Flip()
+	Flop()
+	Rotate90CW()
+}
+
+// applyOrientation applique l'orientation aux pixels de l'image.
+func applyOrientation(img orientable, orientation Orientation) {
+	switch orientation {
+	case OrientationMirrored:
+		img.Flip()
+	case OrientationRotated180:
+		img.Flip()
+		img.Flop()
+	case OrientationFlipped:
+		img.Flop()
+	case OrientationTransposed:
+		img.Rotate90CW()
+		img.Flip()
+	case OrientationRotated90CW:
+		img.Rotate90CW()
+	case OrientationTransverse:
+		img.Rotate90CW()
+		img.Flop()
+	case OrientationRotated90CCW:
+		img.Rotate90CW()
+		img.Flip()
+		img.Flop()
+	}
+}
+
+// ApplyOrientation redresse l'image PPM selon son orientation (voir Metadata.Orientation), qui
+// devient OrientationNormal : les pixels sont ensuite à afficher tels quels.
+func (ppm *PPM) ApplyOrientation() {
+	if ppm.meta.Orientation.valid() {
+		applyOrientation(ppm, ppm.meta.Orientation)
+		ppm.meta.Orientation = OrientationNormal
+	}
+}
+
+// ApplyOrientation redresse l'image PGM selon son orientation (voir Metadata.Orientation), qui
+// devient OrientationNormal : les pixels sont ensuite à afficher tels quels.
+func (pgm *PGM) ApplyOrientation() {
+	if pgm.meta.Orientation.valid() {
+		applyOrientation(pgm, pgm.meta.Orientation)
+		pgm.meta.Orientation = OrientationNormal
+	}
+}
+
+// ApplyOrientation redresse l'image PBM selon son orientation (voir Metadata.Orientation), qui
+// devient OrientationNormal : les pixels sont ensuite à afficher tels quels.
+func (pbm *PBM) ApplyOrientation() {
+	if pbm.meta.Orientation.valid() {
+		applyOrientation(pbm, pbm.meta.Orientation)
+		pbm.meta.Orientation = OrientationNormal
+	}
+}
@@ -0,0 +1,266 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// PackedPBM est une image bitonale stockée comme dans un fichier P4 : 8 pixels par octet, bit de
+// poids fort à gauche, chaque ligne complétée à l'octet (les bits de remplissage restent à 0).
+// Elle occupe huit fois moins de mémoire qu'un PBM et se réoriente sans être dépaquetée, ce qui
+// convient aux grandes numérisations bitonales (télécopies, plans).
+type PackedPBM struct {
+	bits          []byte
+	width, height int
+	stride        int // Octets par ligne
+}
+
+// reversedBits[b] est l'octet b lu à l'envers (bit 0 ↔ bit 7).
+var reversedBits [256]byte
+
+// spreadBits[b] place chaque pixel de l'octet b dans un octet distinct : l'octet c du résultat,
+// compté depuis le poids fort, vaut 0x80 si le pixel c (depuis la gauche) est noir. Sert à la
+// transposition par blocs de 8×8.
+var spreadBits [256]uint64
+
+func init() {
+	for b := 0; b < 256; b++ {
+		for i := 0; i < 8; i++ {
+			if b&(1<<i) != 0 {
+				reversedBits[b] |= 0x80 >> i
+				spreadBits[b] |= 0x80 << (8 * i)
+			}
+		}
+	}
+}
+
+// NewPackedPBM crée une image bitonale compacte blanche, à 1 bit par pixel.
+func NewPackedPBM(width, height int) *PackedPBM {
+	stride := (width + 7) / 8
+	return &PackedPBM{bits: make([]byte, stride*height), width: width, height: height, stride: stride}
+}
+
+// ReadPackedPBM lit un fichier PBM binaire (P4) sans dépaqueter ses pixels.
+func ReadPackedPBM(filename string) (*PackedPBM, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, DefaultDecodeOptions.bufferSize())
+	fields, err := readHeaderFields(reader, 3)
+	if err != nil {
+		return nil, err
+	}
+	if fields[0] != "P4" {
+		return nil, fmt.Errorf("format PBM compact non pris en charge: %s", fields[0])
+	}
+	width, errWidth := strconv.Atoi(fields[1])
+	height, errHeight := strconv.Atoi(fields[2])
+	if errWidth != nil || errHeight != nil || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("dimensions invalides: %s %s", fields[1], fields[2])
+	}
+
+	packed := NewPackedPBM(width, height)
+	if _, err := io.ReadFull(reader, packed.bits); err != nil {
+		return nil, fmt.Errorf("données P4 tronquées: %v", err)
+	}
+	packed.clearPadding()
+	return packed, nil
+}
+
+// Save enregistre l'image au format P4.
+func (packed *PackedPBM) Save(filename string) error {
+	return SaveImage(filename, packed, EncodeOptions{})
+}
+
+// Size renvoie la largeur et la hauteur de l'image.
+func (packed *PackedPBM) Size() (int, int) {
+	return packed.width, packed.height
+}
+
+// row renvoie les octets de la ligne y.
+func (packed *PackedPBM) row(y int) []byte {
+	return packed.bits[y*packed.stride : (y+1)*packed.stride]
+}
+
+// clearPadding remet à 0 les bits de remplissage de fin de ligne.
+func (packed *PackedPBM) clearPadding() {
+	if packed.width%8 == 0 {
+		return
+	}
+	mask := byte(0xFF << (8 - packed.width%8))
+	for y := 0; y < packed.height; y++ {
+		packed.bits[(y+1)*packed.stride-1] &= mask
+	}
+}
+
+// Flip inverse horizontalement l'image : chaque ligne est lue à l'envers octet par octet grâce
+// à une table, puis décalée pour éliminer le remplissage.
+func (packed *PackedPBM) Flip() {
+	padding := uint(packed.stride*8 - packed.width)
+	for y := 0; y < packed.height; y++ {
+		row := packed.row(y)
+		for i, j := 0, len(row)-1; i <= j; i, j = i+1, j-1 {
+			row[i], row[j] = reversedBits[row[j]], reversedBits[row[i]]
+		}
+		if padding == 0 {
+			continue
+		}
+		for i := range row {
+			row[i] <<= padding
+			if i+1 < len(row) {
+				row[i] |= row[i+1] >> (8 - padding)
+			}
+		}
+	}
+}
+
+// Flop inverse verticalement l'image en échangeant ses lignes.
+func (packed *PackedPBM) Flop() {
+	buffer := make([]byte, packed.stride)
+	for i, j := 0, packed.height-1; i < j; i, j = i+1, j-1 {
+		copy(buffer, packed.row(i))
+		copy(packed.row(i), packed.row(j))
+		copy(packed.row(j), buffer)
+	}
+}
+
+// Rotate180 tourne l'image d'un demi-tour.
+func (packed *PackedPBM) Rotate180() {
+	packed.Flip()
+	packed.Flop()
+}
+
+// transpose échange lignes et colonnes par blocs de 8×8 pixels : les 8 octets d'un bloc sont
+// étalés par la table spreadBits puis recombinés, sans jamais manipuler les pixels un à un.
+func (packed *PackedPBM) transpose() *PackedPBM {
+	result := NewPackedPBM(packed.height, packed.width)
+	for by := 0; by < result.stride; by++ {
+		for bx := 0; bx < packed.stride; bx++ {
+			var block uint64
+			for r := 0; r < 8 && by*8+r < packed.height; r++ {
+				block |= spreadBits[packed.bits[(by*8+r)*packed.stride+bx]] >> r
+			}
+			for c := 0; c < 8 && bx*8+c < packed.width; c++ {
+				result.bits[(bx*8+c)*result.stride+by] = byte(block >> (8 * (7 - c)))
+			}
+		}
+	}
+	return result
+}
+
+// Rotate90CW tourne l'image d'un quart de tour dans le sens horaire.
+func (packed *PackedPBM) Rotate90CW() {
+	*packed = *packed.transpose()
+	packed.Flip()
+}
+
+// Rotate90CCW tourne l'image d'un quart de tour dans le sens antihoraire.
+func (packed *PackedPBM) Rotate90CCW() {
+	*packed = *packed.transpose()
+	packed.Flop()
+}
+
+// Bitmap est l'interface commune aux images bitonales, qu'elles soient stockées sous forme de
+// booléens (*PBM) ou compactées (*PackedPBM).
+type Bitmap interface {
+	Size() (int, int)
+	At(x, y int) bool
+	Set(x, y int, value bool)
+}
+
+// At renvoie la valeur du pixel aux coordonnées (x, y).
+func (packed *PackedPBM) At(x, y int) bool {
+	return packed.bits[y*packed.stride+x/8]&(0x80>>(x%8)) != 0
+}
+
+// Set définit la valeur du pixel aux coordonnées (x, y).
+func (packed *PackedPBM) Set(x, y int, value bool) {
+	if value {
+		packed.bits[y*packed.stride+x/8] |= 0x80 >> (x % 8)
+	} else {
+		packed.bits[y*packed.stride+x/8] &^= 0x80 >> (x % 8)
+	}
+}
+
+// Copy renvoie une copie indépendante de l'image.
+func (packed *PackedPBM) Copy() *PackedPBM {
+	result := *packed
+	result.bits = append([]byte(nil), packed.bits...)
+	return &result
+}
+
+// Invert inverse les couleurs de l'image, 8 pixels à la fois.
+func (packed *PackedPBM) Invert() {
+	for i := range packed.bits {
+		packed.bits[i] = ^packed.bits[i]
+	}
+	packed.clearPadding()
+}
+
+// combine applique une opération octet par octet avec une image de même taille.
+func (packed *PackedPBM) combine(other *PackedPBM, operation func(a, b byte) byte) error {
+	if other.width != packed.width || other.height != packed.height {
+		return fmt.Errorf("tailles différentes: %dx%d et %dx%d", packed.width, packed.height, other.width, other.height)
+	}
+	for i := range packed.bits {
+		packed.bits[i] = operation(packed.bits[i], other.bits[i])
+	}
+	return nil
+}
+
+// And ne garde noirs que les pixels noirs dans les deux images (intersection des masques).
+func (packed *PackedPBM) And(other *PackedPBM) error {
+	return packed.combine(other, func(a, b byte) byte { return a & b })
+}
+
+// Or rend noirs les pixels noirs dans l'une ou l'autre image (union des masques).
+func (packed *PackedPBM) Or(other *PackedPBM) error {
+	return packed.combine(other, func(a, b byte) byte { return a | b })
+}
+
+// Xor rend noirs les pixels qui diffèrent entre les deux images.
+func (packed *PackedPBM) Xor(other *PackedPBM) error {
+	return packed.combine(other, func(a, b byte) byte { return a ^ b })
+}
+
+// packRow range la ligne de pixels dans bits, 8 pixels par octet comme en P4, bits de remplissage à 0.
+func packRow(bits []byte, row []bool) {
+	clear(bits)
+	for x, value := range row {
+		if value {
+			bits[x/8] |= 0x80 >> (x % 8)
+		}
+	}
+}
+
+// unpackRow remplit la ligne de pixels à partir de bits, 8 pixels par octet comme en P4.
+func unpackRow(row []bool, bits []byte) {
+	for x := range row {
+		row[x] = bits[x/8]&(0x80>>(x%8)) != 0
+	}
+}
+
+// Pack renvoie une copie compacte de l'image PBM.
+func (pbm *PBM) Pack() *PackedPBM {
+	packed := NewPackedPBM(pbm.width, pbm.height)
+	for y, row := range pbm.data {
+		packRow(packed.row(y), row)
+	}
+	return packed
+}
+
+// Unpack renvoie l'image sous forme de PBM, un booléen par pixel.
+func (packed *PackedPBM) Unpack() *PBM {
+	pbm := NewPBM(packed.width, packed.height)
+	pbm.magicNumber = "P4"
+	for y, row := range pbm.data {
+		unpackRow(row, packed.row(y))
+	}
+	return pbm
+}
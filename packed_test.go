@@ -0,0 +1,110 @@
+package netpbm
+
+import "testing"
+
+// testPBM renvoie une image bitonale irrégulière dont la largeur n'est pas un multiple de 8 :
+// les bits de remplissage de chaque ligne entrent en jeu.
+func testPBM(width, height, seed int) *PBM {
+	pbm := NewPBM(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pbm.Set(x, y, (x*x+3*y+seed)%7 < 3)
+		}
+	}
+	return pbm
+}
+
+func assertSamePBM(t *testing.T, name string, got *PackedPBM, want *PBM) {
+	t.Helper()
+	if got.width != want.width || got.height != want.height {
+		t.Fatalf("%s: image %dx%d au lieu de %dx%d", name, got.width, got.height, want.width, want.height)
+	}
+	for y := 0; y < want.height; y++ {
+		for x := 0; x < want.width; x++ {
+			if got.At(x, y) != want.At(x, y) {
+				t.Fatalf("%s: pixel (%d, %d) différent", name, x, y)
+			}
+		}
+		// Les bits de remplissage restent à 0 : And, Or et l'écriture P4 en dépendent.
+		if padding := got.width % 8; padding != 0 {
+			if last := got.row(y)[got.stride-1]; last&(0xff>>padding) != 0 {
+				t.Fatalf("%s: bits de remplissage non nuls en ligne %d: %08b", name, y, last)
+			}
+		}
+	}
+}
+
+func TestPackedMatchesPBM(t *testing.T) {
+	operations := []struct {
+		name   string
+		packed func(*PackedPBM)
+		pbm    func(*PBM)
+	}{
+		{"Flip", (*PackedPBM).Flip, (*PBM).Flip},
+		{"Flop", (*PackedPBM).Flop, (*PBM).Flop},
+		{"Rotate90CW", (*PackedPBM).Rotate90CW, (*PBM).Rotate90CW},
+		{"Rotate90CCW", (*PackedPBM).Rotate90CCW, (*PBM).Rotate90CCW},
+		{"Rotate180", (*PackedPBM).Rotate180, (*PBM).Rotate180},
+		{"Invert", (*PackedPBM).Invert, (*PBM).Invert},
+	}
+	for _, size := range [][2]int{{13, 5}, {8, 8}, {1, 17}, {20, 9}} {
+		for _, operation := range operations {
+			pbm := testPBM(size[0], size[1], 0)
+			packed := pbm.Pack()
+			operation.packed(packed)
+			operation.pbm(pbm)
+			assertSamePBM(t, operation.name, packed, pbm)
+		}
+	}
+}
+
+func TestPackedCombine(t *testing.T) {
+	a, b := testPBM(13, 5, 0), testPBM(13, 5, 4)
+	combinations := []struct {
+		name      string
+		combine   func(*PackedPBM, *PackedPBM) error
+		operation func(a, b bool) bool
+	}{
+		{"And", (*PackedPBM).And, func(a, b bool) bool { return a && b }},
+		{"Or", (*PackedPBM).Or, func(a, b bool) bool { return a || b }},
+		{"Xor", (*PackedPBM).Xor, func(a, b bool) bool { return a != b }},
+	}
+	for _, combination := range combinations {
+		packed := a.Pack()
+		if err := combination.combine(packed, b.Pack()); err != nil {
+			t.Fatalf("%s: %v", combination.name, err)
+		}
+		want := NewPBM(13, 5)
+		for y := 0; y < 5; y++ {
+			for x := 0; x < 13; x++ {
+				want.Set(x, y, combination.operation(a.At(x, y), b.At(x, y)))
+			}
+		}
+		assertSamePBM(t, combination.name, packed, want)
+
+		if err := combination.combine(packed, NewPackedPBM(12, 5)); err == nil {
+			t.Errorf("%s: tailles différentes acceptées", combination.name)
+		}
+	}
+}
+
+func TestPackUnpack(t *testing.T) {
+	pbm := testPBM(13, 5, 2)
+	packed := pbm.Pack()
+	if len(packed.bits) != 2*5 {
+		t.Fatalf("%d octets, 10 attendus", len(packed.bits))
+	}
+	unpacked := packed.Unpack()
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 13; x++ {
+			if unpacked.At(x, y) != pbm.At(x, y) {
+				t.Fatalf("pixel (%d, %d) différent", x, y)
+			}
+		}
+	}
+
+	// Copy ne partage pas les octets : modifier la copie laisse l'original intact.
+	clone := packed.Copy()
+	clone.Invert()
+	assertSamePBM(t, "Copy", packed, pbm)
+}
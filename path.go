@@ -0,0 +1,382 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// LineCap est la forme des extrémités d'un tracé ouvert.
+type LineCap int
+
+const (
+	CapButt   LineCap = iota // Coupé net à l'extrémité
+	CapRound                 // Demi-disque centré sur l'extrémité
+	CapSquare                // Prolongé d'une demi-épaisseur
+)
+
+// String renvoie le nom de la forme d'extrémité.
+func (cap LineCap) String() string {
+	switch cap {
+	case CapButt:
+		return "net"
+	case CapRound:
+		return "rond"
+	case CapSquare:
+		return "carré"
+	}
+	return fmt.Sprintf("LineCap(%d)", int(cap))
+}
+
+// LineJoin est la forme de la jonction entre deux segments d'un tracé.
+type LineJoin int
+
+const (
+	JoinMiter LineJoin = iota // Angle vif, biseauté au-delà de miterLimit
+	JoinRound                 // Arrondi
+	JoinBevel                 // Biseau
+)
+
+// String renvoie le nom de la forme de jonction.
+func (join LineJoin) String() string {
+	switch join {
+	case JoinMiter:
+		return "onglet"
+	case JoinRound:
+		return "rond"
+	case JoinBevel:
+		return "biseau"
+	}
+	return fmt.Sprintf("LineJoin(%d)", int(join))
+}
+
+// miterLimit est le rapport maximal entre la longueur d'un angle vif et l'épaisseur du trait, comme
+// en SVG : au-delà, la jonction est biseautée.
+const miterLimit = 4
+
+// arcTolerance est l'écart maximal, en pixels, entre un arc et les segments qui l'approchent.
+const arcTolerance = 0.25
+
+// subpath est une suite de points reliés par des segments, éventuellement refermée.
+type subpath struct {
+	points []Vec2
+	closed bool
+}
+
+// Path est un tracé vectoriel fait de sous-tracés, construits par MoveTo, LineTo, ArcTo et Close,
+// puis remplis (FillPath) ou tracés avec une épaisseur (StrokePath). Les coordonnées sont réelles :
+// le pixel (x, y) couvre le carré [x, x+1[ × [y, y+1[ et son centre est en (x+0,5, y+0,5).
+type Path struct {
+	subpaths []subpath
+}
+
+// NewPath crée un tracé vide.
+func NewPath() *Path {
+	return &Path{}
+}
+
+// current renvoie le sous-tracé en cours, en en commençant un en (0, 0) s'il n'y en a pas.
+func (path *Path) current() *subpath {
+	if len(path.subpaths) == 0 || path.subpaths[len(path.subpaths)-1].closed {
+		start := Vec2{}
+		if n := len(path.subpaths); n > 0 {
+			start = path.subpaths[n-1].points[0]
+		}
+		path.subpaths = append(path.subpaths, subpath{points: []Vec2{start}})
+	}
+	return &path.subpaths[len(path.subpaths)-1]
+}
+
+// MoveTo commence un nouveau sous-tracé en (x, y).
+func (path *Path) MoveTo(x, y float64) {
+	path.subpaths = append(path.subpaths, subpath{points: []Vec2{{X: x, Y: y}}})
+}
+
+// LineTo prolonge le sous-tracé en cours d'un segment jusqu'à (x, y).
+func (path *Path) LineTo(x, y float64) {
+	current := path.current()
+	current.points = append(current.points, Vec2{X: x, Y: y})
+}
+
+// ArcTo arrondit le coin (x1, y1) d'un arc de cercle de rayon radius, comme arcTo en HTML : le
+// tracé va en ligne droite jusqu'au point où l'arc touche le segment qui mène au coin, suit l'arc,
+// et s'arrête là où il touche le segment allant du coin vers (x2, y2). Un rayon nul ou des points
+// alignés donnent un simple segment jusqu'au coin.
+func (path *Path) ArcTo(x1, y1, x2, y2, radius float64) {
+	current := path.current()
+	p0 := current.points[len(current.points)-1]
+	corner := Vec2{X: x1, Y: y1}
+	v1 := normalize(Vec2{X: p0.X - x1, Y: p0.Y - y1})
+	v2 := normalize(Vec2{X: x2 - x1, Y: y2 - y1})
+	if radius <= 0 || math.Abs(v1.X*v2.Y-v1.Y*v2.X) < 1e-9 {
+		path.LineTo(x1, y1)
+		return
+	}
+
+	// Demi-angle du coin, puis distance du coin aux points de contact et au centre.
+	half := math.Acos(math.Max(-1, math.Min(1, v1.X*v2.X+v1.Y*v2.Y))) / 2
+	tangent := radius / math.Tan(half)
+	bisector := normalize(Vec2{X: v1.X + v2.X, Y: v1.Y + v2.Y})
+	center := Vec2{X: corner.X + bisector.X*radius/math.Sin(half), Y: corner.Y + bisector.Y*radius/math.Sin(half)}
+	start := Vec2{X: corner.X + v1.X*tangent, Y: corner.Y + v1.Y*tangent}
+	end := Vec2{X: corner.X + v2.X*tangent, Y: corner.Y + v2.Y*tangent}
+
+	startAngle := math.Atan2(start.Y-center.Y, start.X-center.X)
+	sweep := math.Atan2(end.Y-center.Y, end.X-center.X) - startAngle
+	// L'arc suit le petit côté du cercle.
+	if sweep > math.Pi {
+		sweep -= 2 * math.Pi
+	} else if sweep < -math.Pi {
+		sweep += 2 * math.Pi
+	}
+	current.points = append(current.points, start)
+	current.points = append(current.points, arcPoints(center, radius, startAngle, sweep)[1:]...)
+}
+
+// Close referme le sous-tracé en cours en le reliant à son premier point.
+func (path *Path) Close() {
+	if len(path.subpaths) > 0 {
+		path.subpaths[len(path.subpaths)-1].closed = true
+	}
+}
+
+// normalize renvoie le vecteur v ramené à une longueur de 1 (nul si v est nul).
+func normalize(v Vec2) Vec2 {
+	length := math.Hypot(v.X, v.Y)
+	if length == 0 {
+		return Vec2{}
+	}
+	return Vec2{X: v.X / length, Y: v.Y / length}
+}
+
+// arcPoints approche par des segments l'arc de centre center, commençant à l'angle start et
+// parcourant sweep radians ; le premier et le dernier point sont les extrémités de l'arc.
+func arcPoints(center Vec2, radius, start, sweep float64) []Vec2 {
+	step := math.Pi / 2
+	if radius > arcTolerance {
+		step = 2 * math.Acos(1-arcTolerance/radius)
+	}
+	n := max(int(math.Ceil(math.Abs(sweep)/step)), 1)
+	points := make([]Vec2, n+1)
+	for i := range points {
+		angle := start + sweep*float64(i)/float64(n)
+		points[i] = Vec2{X: center.X + radius*math.Cos(angle), Y: center.Y + radius*math.Sin(angle)}
+	}
+	return points
+}
+
+// disk renvoie le polygone qui approche le disque de centre center.
+func disk(center Vec2, radius float64) []Vec2 {
+	points := arcPoints(center, radius, 0, 2*math.Pi)
+	return points[:len(points)-1]
+}
+
+// Stroke renvoie le contour du trait d'épaisseur width qui suit le tracé, sous forme d'un tracé
+// fait de polygones fermés à remplir avec FillNonZero. Les sous-tracés réduits à un point ne
+// donnent un point que si les extrémités sont rondes ou carrées.
+func (path *Path) Stroke(width float64, cap LineCap, join LineJoin) *Path {
+	outline := &Path{}
+	if width <= 0 {
+		return outline
+	}
+	half := width / 2
+	add := func(polygon ...Vec2) {
+		outline.subpaths = append(outline.subpaths, subpath{points: polygon, closed: true})
+	}
+
+	for _, sub := range path.subpaths {
+		// Les points répétés n'ont pas de direction : on les écarte.
+		points := sub.points[:1:1]
+		for _, p := range sub.points[1:] {
+			if p != points[len(points)-1] {
+				points = append(points, p)
+			}
+		}
+		if sub.closed && len(points) > 2 && points[0] == points[len(points)-1] {
+			points = points[:len(points)-1]
+		}
+
+		if len(points) == 1 {
+			switch cap {
+			case CapRound:
+				add(disk(points[0], half)...)
+			case CapSquare:
+				p := points[0]
+				add(Vec2{X: p.X - half, Y: p.Y - half}, Vec2{X: p.X + half, Y: p.Y - half},
+					Vec2{X: p.X + half, Y: p.Y + half}, Vec2{X: p.X - half, Y: p.Y + half})
+			}
+			continue
+		}
+
+		closed := sub.closed && len(points) > 2
+		segments := len(points) - 1
+		if closed {
+			segments++
+		}
+		for i := 0; i < segments; i++ {
+			a, b := points[i], points[(i+1)%len(points)]
+			d := normalize(Vec2{X: b.X - a.X, Y: b.Y - a.Y})
+			n := Vec2{X: -d.Y * half, Y: d.X * half}
+			// Les extrémités carrées prolongent le premier et le dernier segment.
+			if cap == CapSquare && !closed {
+				if i == 0 {
+					a = Vec2{X: a.X - d.X*half, Y: a.Y - d.Y*half}
+				}
+				if i == segments-1 {
+					b = Vec2{X: b.X + d.X*half, Y: b.Y + d.Y*half}
+				}
+			}
+			add(Vec2{X: a.X + n.X, Y: a.Y + n.Y}, Vec2{X: b.X + n.X, Y: b.Y + n.Y},
+				Vec2{X: b.X - n.X, Y: b.Y - n.Y}, Vec2{X: a.X - n.X, Y: a.Y - n.Y})
+		}
+
+		for i, p := range points {
+			if !closed && (i == 0 || i == len(points)-1) {
+				if cap == CapRound {
+					add(disk(p, half)...)
+				}
+				continue
+			}
+			previous := points[(i+len(points)-1)%len(points)]
+			next := points[(i+1)%len(points)]
+			if polygon := joinPolygon(previous, p, next, half, join); polygon != nil {
+				add(polygon...)
+			}
+		}
+	}
+
+	// Tous les morceaux sont orientés dans le même sens : leurs recouvrements ne s'annulent pas.
+	for _, sub := range outline.subpaths {
+		if signedArea(sub.points) < 0 {
+			for i, j := 0, len(sub.points)-1; i < j; i, j = i+1, j-1 {
+				sub.points[i], sub.points[j] = sub.points[j], sub.points[i]
+			}
+		}
+	}
+	return outline
+}
+
+// joinPolygon renvoie le polygone qui comble, du côté extérieur du virage, l'espace laissé entre
+// les segments previous→p et p→next d'un trait de demi-épaisseur half (nil s'ils sont alignés).
+func joinPolygon(previous, p, next Vec2, half float64, join LineJoin) []Vec2 {
+	d1 := normalize(Vec2{X: p.X - previous.X, Y: p.Y - previous.Y})
+	d2 := normalize(Vec2{X: next.X - p.X, Y: next.Y - p.Y})
+	turn := d1.X*d2.Y - d1.Y*d2.X
+	if math.Abs(turn) < 1e-9 && d1.X*d2.X+d1.Y*d2.Y > 0 {
+		return nil
+	}
+	if join == JoinRound {
+		return disk(p, half)
+	}
+
+	// Normales du côté extérieur du virage.
+	side := half
+	if turn > 0 {
+		side = -half
+	}
+	n1 := Vec2{X: -d1.Y * side, Y: d1.X * side}
+	n2 := Vec2{X: -d2.Y * side, Y: d2.X * side}
+	a := Vec2{X: p.X + n1.X, Y: p.Y + n1.Y}
+	b := Vec2{X: p.X + n2.X, Y: p.Y + n2.Y}
+	if join == JoinMiter {
+		// La pointe est sur la bissectrice des normales, à half/cos(φ/2) du sommet.
+		cosHalf := math.Sqrt(math.Max(0, (1+d1.X*d2.X+d1.Y*d2.Y)/2))
+		if cosHalf > 0 && 1/cosHalf <= miterLimit {
+			bisector := normalize(Vec2{X: n1.X + n2.X, Y: n1.Y + n2.Y})
+			tip := Vec2{X: p.X + bisector.X*half/cosHalf, Y: p.Y + bisector.Y*half/cosHalf}
+			return []Vec2{p, a, tip, b}
+		}
+	}
+	return []Vec2{p, a, b}
+}
+
+// signedArea renvoie l'aire algébrique du polygone, positive dans le sens des aiguilles d'une
+// montre à l'écran (voir PolygonWinding).
+func signedArea(points []Vec2) float64 {
+	area := 0.0
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	return area / 2
+}
+
+// pathCrossing est le passage d'un côté du tracé à travers la ligne de balayage.
+type pathCrossing struct {
+	x         float64
+	direction int // +1 si le côté descend, -1 s'il monte
+}
+
+// fillSpans appelle span pour chaque suite de pixels de la ligne y, dans les limites width×height,
+// dont le centre est à l'intérieur du tracé selon la règle de remplissage. Les sous-tracés sont
+// tous considérés fermés.
+func (path *Path) fillSpans(width, height int, rule FillRule, span func(y, startX, endX int)) {
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, sub := range path.subpaths {
+		for _, p := range sub.points {
+			minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+		}
+	}
+	if minY > maxY {
+		return
+	}
+	startY := max(int(math.Ceil(minY-0.5)), 0)
+	endY := min(int(math.Ceil(maxY-0.5)), height)
+
+	var crossings []pathCrossing
+	for y := startY; y < endY; y++ {
+		center := float64(y) + 0.5
+		crossings = crossings[:0]
+		for _, sub := range path.subpaths {
+			for i, a := range sub.points {
+				b := sub.points[(i+1)%len(sub.points)]
+				if (a.Y <= center) == (b.Y <= center) {
+					continue
+				}
+				crossing := pathCrossing{x: a.X + (center-a.Y)*(b.X-a.X)/(b.Y-a.Y), direction: 1}
+				if b.Y < a.Y {
+					crossing.direction = -1
+				}
+				crossings = append(crossings, crossing)
+			}
+		}
+		sort.Slice(crossings, func(i, j int) bool { return crossings[i].x < crossings[j].x })
+
+		winding := 0
+		for i := 0; i+1 < len(crossings); i++ {
+			winding += crossings[i].direction
+			inside := winding != 0
+			if rule == FillEvenOdd {
+				inside = (i+1)%2 == 1
+			}
+			if !inside {
+				continue
+			}
+			// Pixels dont le centre est dans [x_i, x_i+1[.
+			startX := max(int(math.Ceil(crossings[i].x-0.5)), 0)
+			endX := min(int(math.Ceil(crossings[i+1].x-0.5)), width) - 1
+			if startX <= endX {
+				span(y, startX, endX)
+			}
+		}
+	}
+}
+
+// FillPath remplit l'intérieur du tracé avec la couleur donnée, selon la règle de remplissage. Les
+// sous-tracés ouverts sont refermés implicitement ; les pixels hors de l'image sont ignorés. Le tracé
+// est exprimé dans le repère en cours (voir Translate).
+func (ppm *PPM) FillPath(path *Path, rule FillRule, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	if ppm.repeatSymmetric(func() { ppm.FillPath(path, rule, color) }) {
+		return
+	}
+	path = ppm.toDevicePath(path)
+	path.fillSpans(ppm.width, ppm.height, rule, func(y, startX, endX int) {
+		ppm.drawHorizontalLine(y, startX, endX, color)
+	})
+}
+
+// StrokePath trace le tracé avec un trait d'épaisseur width, aux extrémités et jonctions données.
+func (ppm *PPM) StrokePath(path *Path, width float64, cap LineCap, join LineJoin, color Pixel) {
+	ppm.FillPath(path.Stroke(width, cap, join), FillNonZero, color)
+}
@@ -1,20 +1,26 @@
-package main
+package netpbm
 
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
-	"strings"
 )
 
 // PBM représente la structure d'une image PBM
 type PBM struct {
-	data          [][]bool
+	data          [][]bool // true = noir, comme dans le fichier
 	width, height int
 	magicNumber   string
+	polarity      Polarity      // Convention de At et Set (voir Polarity)
+	shared        []bool        // Lignes partagées avec une copie (voir Copy)
+	viewed        []bool        // Lignes dont les pixels sont aussi ceux d'une vue (voir SubImage)
+	changes       changeTracker // Zones modifiées (voir Dirty)
+	meta          Metadata      // Métadonnées de l'en-tête (voir Metadata)
 }
 
-// ReadPBM lit une image PBM à partir d'un fichier et renvoie une structure qui représente l'image.
+// ReadPBM lit une image PBM, ASCII (P1) ou binaire (P4), à partir d'un fichier et renvoie une
+// structure qui représente l'image.
 func ReadPBM(filename string) (*PBM, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -22,61 +28,97 @@ func ReadPBM(filename string) (*PBM, error) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	scanner, err := scanFile(file, DefaultDecodeOptions)
+	if err != nil {
+		return nil, err
+	}
+	return decodePBM(scanner)
+}
 
-	var magicNumber string
-	var width, height int
-	var data [][]bool
+// DecodePBM lit une image PBM, ASCII (P1) ou binaire (P4), à partir de r.
+func DecodePBM(r io.Reader) (*PBM, error) {
+	return decodePBM(newSampleScanner(r, DefaultDecodeOptions.bufferSize()))
+}
 
-	lineCount := 0
-	for scanner.Scan() {
-		line := strings.Fields(scanner.Text()) // Utiliser Fields pour diviser la ligne en mots
-		if len(line) == 0 {
-			continue
-		}
+// decodePBM lit une image PBM à travers scanner.
+func decodePBM(scanner *sampleScanner) (*PBM, error) {
+	// Les pixels sont lus un à un (voir sampleScanner) : ils peuvent être accolés ("0110") ou
+	// séparés par des blancs, et répartis librement sur les lignes du fichier.
+	magicNumber, err := scanner.token()
+	if err != nil {
+		return nil, fmt.Errorf("en-tête illisible: %v", err)
+	}
+	if magicNumber != "P1" && magicNumber != "P4" {
+		return nil, fmt.Errorf("format PBM non pris en charge: %s", magicNumber)
+	}
+	width, height, _, err := scanner.dimensions(false)
+	if err != nil {
+		return nil, err
+	}
 
-		if lineCount == 0 {
-			magicNumber = line[0]
-		} else if lineCount == 1 {
-			_, err := fmt.Sscanf(line[0], "%d", &width)
-			if err != nil {
-				return nil, fmt.Errorf("Erreur de format des dimensions: %v", err)
-			}
+	pbm := NewPBM(width, height)
+	pbm.magicNumber = magicNumber
+	pbm.polarity = DefaultDecodeOptions.Polarity
+	if magicNumber == "P4" {
+		err = pbm.readRaw(scanner)
+	} else {
+		err = pbm.readPlain(scanner)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-			_, err = fmt.Sscanf(line[1], "%d", &height)
-			if err != nil {
-				return nil, fmt.Errorf("Erreur de format des dimensions: %v", err)
-			}
+	for _, comment := range scanner.comments {
+		pbm.meta.parseComment(comment)
+	}
+	if err := verifyChecksum(pbm); err != nil {
+		return nil, err
+	}
+	return pbm, nil
+}
 
-			data = make([][]bool, height)
-			for i := range data {
-				data[i] = make([]bool, width)
-			}
-		} else {
-			// Traitement des valeurs des pixels
-			for j, val := range line {
-				if val == "1" {
-					data[lineCount-2][j] = true
-				} else if val == "0" {
-					data[lineCount-2][j] = false
-				} else {
-					return nil, fmt.Errorf("Erreur de format des valeurs des pixels: valeur inattendue %s", val)
-				}
+// readPlain lit les pixels d'un fichier P1.
+func (pbm *PBM) readPlain(scanner *sampleScanner) error {
+	var err error
+	for i, row := range pbm.data {
+		for j := range row {
+			if row[j], err = scanner.bit(); err != nil {
+				return fmt.Errorf("ligne %d de l'image: %v", i+1, err)
 			}
 		}
-		lineCount++
 	}
+	return nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+// readRaw lit les pixels d'un fichier P4 : 8 pixels par octet, bit de poids fort à gauche, chaque
+// ligne complétée à l'octet.
+func (pbm *PBM) readRaw(scanner *sampleScanner) error {
+	if err := scanner.endHeader(); err != nil {
+		return err
+	}
+	packed := make([]byte, (pbm.width+7)/8)
+	for i, row := range pbm.data {
+		if err := scanner.read(packed); err != nil {
+			return fmt.Errorf("ligne %d de l'image: %v", i+1, err)
+		}
+		unpackRow(row, packed)
+	}
+	return nil
+}
+
+// NewPBM crée une image PBM blanche (tous les pixels à 0) de la taille donnée.
+func NewPBM(width, height int) *PBM {
+	data := make([][]bool, height)
+	for i := range data {
+		data[i] = make([]bool, width)
 	}
 
 	return &PBM{
 		data:        data,
 		width:       width,
 		height:      height,
-		magicNumber: magicNumber,
-	}, nil
+		magicNumber: "P1",
+	}
 }
 
 // Display affiche l'image PBM.
@@ -107,52 +149,65 @@ func (pbm *PBM) Size() (int, int) {
 	return pbm.width, pbm.height
 }
 
-// At retourne la valeur du pixel aux coordonnées (x, y).
+// At retourne la valeur du pixel aux coordonnées (x, y), dans la convention de l'image (voir Polarity).
 func (pbm *PBM) At(x, y int) bool {
-	return pbm.data[y][x]
+	return pbm.polarity.black(pbm.data[y][x])
 }
 
-// Set définit la valeur du pixel aux coordonnées (x, y).
+// Set définit la valeur du pixel aux coordonnées (x, y), dans la convention de l'image (voir Polarity).
 func (pbm *PBM) Set(x, y int, value bool) {
-	pbm.data[y][x] = value
+	pbm.writableRow(y)[x] = pbm.polarity.black(value)
+	pbm.changes.mark(Rect{X: x, Y: y, Width: 1, Height: 1})
 }
 
-// Save enregistre l'image PBM dans un fichier et renvoie une erreur s'il y a un problème.
+// Save enregistre l'image PBM dans un fichier et renvoie une erreur s'il y a un problème. Le format,
+// P1 (ASCII) ou P4 (binaire, 8 pixels par octet), est celui du nombre magique de l'image.
 func (pbm *PBM) Save(filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	if err := pbm.Encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
 
-	writer := bufio.NewWriter(file)
+// Encode écrit l'image PBM dans w, en P1 ou en P4 selon son nombre magique, comme Save.
+func (pbm *PBM) Encode(w io.Writer) error {
+	writer := bufio.NewWriter(w)
 
 	// Écriture du magic number, de la largeur et de la hauteur
 	fmt.Fprintf(writer, "%s\n%d %d\n", pbm.magicNumber, pbm.width, pbm.height)
 
 	// Écriture des valeurs des pixels
-	for i := 0; i < pbm.height; i++ {
-		for j := 0; j < pbm.width; j++ {
-			if pbm.data[i][j] {
-				fmt.Fprint(writer, "1")
-			} else {
-				fmt.Fprint(writer, "0")
+	if pbm.magicNumber == "P4" {
+		packed := make([]byte, (pbm.width+7)/8)
+		for _, row := range pbm.data {
+			packRow(packed, row)
+			if _, err := writer.Write(packed); err != nil {
+				return err
 			}
 		}
-		fmt.Fprintln(writer)
+		return writer.Flush()
 	}
-
-	// Assurez-vous que toutes les données tamponnées sont écrites dans le fichier
-	err = writer.Flush()
-	if err != nil {
-		return err
+	for i := 0; i < pbm.height; i++ {
+		for j := 0; j < pbm.width; j++ {
+			writer.WriteByte('0' + byte(boolToInt(pbm.data[i][j])))
+		}
+		writer.WriteByte('\n')
 	}
 
-	return nil
+	// Assurez-vous que toutes les données tamponnées sont écrites
+	return writer.Flush()
 }
 
 // Invert inverse les couleurs de l'image PBM.
 func (pbm *PBM) Invert() {
+	defer pbm.changes.batch(pbm.Size)()
+	pbm.own()
+	pbm.meta.record("invert")
 	for i := 0; i < pbm.height; i++ {
 		for j := 0; j < pbm.width; j++ {
 			pbm.data[i][j] = !pbm.data[i][j]
@@ -162,6 +217,9 @@ func (pbm *PBM) Invert() {
 
 // Flip inverse horizontalement l'image PBM.
 func (pbm *PBM) Flip() {
+	defer pbm.changes.batch(pbm.Size)()
+	pbm.own()
+	pbm.meta.record("flip")
 	for i := 0; i < pbm.height; i++ {
 		for j, k := 0, pbm.width-1; j < k; j, k = j+1, k-1 {
 			pbm.data[i][j], pbm.data[i][k] = pbm.data[i][k], pbm.data[i][j]
@@ -171,54 +229,79 @@ func (pbm *PBM) Flip() {
 
 // Flop inverse verticalement l'image PBM.
 func (pbm *PBM) Flop() {
+	defer pbm.changes.batch(pbm.Size)()
+	pbm.own()
+	pbm.meta.record("flop")
 	for i, j := 0, pbm.height-1; i < j; i, j = i+1, j-1 {
 		pbm.data[i], pbm.data[j] = pbm.data[j], pbm.data[i]
 	}
 }
 
-// SetMagicNumber définit le magic number de l'image PBM.
-func (pbm *PBM) SetMagicNumber(magicNumber string) {
-	pbm.magicNumber = magicNumber
-}
+// Rotate90CW fait pivoter l'image PBM de 90° dans le sens des aiguilles d'une montre.
+func (pbm *PBM) Rotate90CW() {
+	defer pbm.changes.batch(pbm.Size)()
+	pbm.own()
+	pbm.detachViews()
+	pbm.meta.record("rotate 90")
+	rotatedData := make([][]bool, pbm.width)
+	for i := range rotatedData {
+		rotatedData[i] = make([]bool, pbm.height)
+	}
 
-func main() {
-	// Exemple d'utilisation
-	image, err := ReadPBM("exemple.pbm")
-	if err != nil {
-		fmt.Println("Erreur lors de la lecture de l'image PBM :", err)
-		return
+	for i := 0; i < pbm.height; i++ {
+		for j := 0; j < pbm.width; j++ {
+			rotatedData[j][pbm.height-i-1] = pbm.data[i][j]
+		}
 	}
 
-	// Afficher les informations de l'image
-	fmt.Printf("Nombre magique : %s\n", image.magicNumber)
-	width, height := image.Size()
-	fmt.Printf("Dimensions du dessin : %d x %d\n", width, height)
+	pbm.data = rotatedData
+	pbm.width, pbm.height = pbm.height, pbm.width
+}
 
-	// Afficher la valeur d'un pixel (par exemple, à la position (2, 3))
-	value := image.At(2, 3)
-	fmt.Printf("Valeur du pixel à la position (2, 3) : %t\n", value)
+// Rotate90CCW fait pivoter l'image PBM de 90° dans le sens inverse des aiguilles d'une montre.
+func (pbm *PBM) Rotate90CCW() {
+	defer pbm.changes.batch(pbm.Size)()
+	pbm.own()
+	pbm.detachViews()
+	pbm.meta.record("rotate 270")
+	rotatedData := make([][]bool, pbm.width)
+	for i := range rotatedData {
+		rotatedData[i] = make([]bool, pbm.height)
+	}
 
-	// Appliquer des opérations (par exemple, Inverser, Flip, Flop)
+	for i := 0; i < pbm.height; i++ {
+		for j := 0; j < pbm.width; j++ {
+			rotatedData[pbm.width-j-1][i] = pbm.data[i][j]
+		}
+	}
 
-	fmt.Println("Image normale:")
-	image.Display()
+	pbm.data = rotatedData
+	pbm.width, pbm.height = pbm.height, pbm.width
+}
 
-	// Affiche l'image inversée
-	image.Invert()
-	fmt.Println("Image inversée:")
-	image.Display()
+// Rotate180 fait pivoter l'image PBM d'un demi-tour.
+func (pbm *PBM) Rotate180() {
+	defer pbm.changes.batch(pbm.Size)()
+	pbm.own()
+	pbm.detachViews()
+	pbm.meta.record("rotate 180")
+	for i, j := 0, pbm.height-1; i < j; i, j = i+1, j-1 {
+		pbm.data[i], pbm.data[j] = pbm.data[j], pbm.data[i]
+	}
+	for _, row := range pbm.data {
+		for j, k := 0, len(row)-1; j < k; j, k = j+1, k-1 {
+			row[j], row[k] = row[k], row[j]
+		}
+	}
+}
 
-	image.Flip()
-	fmt.Println("Image inversée horizontalement:")
-	image.Display()
+// MagicNumber renvoie le magic number de l'image PBM.
+func (pbm *PBM) MagicNumber() string {
+	return pbm.magicNumber
+}
 
-	image.Flop()
-	fmt.Println("Image inversée verticalement:")
-	image.Display()
-	// Enregistrer l'image modifiée
-	err = image.Save("image_modifiee.pbm")
-	if err != nil {
-		fmt.Println("Erreur lors de l'enregistrement de l'image PBM :", err)
-		return
-	}
-}
\ No newline at end of file
+// SetMagicNumber définit le magic number de l'image PBM : "P1" ou "P4", il choisit la variante ASCII
+// ou binaire écrite par Save.
+func (pbm *PBM) SetMagicNumber(magicNumber string) {
+	pbm.magicNumber = magicNumber
+}
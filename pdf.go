@@ -0,0 +1,135 @@
+package netpbm
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// pdfWriter écrit un fichier PDF objet par objet en notant la position de chacun, pour la table
+// de références croisées.
+type pdfWriter struct {
+	w       *bufio.Writer
+	offset  int
+	objects []int
+}
+
+// printf écrit du texte dans le fichier.
+func (pdf *pdfWriter) printf(format string, args ...any) {
+	n, _ := fmt.Fprintf(pdf.w, format, args...)
+	pdf.offset += n
+}
+
+// object écrit l'objet suivant, de dictionnaire dict, suivi du flux stream s'il n'est pas nil.
+func (pdf *pdfWriter) object(dict string, stream []byte) {
+	pdf.objects = append(pdf.objects, pdf.offset)
+	pdf.printf("%d 0 obj\n%s\n", len(pdf.objects), dict)
+	if stream != nil {
+		pdf.printf("stream\n")
+		n, _ := pdf.w.Write(stream)
+		pdf.offset += n
+		pdf.printf("\nendstream\n")
+	}
+	pdf.printf("endobj\n")
+}
+
+// finish écrit la table de références croisées et la fin du fichier, l'objet 1 étant le catalogue.
+func (pdf *pdfWriter) finish() error {
+	start := pdf.offset
+	pdf.printf("xref\n0 %d\n0000000000 65535 f \n", len(pdf.objects)+1)
+	for _, offset := range pdf.objects {
+		pdf.printf("%010d 00000 n \n", offset)
+	}
+	pdf.printf("trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pdf.objects)+1, start)
+	return pdf.w.Flush()
+}
+
+// pdfImage renvoie l'espace de couleurs, le nombre de bits par composante, le tableau Decode
+// éventuel et les échantillons non compressés de l'image.
+func pdfImage(img Image) (colorSpace string, bits int, decode string, samples []byte, err error) {
+	if packed, ok := img.(*PackedPBM); ok {
+		// Dans un PDF comme dans un fichier PBM, les lignes sont complétées à l'octet ; seul le
+		// sens des bits diffère (0 est noir en DeviceGray).
+		return "/DeviceGray", 1, " /Decode [1 0]", packed.bits, nil
+	}
+	switch img := img.(type) {
+	case *PBM:
+		return pdfImage(img.Pack())
+	case *PGM:
+		samples = make([]byte, 0, img.width*img.height)
+		for _, row := range img.data {
+			for _, value := range row {
+				samples = append(samples, scaleTo8(value, img.max))
+			}
+		}
+		return "/DeviceGray", 8, "", samples, nil
+	case *PPM:
+		samples = make([]byte, 0, 3*img.width*img.height)
+		for _, row := range img.data {
+			for _, pixel := range row {
+				for c := 0; c < 3; c++ {
+					samples = append(samples, scaleTo8(pixel[c], img.max))
+				}
+			}
+		}
+		return "/DeviceRGB", 8, "", samples, nil
+	}
+	return "", 0, "", nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+}
+
+// exportPDF écrit un PDF d'une page contenant l'image, imprimée à dpi points par pouce (ceux des
+// métadonnées de l'image si dpi est nul, 72 à défaut).
+func exportPDF(w io.Writer, img Image, dpi float64) error {
+	if dpi <= 0 {
+		dpi = 72
+		if meta := metadataOf(img); meta != nil && meta.DPI > 0 {
+			dpi = meta.DPI
+		}
+	}
+	colorSpace, bits, decode, samples, err := pdfImage(img)
+	if err != nil {
+		return err
+	}
+	var compressed bytes.Buffer
+	flate := zlib.NewWriter(&compressed)
+	flate.Write(samples)
+	if err := flate.Close(); err != nil {
+		return err
+	}
+
+	width, height := img.Size()
+	// La page a la taille de l'image imprimée, en points (1/72 de pouce).
+	pageWidth := strconv.FormatFloat(float64(width)*72/dpi, 'f', -1, 64)
+	pageHeight := strconv.FormatFloat(float64(height)*72/dpi, 'f', -1, 64)
+	content := []byte(fmt.Sprintf("q %s 0 0 %s 0 0 cm /Im0 Do Q", pageWidth, pageHeight))
+
+	pdf := &pdfWriter{w: bufio.NewWriter(w)}
+	pdf.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	pdf.object("<< /Type /Catalog /Pages 2 0 R >>", nil)
+	pdf.object("<< /Type /Pages /Kids [3 0 R] /Count 1 >>", nil)
+	pdf.object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /XObject << /Im0 4 0 R >> >> /Contents 5 0 R >>",
+		pageWidth, pageHeight), nil)
+	pdf.object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent %d%s /Filter /FlateDecode /Length %d >>",
+		width, height, colorSpace, bits, decode, compressed.Len()), compressed.Bytes())
+	pdf.object(fmt.Sprintf("<< /Length %d >>", len(content)), content)
+	return pdf.finish()
+}
+
+// ExportPDF écrit l'image PPM dans un PDF d'une page à sa taille d'impression à dpi points par
+// pouce (la résolution de ses métadonnées si dpi est nul, 72 à défaut).
+func (ppm *PPM) ExportPDF(w io.Writer, dpi float64) error {
+	return exportPDF(w, ppm, dpi)
+}
+
+// ExportPDF écrit l'image PGM dans un PDF d'une page, comme PPM.ExportPDF.
+func (pgm *PGM) ExportPDF(w io.Writer, dpi float64) error {
+	return exportPDF(w, pgm, dpi)
+}
+
+// ExportPDF écrit l'image PBM dans un PDF d'une page, comme PPM.ExportPDF.
+func (pbm *PBM) ExportPDF(w io.Writer, dpi float64) error {
+	return exportPDF(w, pbm, dpi)
+}
@@ -0,0 +1,131 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+)
+
+// Homography représente une transformation projective du plan (matrice 3×3, h[2][2] = 1).
+type Homography [3][3]float64
+
+// NewHomography calcule la transformation projective qui envoie les quatre points from sur les quatre points to.
+func NewHomography(from, to [4]Point) (Homography, error) {
+	// Système linéaire 8×8 classique (transformation linéaire directe).
+	var a [8][9]float64
+	for i := 0; i < 4; i++ {
+		x, y := float64(from[i].X), float64(from[i].Y)
+		u, v := float64(to[i].X), float64(to[i].Y)
+		a[2*i] = [9]float64{x, y, 1, 0, 0, 0, -u * x, -u * y, u}
+		a[2*i+1] = [9]float64{0, 0, 0, x, y, 1, -v * x, -v * y, v}
+	}
+
+	// Élimination de Gauss avec pivot partiel.
+	for col := 0; col < 8; col++ {
+		pivot := col
+		for row := col + 1; row < 8; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return Homography{}, fmt.Errorf("quadrilatère dégénéré: trois points sont alignés")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+
+		for row := 0; row < 8; row++ {
+			if row == col {
+				continue
+			}
+			factor := a[row][col] / a[col][col]
+			for k := col; k < 9; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+		}
+	}
+
+	var h Homography
+	for i := 0; i < 8; i++ {
+		h[i/3][i%3] = a[i][8] / a[i][i]
+	}
+	h[2][2] = 1
+	return h, nil
+}
+
+// Apply renvoie l'image du point (x, y) par la transformation.
+func (h Homography) Apply(x, y float64) (float64, float64) {
+	w := h[2][0]*x + h[2][1]*y + h[2][2]
+	return (h[0][0]*x + h[0][1]*y + h[0][2]) / w, (h[1][0]*x + h[1][1]*y + h[1][2]) / w
+}
+
+// WarpPerspective renvoie une image de même taille dans laquelle le quadrilatère srcQuad de
+// l'image PPM a été projeté sur le quadrilatère dstQuad (coins dans le même ordre), avec
+// interpolation bilinéaire. Les pixels dont l'antécédent sort de l'image source sont noirs.
+// Avec dstQuad aux coins d'un rectangle, cela redresse par exemple un document photographié de biais.
+func (ppm *PPM) WarpPerspective(srcQuad, dstQuad [4]Point) (*PPM, error) {
+	result := NewPPM(ppm.width, ppm.height, ppm.max)
+	result.magicNumber = ppm.magicNumber
+	if err := result.warpFrom(ppm, srcQuad, dstQuad, false); err != nil {
+		return nil, err
+	}
+	result.meta = ppm.meta.derive("warp")
+	return result, nil
+}
+
+// MapQuad plaque le quadrilatère srcQuad de la texture sur le quadrilatère dstQuad de l'image PPM.
+// Seuls les pixels situés à l'intérieur de dstQuad sont modifiés.
+func (ppm *PPM) MapQuad(texture *PPM, srcQuad, dstQuad [4]Point) error {
+	if err := ppm.warpFrom(texture, srcQuad, dstQuad, true); err != nil {
+		return err
+	}
+	ppm.meta.record("map quad")
+	return nil
+}
+
+// warpFrom remplit l'image par projection inverse depuis la source. Si insideOnly est vrai,
+// seuls les pixels contenus dans dstQuad sont écrits.
+func (ppm *PPM) warpFrom(source *PPM, srcQuad, dstQuad [4]Point, insideOnly bool) error {
+	h, err := NewHomography(dstQuad, srcQuad)
+	if err != nil {
+		return err
+	}
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+
+	samples := source.toFloat(false)
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			if insideOnly && !pointInQuad(float64(x), float64(y), dstQuad) {
+				continue
+			}
+			sx, sy := h.Apply(float64(x), float64(y))
+			if !samples.contains(sx, sy) {
+				if !insideOnly {
+					ppm.data[y][x] = []uint8{0, 0, 0}
+				}
+				continue
+			}
+			value := make([]uint8, 3)
+			for c := 0; c < 3; c++ {
+				value[c] = encodeValue(samples.bilinear(sx, sy, c), ppm.max, false)
+			}
+			ppm.data[y][x] = value
+		}
+	}
+	return nil
+}
+
+// pointInQuad indique si le point (x, y) est à l'intérieur du quadrilatère (règle pair-impair).
+func pointInQuad(x, y float64, quad [4]Point) bool {
+	inside := false
+	for i := 0; i < 4; i++ {
+		a, b := quad[i], quad[(i+1)%4]
+		ay, by := float64(a.Y), float64(b.Y)
+		if (ay > y) != (by > y) {
+			crossX := float64(a.X) + (y-ay)/(by-ay)*float64(b.X-a.X)
+			if x < crossX {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
@@ -0,0 +1,32 @@
+package netpbm
+
+import "testing"
+
+func TestMapQuadDegenerateLeavesImage(t *testing.T) {
+	ppm := NewPPM(8, 8, 255)
+	notified := 0
+	ppm.OnChange(func(Rect) { notified++ })
+
+	// Trois coins confondus : la projection n'existe pas.
+	flat := [4]Point{{0, 0}, {0, 0}, {0, 0}, {7, 7}}
+	corners := [4]Point{{0, 0}, {7, 0}, {7, 7}, {0, 7}}
+	if err := ppm.MapQuad(NewPPM(4, 4, 255), corners, flat); err == nil {
+		t.Fatal("quadrilatère dégénéré accepté")
+	}
+	if !ppm.Dirty().Empty() || notified != 0 {
+		t.Errorf("image marquée modifiée (%v, %d notifications)", ppm.Dirty(), notified)
+	}
+}
+
+func TestWarpPerspectiveIdentity(t *testing.T) {
+	ppm := NewPPM(6, 4, 255)
+	ppm.Set(2, 1, []uint8{10, 20, 30})
+	corners := [4]Point{{0, 0}, {5, 0}, {5, 3}, {0, 3}}
+	warped, err := ppm.WarpPerspective(corners, corners)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := warped.At(2, 1); got[0] != 10 || got[1] != 20 || got[2] != 30 {
+		t.Errorf("pixel %v au lieu de [10 20 30]", got)
+	}
+}
@@ -1,11 +1,11 @@
-package main
+package netpbm
 
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
-	"strings"
 )
 
 // PGM représente une image PGM.
@@ -14,6 +14,10 @@ type PGM struct {
 	width, height int
 	magicNumber   string
 	max           int
+	shared        []bool        // Lignes partagées avec une copie (voir Copy)
+	viewed        []bool        // Lignes dont les pixels sont aussi ceux d'une vue (voir SubImage)
+	changes       changeTracker // Zones modifiées (voir Dirty)
+	meta          Metadata      // Métadonnées de l'en-tête (voir Metadata)
 }
 
 // Display affiche le dessin de l'image PGM dans la console.
@@ -26,7 +30,8 @@ func (pgm *PGM) Display() {
 	}
 }
 
-// ReadPGM lit une image PGM à partir d'un fichier et renvoie une structure qui représente l'image.
+// ReadPGM lit une image PGM, ASCII (P2) ou binaire (P5), à partir d'un fichier et renvoie une
+// structure qui représente l'image.
 func ReadPGM(filename string) (*PGM, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -34,74 +39,85 @@ func ReadPGM(filename string) (*PGM, error) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	var magicNumber string
-	var width, height, max int
-
-	// Lire les informations d'en-tête PGM.
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "#") {
-			// Ignorer les commentaires
-			continue
-		}
-		if magicNumber == "" {
-			magicNumber = line
-			if magicNumber != "P2" {
-				return nil, fmt.Errorf("format PGM non pris en charge: %s", magicNumber)
-			}
-		} else {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				width, _ = strconv.Atoi(fields[0])
-				height, _ = strconv.Atoi(fields[1])
-				break // Sortir après avoir lu les dimensions
-			}
-		}
-	}
-
-	if width == 0 || height == 0 {
-		return nil, fmt.Errorf("dimensions de l'image non spécifiées")
+	scanner, err := scanFile(file, DefaultDecodeOptions)
+	if err != nil {
+		return nil, err
 	}
+	return decodePGM(scanner)
+}
 
-	// Lire la valeur maximale
-	scanner.Scan()
-	max, _ = strconv.Atoi(scanner.Text())
+// DecodePGM lit une image PGM à partir de r, comme ReadPGM à partir d'un fichier.
+func DecodePGM(r io.Reader) (*PGM, error) {
+	return decodePGM(newSampleScanner(r, DefaultDecodeOptions.bufferSize()))
+}
 
-	data := make([][]uint8, height)
-	for i := 0; i < height; i++ {
-		data[i] = make([]uint8, width)
+// decodePGM lit une image PGM à travers scanner.
+func decodePGM(scanner *sampleScanner) (*PGM, error) {
+	// Les champs sont lus octet par octet (voir sampleScanner) : les échantillons peuvent être
+	// répartis librement sur les lignes du fichier.
+	magicNumber, err := scanner.token()
+	if err != nil {
+		return nil, fmt.Errorf("en-tête illisible: %v", err)
+	}
+	if magicNumber != "P2" && magicNumber != "P5" {
+		return nil, fmt.Errorf("format PGM non pris en charge: %s", magicNumber)
+	}
+	width, height, max, err := scanner.dimensions(true)
+	if err != nil {
+		return nil, err
+	}
+	if err := check8Bits(max); err != nil {
+		return nil, err
 	}
 
-	// Lire les données de l'image.
-	for i := 0; i < height; i++ {
-		scanner.Scan()
-		line := scanner.Text()
-		if len(line) == 0 {
-			return nil, fmt.Errorf("ligne vide à la position %d", i+2) // +2 pour compenser le 0-indexing et le fait que la première ligne est le chiffre magique "P2"
-		}
-
-		values := strings.Fields(line)
-
-		if len(values) < width {
-			return nil, fmt.Errorf("nombre insuffisant de valeurs sur la ligne %d, valeurs trouvées: %v", i+2, values) // +2 pour compenser le 0-indexing et le fait que la première ligne est le chiffre magique "P2"
-		}
+	pgm := NewPGM(width, height, max)
+	pgm.magicNumber = magicNumber
+	if magicNumber == "P5" {
+		err = pgm.readRaw(scanner)
+	} else {
+		err = pgm.readPlain(scanner)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		// S'assurer que le tableau a une taille suffisante
-		if len(data[i]) < width {
-			data[i] = make([]uint8, width)
-		}
+	for _, comment := range scanner.comments {
+		pgm.meta.parseComment(comment)
+	}
+	if err := verifyChecksum(pgm); err != nil {
+		return nil, err
+	}
+	return pgm, nil
+}
 
-		for j := 0; j < width; j++ {
-			value, err := strconv.Atoi(values[j])
+// readPlain lit les pixels d'un fichier P2, en décimal.
+func (pgm *PGM) readPlain(scanner *sampleScanner) error {
+	for i, row := range pgm.data {
+		for j := range row {
+			value, err := scanner.next()
 			if err != nil {
-				return nil, err
+				return fmt.Errorf("ligne %d de l'image: %v", i+1, err)
+			}
+			if value > pgm.max {
+				return fmt.Errorf("ligne %d de l'image: valeur %d supérieure au maximum %d", i+1, value, pgm.max)
 			}
-			data[i][j] = uint8(value)
+			row[j] = uint8(value)
 		}
 	}
+	return nil
+}
 
-	return &PGM{data, width, height, magicNumber, max}, nil
+// readRaw lit les pixels d'un fichier P5, un octet par pixel.
+func (pgm *PGM) readRaw(scanner *sampleScanner) error {
+	if err := scanner.endHeader(); err != nil {
+		return err
+	}
+	for i, row := range pgm.data {
+		if err := scanner.read(row); err != nil {
+			return fmt.Errorf("ligne %d de l'image: %v", i+1, err)
+		}
+	}
+	return nil
 }
 
 // Size renvoie la largeur et la hauteur de l'image.
@@ -116,7 +132,8 @@ func (pgm *PGM) At(x, y int) uint8 {
 
 // Set définit la valeur du pixel à (x, y).
 func (pgm *PGM) Set(x, y int, value uint8) {
-	pgm.data[y][x] = value
+	pgm.writableRow(y)[x] = value
+	pgm.changes.mark(Rect{X: x, Y: y, Width: 1, Height: 1})
 }
 
 // Save enregistre l'image PGM dans un fichier et renvoie une erreur en cas de problème.
@@ -125,36 +142,60 @@ func (pgm *PGM) Save(filename string) error {
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
+	if err := pgm.Encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
 
+// Encode écrit l'image PGM dans w, au format de Save : P2 (ASCII) ou P5 (binaire) selon son
+// nombre magique.
+func (pgm *PGM) Encode(w io.Writer) error {
+	writer := bufio.NewWriter(w)
 	fmt.Fprintf(writer, "%s\n", pgm.magicNumber)
 	fmt.Fprintf(writer, "%d %d\n", pgm.width, pgm.height)
 	fmt.Fprintf(writer, "%d\n", pgm.max)
 
+	// Chaque ligne est formatée dans un tampon réutilisé, sans allocation par pixel ; en P5, elle
+	// est écrite telle quelle.
+	line := make([]byte, 0, 4*pgm.width+1)
 	for _, row := range pgm.data {
+		if pgm.magicNumber == "P5" {
+			if _, err := writer.Write(row); err != nil {
+				return err
+			}
+			continue
+		}
+		line = line[:0]
 		for _, value := range row {
-			fmt.Fprintf(writer, "%d ", value)
+			line = strconv.AppendUint(line, uint64(value), 10)
+			line = append(line, ' ')
+		}
+		line = append(line, '\n')
+		if _, err := writer.Write(line); err != nil {
+			return err
 		}
-		fmt.Fprintln(writer)
 	}
 
-	return nil
+	return writer.Flush()
 }
 
 // Inverser inverse les couleurs de l'image PGM.
 func (pgm *PGM) Invert() {
-	for i := 0; i < pgm.height; i++ {
-		for j := 0; j < pgm.width; j++ {
-			pgm.data[i][j] = uint8(pgm.max) - pgm.data[i][j]
-		}
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("invert")
+	for _, row := range pgm.data {
+		subtractFrom(row, uint8(pgm.max))
 	}
 }
 
 // Flip retourne l'image PGM horizontalement.
 func (pgm *PGM) Flip() {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("flip")
 	for i := 0; i < pgm.height; i++ {
 		for j := 0; j < pgm.width/2; j++ {
 			pgm.data[i][j], pgm.data[i][pgm.width-j-1] = pgm.data[i][pgm.width-j-1], pgm.data[i][j]
@@ -164,6 +205,9 @@ func (pgm *PGM) Flip() {
 
 // Flop fait basculer l'image PGM verticalement.
 func (pgm *PGM) Flop() {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.meta.record("flop")
 	for i := 0; i < pgm.height/2; i++ {
 		for j := 0; j < pgm.width; j++ {
 			pgm.data[i][j], pgm.data[pgm.height-i-1][j] = pgm.data[pgm.height-i-1][j], pgm.data[i][j]
@@ -171,7 +215,17 @@ func (pgm *PGM) Flop() {
 	}
 }
 
-// SetMagicNumber définit le nombre magique de l'image PGM.
+// MagicNumber renvoie le nombre magique de l'image PGM.
+func (pgm *PGM) MagicNumber() string {
+	return pgm.magicNumber
+}
+
+// MaxValue renvoie la valeur maximale de l'image PGM.
+func (pgm *PGM) MaxValue() int {
+	return pgm.max
+}
+
+// SetMagicNumber définit le nombre magique de l'image PGM : "P2" ou "P5".
 func (pgm *PGM) SetMagicNumber(magicNumber string) {
 	pgm.magicNumber = magicNumber
 }
@@ -183,6 +237,10 @@ func (pgm *PGM) SetMaxValue(maxValue uint8) {
 
 // Rotate90CW fait pivoter l'image PGM de 90° dans le sens des aiguilles d'une montre.
 func (pgm *PGM) Rotate90CW() {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.detachViews()
+	pgm.meta.record("rotate 90")
 	rotatedData := make([][]uint8, pgm.width)
 	for i := 0; i < pgm.width; i++ {
 		rotatedData[i] = make([]uint8, pgm.height)
@@ -198,147 +256,62 @@ func (pgm *PGM) Rotate90CW() {
 	pgm.width, pgm.height = pgm.height, pgm.width
 }
 
-// ToPBM convertit l'image PGM en PBM.
-func (pgm *PGM) ToPBM() *PBM {
-	pbmData := make([][]bool, pgm.height)
+// Rotate90CCW fait pivoter l'image PGM de 90° dans le sens inverse des aiguilles d'une montre.
+func (pgm *PGM) Rotate90CCW() {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.detachViews()
+	pgm.meta.record("rotate 270")
+	rotatedData := make([][]uint8, pgm.width)
+	for i := range rotatedData {
+		rotatedData[i] = make([]uint8, pgm.height)
+	}
+
 	for i := 0; i < pgm.height; i++ {
-		pbmData[i] = make([]bool, pgm.width)
 		for j := 0; j < pgm.width; j++ {
-			pbmData[i][j] = pgm.data[i][j] > uint8(pgm.max/2)
+			rotatedData[pgm.width-j-1][i] = pgm.data[i][j]
 		}
 	}
 
-	return &PBM{pbmData, pgm.width, pgm.height}
-}
-
-// PBM représente une image PBM.
-type PBM struct {
-	data          [][]bool
-	width, height int
+	pgm.data = rotatedData
+	pgm.width, pgm.height = pgm.height, pgm.width
 }
 
-// Save enregistre l'image PBM dans un fichier et renvoie une erreur en cas de problème.
-func (pbm *PBM) Save(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
+// Rotate180 fait pivoter l'image PGM d'un demi-tour.
+func (pgm *PGM) Rotate180() {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.own()
+	pgm.detachViews()
+	pgm.meta.record("rotate 180")
+	for i, j := 0, pgm.height-1; i < j; i, j = i+1, j-1 {
+		pgm.data[i], pgm.data[j] = pgm.data[j], pgm.data[i]
 	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
-
-	fmt.Fprintf(writer, "P1\n")
-	fmt.Fprintf(writer, "%d %d\n", pbm.width, pbm.height)
-
-	for _, row := range pbm.data {
-		for _, value := range row {
-			if value {
-				fmt.Fprintf(writer, "1 ")
-			} else {
-				fmt.Fprintf(writer, "0 ")
-			}
+	for _, row := range pgm.data {
+		for j, k := 0, len(row)-1; j < k; j, k = j+1, k-1 {
+			row[j], row[k] = row[k], row[j]
 		}
-		fmt.Fprintln(writer)
 	}
-
-	return nil
 }
 
-func main() {
-	// Exemple d'utilisation
-	pgm, err := ReadPGM("exemple.pgm")
-	if err != nil {
-		fmt.Println("Erreur lors de la lecture de l'image PGM:", err)
-		return
-	}
-	nouveauNombreMagique := "P2"
-	pgm.SetMagicNumber(nouveauNombreMagique)
-	fmt.Println()
-	fmt.Println("Nouveau nombre magique:", pgm.magicNumber)
-
-	width, height := pgm.Size()
-	fmt.Println()
-	fmt.Println("Taille de l'image:", width, height)
-
-	// Afficher la nouvelle valeur maximale
-	ValeurMax := uint8(10)
-	pgm.SetMaxValue(ValeurMax)
-	fmt.Println()
-	fmt.Println("La valeur maximale de l'image:", pgm.max)
-
-	x, y := 0, 9
-	pixelValue := pgm.At(x, y)
-	fmt.Println()
-	fmt.Printf("Valeur du pixel à la position (%d, %d): %d\n", x, y, pixelValue)
-
-	newPixelValue := uint8(4) // Nouvelle valeur que vous souhaitez définir
-	pgm.Set(x, y, newPixelValue)
-	fmt.Println()
-	fmt.Printf("Nouvelle valeur du pixel à la position (%d, %d): %d\n", x, y, pgm.At(x, y))
-
-	fmt.Println()
-	fmt.Println("Image normale :")
-	fmt.Println()
-	pgm.Display()
-	fmt.Println()
-
-	pgm.Invert()
-	fmt.Println("Image avec les couleurs inversé :")
-	fmt.Println()
-	pgm.Display()
-	fmt.Println()
-
-	pgm.Flip()
-	fmt.Println("Image retourner à l'horizontalement :")
-	fmt.Println()
-	pgm.Display()
-	fmt.Println()
-
-	pgm.Flop()
-	fmt.Println("Image renverser à la verticalement :")
-	fmt.Println()
-	pgm.Display()
-	fmt.Println()
-
-	pgm.Rotate90CW()
-	fmt.Println("Image tourné à 90° dans le sens des aiguilles d'une montre :")
-	fmt.Println()
-	pgm.Display()
-	fmt.Println()
-
-	// Sauvegarde de l'image modifiée
-	err = pgm.Save("image_modifiee.pgm")
-	if err != nil {
-		fmt.Println("Erreur lors de l'enregistrement de l'image PGM modifiée:", err)
-		return
+// NewPGM crée une image PGM noire de la taille donnée.
+func NewPGM(width, height, max int) *PGM {
+	data := make([][]uint8, height)
+	for i := range data {
+		data[i] = make([]uint8, width)
 	}
 
-	// Conversion en PBM
-	pbm := pgm.ToPBM()
-
-	// Affichage de l'image PBM
-	pbmWidth, pbmHeight := pbm.width, pbm.height
-	fmt.Println()
-	fmt.Println("Taille de l'image PBM:", pbmWidth, pbmHeight)
-	fmt.Println()
-	fmt.Println("Image PBM:")
-	fmt.Println()
-	for _, row := range pbm.data {
-		for _, value := range row {
-			if value {
-				fmt.Print("1 ")
-			} else {
-				fmt.Print("0 ")
-			}
+	return &PGM{data: data, width: width, height: height, magicNumber: "P2", max: max}
+}
+
+// ToPBM convertit l'image PGM en PBM.
+func (pgm *PGM) ToPBM() *PBM {
+	pbmData := make([][]bool, pgm.height)
+	for i := 0; i < pgm.height; i++ {
+		pbmData[i] = make([]bool, pgm.width)
+		for j := 0; j < pgm.width; j++ {
+			pbmData[i][j] = pgm.data[i][j] > uint8(pgm.max/2)
 		}
-		fmt.Println()
 	}
 
-	// Sauvegarde de l'image PBM
-	err = pbm.Save("image_pbm.pbm")
-	if err != nil {
-		fmt.Println("Erreur lors de l'enregistrement de l'image PBM:", err)
-		return
-	}
-}
\ No newline at end of file
+	return &PBM{data: pbmData, width: pgm.width, height: pgm.height, magicNumber: "P1", meta: pgm.meta.derive("convert P1")}
+}
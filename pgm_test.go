@@ -0,0 +1,81 @@
+package netpbm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testPGM() *PGM {
+	pgm := NewPGM(5, 3, 200)
+	for y, row := range pgm.data {
+		for x := range row {
+			// Les octets 10 et 32 (saut de ligne, espace) ne doivent pas être lus comme des séparateurs en P5.
+			row[x] = uint8([]int{0, 10, 32, 199, 200}[(x+y)%5])
+		}
+	}
+	return pgm
+}
+
+func assertSamePGM(t *testing.T, got, want *PGM) {
+	t.Helper()
+	if got.width != want.width || got.height != want.height || got.max != want.max {
+		t.Fatalf("image %dx%d (max %d) au lieu de %dx%d (max %d)", got.width, got.height, got.max, want.width, want.height, want.max)
+	}
+	for y := range want.data {
+		if !bytes.Equal(got.data[y], want.data[y]) {
+			t.Fatalf("ligne %d: %v au lieu de %v", y, got.data[y], want.data[y])
+		}
+	}
+}
+
+func TestPGMRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatP2, FormatP5} {
+		var buffer bytes.Buffer
+		if err := Write(&buffer, testPGM(), format); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		decoded, err := DecodePGM(&buffer)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		assertSamePGM(t, decoded, testPGM())
+		if decoded.MagicNumber() != format.MagicNumber() {
+			t.Errorf("nombre magique %s au lieu de %s", decoded.MagicNumber(), format.MagicNumber())
+		}
+
+		// Encode réécrit l'image dans son format d'origine.
+		buffer.Reset()
+		if err := decoded.Encode(&buffer); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		again, err := DecodePGM(&buffer)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		assertSamePGM(t, again, testPGM())
+	}
+}
+
+func TestDecodePGMTruncatedP5(t *testing.T) {
+	if _, err := DecodePGM(bytes.NewReader([]byte("P5\n2 2\n255\n\x01\x02\x03"))); err == nil {
+		t.Error("fichier P5 tronqué accepté")
+	}
+}
+
+func TestReadImageP5(t *testing.T) {
+	name := t.TempDir() + "/image.pgm"
+	pgm := testPGM()
+	pgm.SetMagicNumber("P5")
+	if err := pgm.Save(name); err != nil {
+		t.Fatal(err)
+	}
+	img, err := ReadImage(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, ok := img.(*PGM)
+	if !ok {
+		t.Fatalf("ReadImage a renvoyé %T", img)
+	}
+	assertSamePGM(t, decoded, testPGM())
+}
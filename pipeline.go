@@ -0,0 +1,119 @@
+package netpbm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Recipe décrit une chaîne d'opérations reproductible, lue depuis un fichier JSON, par exemple :
+//
+//	{
+//	  "format": "P6",
+//	  "steps": [
+//	    {"op": "trim", "tolerance": 8},
+//	    {"op": "resize", "width": 800, "height": 600},
+//	    {"op": "gamma", "value": 2.2}
+//	  ]
+//	}
+//
+// Les opérations sont cherchées par leur nom parmi celles enregistrées avec Register. Le format
+// de sortie est facultatif : par défaut, celui de l'image lue.
+type Recipe struct {
+	Format string `json:"format"`
+	Steps  []Step `json:"steps"`
+}
+
+// Step est une opération de la recette : son nom et ses paramètres numériques.
+type Step struct {
+	Op     string
+	Params Params
+}
+
+// UnmarshalJSON lit une étape de la forme {"op": "nom", "paramètre": valeur, ...}.
+func (step *Step) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(fields["op"], &step.Op); err != nil || step.Op == "" {
+		return fmt.Errorf("étape sans opération: %s", data)
+	}
+	delete(fields, "op")
+
+	step.Params = make(Params, len(fields))
+	for name, raw := range fields {
+		var value float64
+		if err := json.Unmarshal(raw, &value); err != nil {
+			// Les booléens sont acceptés pour les options.
+			var flag bool
+			if json.Unmarshal(raw, &flag) != nil {
+				return fmt.Errorf("paramètre %s invalide pour %s: %s", name, step.Op, raw)
+			}
+			value = float64(boolToInt(flag))
+		}
+		step.Params[name] = value
+	}
+	return nil
+}
+
+// ParseRecipe lit une recette au format JSON.
+func ParseRecipe(data []byte) (*Recipe, error) {
+	var recipe Recipe
+	if err := json.Unmarshal(data, &recipe); err != nil {
+		return nil, fmt.Errorf("recette invalide: %v", err)
+	}
+	if recipe.Format != "" {
+		if _, err := ParseFormat(strings.ToUpper(recipe.Format)); err != nil {
+			return nil, err
+		}
+	}
+	// Vérifier dès la lecture que toutes les opérations existent et que leurs paramètres sont valides.
+	for i, step := range recipe.Steps {
+		if _, err := NewOp(step.Op, step.Params); err != nil {
+			return nil, fmt.Errorf("étape %d: %v", i+1, err)
+		}
+	}
+	return &recipe, nil
+}
+
+// LoadRecipe lit une recette depuis un fichier JSON.
+func LoadRecipe(filename string) (*Recipe, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRecipe(data)
+}
+
+// Apply exécute les étapes de la recette sur l'image et renvoie le résultat.
+// L'image d'origine peut être modifiée par les opérations en place.
+func (recipe *Recipe) Apply(img Image) (Image, error) {
+	for i, step := range recipe.Steps {
+		op, err := NewOp(step.Op, step.Params)
+		if err != nil {
+			return nil, fmt.Errorf("étape %d: %v", i+1, err)
+		}
+		if img, err = op.Apply(img); err != nil {
+			return nil, fmt.Errorf("étape %d (%s): %v", i+1, step.Op, err)
+		}
+	}
+	return img, nil
+}
+
+// Run lit l'image input, lui applique la recette et écrit le résultat dans output.
+func (recipe *Recipe) Run(input, output string) error {
+	img, err := ReadImage(input)
+	if err != nil {
+		return err
+	}
+	format := originalFormat(img)
+	if recipe.Format != "" {
+		format, _ = ParseFormat(strings.ToUpper(recipe.Format))
+	}
+	if img, err = recipe.Apply(img); err != nil {
+		return err
+	}
+	return SaveImage(output, img, EncodeOptions{Format: format})
+}
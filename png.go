@@ -0,0 +1,86 @@
+package netpbm
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+)
+
+// bitonalPalette est la palette des images PBM encodées en PNG : le PNG obtenu est à 1 bit par pixel.
+var bitonalPalette = color.Palette{color.Gray{Y: 0}, color.Gray{Y: 255}}
+
+// encodePNG écrit l'image au format PNG : en couleurs pour une PPM, en niveaux de gris pour une
+// PGM et avec une palette noir et blanc pour une PBM. Les valeurs sont ramenées sur 8 bits.
+func encodePNG(w io.Writer, img Image) error {
+	if pbm, ok := img.(*PBM); ok {
+		paletted := image.NewPaletted(image.Rect(0, 0, pbm.width, pbm.height), bitonalPalette)
+		for y, row := range pbm.data {
+			for x, black := range row {
+				if !black {
+					paletted.SetColorIndex(x, y, 1)
+				}
+			}
+		}
+		return png.Encode(w, paletted)
+	}
+	std, err := toStdImage(img)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, std)
+}
+
+// savePNG enregistre l'image dans un fichier PNG.
+func savePNG(filename string, img Image) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := encodePNG(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// EncodePNG écrit l'image PPM dans w au format PNG, lisible par les navigateurs.
+func (ppm *PPM) EncodePNG(w io.Writer) error {
+	return encodePNG(w, ppm)
+}
+
+// SavePNG enregistre l'image PPM dans un fichier PNG.
+func (ppm *PPM) SavePNG(filename string) error {
+	return savePNG(filename, ppm)
+}
+
+// EncodePNG écrit l'image PGM dans w au format PNG, en niveaux de gris.
+func (pgm *PGM) EncodePNG(w io.Writer) error {
+	return encodePNG(w, pgm)
+}
+
+// SavePNG enregistre l'image PGM dans un fichier PNG.
+func (pgm *PGM) SavePNG(filename string) error {
+	return savePNG(filename, pgm)
+}
+
+// EncodePNG écrit l'image PBM dans w au format PNG, à 1 bit par pixel.
+func (pbm *PBM) EncodePNG(w io.Writer) error {
+	return encodePNG(w, pbm)
+}
+
+// SavePNG enregistre l'image PBM dans un fichier PNG.
+func (pbm *PBM) SavePNG(filename string) error {
+	return savePNG(filename, pbm)
+}
+
+// FromPNG lit une image PNG et la convertit en image PPM de valeur maximale maxValue (voir FromImage).
+func FromPNG(r io.Reader, maxValue int) (*PPM, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("PNG illisible: %v", err)
+	}
+	return FromImage(img, maxValue)
+}
@@ -0,0 +1,53 @@
+package netpbm
+
+import "fmt"
+
+// Polarity est la convention qui donne le sens des valeurs booléennes échangées avec une image PBM
+// par At, Set et SetPolarity. Dans le fichier, un bit à 1 est toujours un pixel noir (c'est la norme
+// PBM) ; en mémoire aussi, et seule l'interprétation des valeurs échangées change, si bien que les
+// lecteurs, les écrivains et les conversions n'inversent jamais une image par erreur.
+type Polarity int
+
+const (
+	// BlackIsTrue est la convention de la norme PBM : true est un pixel noir (l'encre).
+	BlackIsTrue Polarity = iota
+	// WhiteIsTrue est la convention inverse, celle des masques où true désigne le fond blanc.
+	WhiteIsTrue
+)
+
+// String renvoie le nom de la convention.
+func (polarity Polarity) String() string {
+	switch polarity {
+	case BlackIsTrue:
+		return "noir=true"
+	case WhiteIsTrue:
+		return "blanc=true"
+	}
+	return fmt.Sprintf("Polarity(%d)", int(polarity))
+}
+
+// black renvoie si la valeur, exprimée dans la convention, désigne un pixel noir.
+func (polarity Polarity) black(value bool) bool {
+	return value != (polarity == WhiteIsTrue)
+}
+
+// Polarity renvoie la convention de l'image PBM.
+func (pbm *PBM) Polarity() Polarity {
+	return pbm.polarity
+}
+
+// SetPolarity change la convention dans laquelle At et Set expriment les pixels. Les pixels
+// eux-mêmes ne changent pas : une image noire reste noire.
+func (pbm *PBM) SetPolarity(polarity Polarity) {
+	pbm.polarity = polarity
+}
+
+// IsBlack indique si le pixel (x, y) est noir, quelle que soit la convention de l'image.
+func (pbm *PBM) IsBlack(x, y int) bool {
+	return pbm.data[y][x]
+}
+
+// IsWhite indique si le pixel (x, y) est blanc, quelle que soit la convention de l'image.
+func (pbm *PBM) IsWhite(x, y int) bool {
+	return !pbm.data[y][x]
+}
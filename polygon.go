@@ -0,0 +1,111 @@
+package netpbm
+
+import "fmt"
+
+// FillRule est la règle qui décide si un point est à l'intérieur d'un polygone dont les côtés se
+// croisent ou s'emboîtent.
+type FillRule int
+
+const (
+	FillEvenOdd FillRule = iota // Intérieur si une demi-droite partant du point coupe un nombre impair de côtés
+	FillNonZero                 // Intérieur si le polygone tourne autour du point (indice non nul)
+)
+
+// String renvoie le nom de la règle de remplissage.
+func (rule FillRule) String() string {
+	switch rule {
+	case FillEvenOdd:
+		return "pair-impair"
+	case FillNonZero:
+		return "non nul"
+	}
+	return fmt.Sprintf("FillRule(%d)", int(rule))
+}
+
+// Winding est le sens de parcours d'un polygone, tel qu'il apparaît à l'écran (axe Y vers le bas).
+type Winding int
+
+const (
+	WindingNone             Winding = iota // Polygone plat ou de moins de trois sommets
+	WindingClockwise                       // Sens des aiguilles d'une montre
+	WindingCounterClockwise                // Sens inverse des aiguilles d'une montre
+)
+
+// String renvoie le nom du sens de parcours.
+func (winding Winding) String() string {
+	switch winding {
+	case WindingNone:
+		return "aucun"
+	case WindingClockwise:
+		return "horaire"
+	case WindingCounterClockwise:
+		return "antihoraire"
+	}
+	return fmt.Sprintf("Winding(%d)", int(winding))
+}
+
+// doubleSignedArea renvoie le double de l'aire algébrique du polygone (formule du lacet), positive
+// si le polygone est parcouru dans le sens des aiguilles d'une montre à l'écran.
+func doubleSignedArea(points []Point) int {
+	area := 0
+	for i, p := range points {
+		q := points[(i+1)%len(points)]
+		area += p.X*q.Y - q.X*p.Y
+	}
+	return area
+}
+
+// PolygonArea renvoie l'aire du polygone fermé, en pixels carrés, quel que soit son sens de parcours.
+// Les parties d'un polygone croisé parcourues en sens inverse se retranchent des autres.
+func PolygonArea(points []Point) float64 {
+	return float64(abs(doubleSignedArea(points))) / 2
+}
+
+// PolygonWinding renvoie le sens de parcours du polygone fermé.
+func PolygonWinding(points []Point) Winding {
+	switch area := doubleSignedArea(points); {
+	case area > 0:
+		return WindingClockwise
+	case area < 0:
+		return WindingCounterClockwise
+	}
+	return WindingNone
+}
+
+// onSegment indique si p est sur le segment [a, b].
+func onSegment(p, a, b Point) bool {
+	if (b.X-a.X)*(p.Y-a.Y) != (p.X-a.X)*(b.Y-a.Y) {
+		return false
+	}
+	return p.X >= min(a.X, b.X) && p.X <= max(a.X, b.X) && p.Y >= min(a.Y, b.Y) && p.Y <= max(a.Y, b.Y)
+}
+
+// PointInPolygon indique si p est à l'intérieur du polygone fermé, selon la règle de remplissage
+// donnée ; un point situé sur un côté est considéré à l'intérieur. Les côtés coupés sont ceux retenus
+// par DrawFilledPolygon, et le calcul se fait en entiers, sans erreur d'arrondi.
+func PointInPolygon(p Point, points []Point, rule FillRule) bool {
+	if len(points) < 3 {
+		return false
+	}
+	crossings, winding := 0, 0
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		if onSegment(p, a, b) {
+			return true
+		}
+		// Le côté coupe-t-il la demi-droite horizontale partant de p vers la droite ?
+		if (a.Y > p.Y) == (b.Y > p.Y) {
+			continue
+		}
+		side := (b.X-a.X)*(p.Y-a.Y) - (p.X-a.X)*(b.Y-a.Y)
+		if b.Y > a.Y && side > 0 {
+			crossings, winding = crossings+1, winding+1
+		} else if b.Y < a.Y && side < 0 {
+			crossings, winding = crossings+1, winding-1
+		}
+	}
+	if rule == FillNonZero {
+		return winding != 0
+	}
+	return crossings%2 == 1
+}
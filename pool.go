@@ -0,0 +1,125 @@
+package netpbm
+
+import "sync"
+
+// ImagePool réutilise les tampons de pixels d'images de même taille, pour les boucles qui créent
+// et abandonnent de nombreuses images temporaires (images intermédiaires, trames successives d'une
+// vidéo). Une image obtenue par Get est remise à zéro, comme si elle sortait de NewPPM, NewPGM ou
+// NewPBM ; une fois rendue par Put, elle ne doit plus être utilisée. Un ImagePool peut être
+// partagé entre plusieurs goroutines.
+type ImagePool struct {
+	mu    sync.Mutex
+	pools map[poolKey]*sync.Pool
+}
+
+// poolKey identifie une catégorie d'images interchangeables.
+type poolKey struct {
+	kind          byte // 'b', 'g' ou 'p'
+	width, height int
+}
+
+// NewImagePool crée un réservoir d'images vide.
+func NewImagePool() *ImagePool {
+	return &ImagePool{pools: make(map[poolKey]*sync.Pool)}
+}
+
+// pool renvoie le réservoir des images de la catégorie key.
+func (pool *ImagePool) pool(key poolKey) *sync.Pool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if pool.pools == nil {
+		pool.pools = make(map[poolKey]*sync.Pool)
+	}
+	p, ok := pool.pools[key]
+	if !ok {
+		p = &sync.Pool{}
+		pool.pools[key] = p
+	}
+	return p
+}
+
+// isShared indique si des lignes sont partagées avec une copie : l'image ne peut alors pas être recyclée.
+func isShared(shared []bool) bool {
+	for _, s := range shared {
+		if s {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPPM renvoie une image PPM noire de la taille donnée, recyclée si possible.
+func (pool *ImagePool) GetPPM(width, height, max int) *PPM {
+	ppm, ok := pool.pool(poolKey{'p', width, height}).Get().(*PPM)
+	if !ok {
+		return NewPPM(width, height, max)
+	}
+	for _, row := range ppm.data {
+		for x, pixel := range row {
+			if len(pixel) != 3 {
+				row[x] = make([]uint8, 3)
+				continue
+			}
+			pixel[0], pixel[1], pixel[2] = 0, 0, 0
+		}
+	}
+	ppm.magicNumber, ppm.max = "P3", max
+	ppm.changes, ppm.meta = changeTracker{}, Metadata{}
+	return ppm
+}
+
+// PutPPM rend une image PPM au réservoir. Les images dont des lignes sont encore partagées avec une
+// copie (voir Copy) ou une vue (voir SubImage) sont ignorées.
+func (pool *ImagePool) PutPPM(ppm *PPM) {
+	if ppm == nil || isShared(ppm.shared) || isShared(ppm.viewed) || len(ppm.data) != ppm.height {
+		return
+	}
+	ppm.shared = nil
+	pool.pool(poolKey{'p', ppm.width, ppm.height}).Put(ppm)
+}
+
+// GetPGM renvoie une image PGM noire de la taille donnée, recyclée si possible.
+func (pool *ImagePool) GetPGM(width, height, max int) *PGM {
+	pgm, ok := pool.pool(poolKey{'g', width, height}).Get().(*PGM)
+	if !ok {
+		return NewPGM(width, height, max)
+	}
+	for _, row := range pgm.data {
+		clear(row)
+	}
+	pgm.magicNumber, pgm.max = "P2", max
+	pgm.changes, pgm.meta = changeTracker{}, Metadata{}
+	return pgm
+}
+
+// PutPGM rend une image PGM au réservoir.
+func (pool *ImagePool) PutPGM(pgm *PGM) {
+	if pgm == nil || isShared(pgm.shared) || isShared(pgm.viewed) || len(pgm.data) != pgm.height {
+		return
+	}
+	pgm.shared = nil
+	pool.pool(poolKey{'g', pgm.width, pgm.height}).Put(pgm)
+}
+
+// GetPBM renvoie une image PBM blanche de la taille donnée, recyclée si possible.
+func (pool *ImagePool) GetPBM(width, height int) *PBM {
+	pbm, ok := pool.pool(poolKey{'b', width, height}).Get().(*PBM)
+	if !ok {
+		return NewPBM(width, height)
+	}
+	for _, row := range pbm.data {
+		clear(row)
+	}
+	pbm.magicNumber = "P1"
+	pbm.changes, pbm.meta, pbm.polarity = changeTracker{}, Metadata{}, BlackIsTrue
+	return pbm
+}
+
+// PutPBM rend une image PBM au réservoir.
+func (pool *ImagePool) PutPBM(pbm *PBM) {
+	if pbm == nil || isShared(pbm.shared) || isShared(pbm.viewed) || len(pbm.data) != pbm.height {
+		return
+	}
+	pbm.shared = nil
+	pool.pool(poolKey{'b', pbm.width, pbm.height}).Put(pbm)
+}
@@ -1,11 +1,11 @@
-package main
+package netpbm
 
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
-	"strings"
 )
 
 // PPM représente une image PPM.
@@ -14,6 +14,15 @@ type PPM struct {
 	width, height int
 	magicNumber   string
 	max           int
+	shared        []bool        // Lignes partagées avec une copie (voir Copy)
+	viewed        []bool        // Lignes dont les pixels sont aussi ceux d'une vue (voir SubImage)
+	changes       changeTracker // Zones modifiées (voir Dirty)
+	meta          Metadata      // Métadonnées de l'en-tête (voir Metadata)
+	clips         []clipRegion  // Zones de découpe empilées (voir PushClipRect)
+	transform     *Homography   // Repère du dessin, nil pour celui de l'image (voir Translate)
+	states        []drawState   // États mémorisés par SaveState
+	symmetry      []Homography  // Copies de chaque dessin (voir SetMirrorSymmetry)
+	repeating     bool          // Une copie symétrique est en cours de dessin
 }
 
 type Pixel struct {
@@ -30,7 +39,8 @@ func (ppm *PPM) Display() {
 	}
 }
 
-// ReadPPM lit une image PPM à partir d'un fichier et renvoie une structure qui représente l'image.
+// ReadPPM lit une image PPM, ASCII (P3) ou binaire (P6), à partir d'un fichier et renvoie une
+// structure qui représente l'image.
 func ReadPPM(filename string) (*PPM, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -38,73 +48,92 @@ func ReadPPM(filename string) (*PPM, error) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	var magicNumber string
-	var width, height, max int
-
-	// Lire les informations d'en-tête PPM.
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "#") {
-			// Ignorer les commentaires
-			continue
-		}
-		if magicNumber == "" {
-			magicNumber = line
-			if magicNumber != "P3" {
-				return nil, fmt.Errorf("format PPM non pris en charge: %s", magicNumber)
-			}
-		} else {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				width, _ = strconv.Atoi(fields[0])
-				height, _ = strconv.Atoi(fields[1])
-				break // Sortir après avoir lu les dimensions
-			}
-		}
-	}
-
-	if width == 0 || height == 0 {
-		return nil, fmt.Errorf("dimensions de l'image non spécifiées")
+	scanner, err := scanFile(file, DefaultDecodeOptions)
+	if err != nil {
+		return nil, err
 	}
+	return decodePPM(scanner)
+}
 
-	// Lire la valeur maximale
-	scanner.Scan()
-	max, _ = strconv.Atoi(scanner.Text())
+// DecodePPM lit une image PPM à partir de r (connexion réseau, fichier embarqué, tube, tampon en
+// mémoire…), comme ReadPPM le fait d'un fichier. r est lu en flux, par blocs.
+func DecodePPM(r io.Reader) (*PPM, error) {
+	return decodePPM(newSampleScanner(r, DefaultDecodeOptions.bufferSize()))
+}
 
-	data := make([][][]uint8, height)
-	for i := 0; i < height; i++ {
-		data[i] = make([][]uint8, width)
+// decodePPM lit une image PPM à travers scanner.
+func decodePPM(scanner *sampleScanner) (*PPM, error) {
+	// Les champs sont lus octet par octet (voir sampleScanner) : les échantillons peuvent être
+	// répartis librement sur les lignes du fichier.
+	magicNumber, err := scanner.token()
+	if err != nil {
+		return nil, fmt.Errorf("en-tête illisible: %v", err)
+	}
+	if magicNumber != "P3" && magicNumber != "P6" {
+		return nil, fmt.Errorf("format PPM non pris en charge: %s", magicNumber)
+	}
+	width, height, max, err := scanner.dimensions(true)
+	if err != nil {
+		return nil, err
+	}
+	if err := check8Bits(max); err != nil {
+		return nil, err
 	}
 
-	// Lire les données de l'image.
-	for i := 0; i < height; i++ {
-		scanner.Scan()
-		line := scanner.Text()
-		if len(line) == 0 {
-			return nil, fmt.Errorf("ligne vide à la position %d", i+2) // +2 pour compenser le 0-indexing et le fait que la première ligne est le chiffre magique "P3"
-		}
+	ppm := NewPPM(width, height, max)
+	ppm.magicNumber = magicNumber
+	if magicNumber == "P6" {
+		err = ppm.readRaw(scanner)
+	} else {
+		err = ppm.readPlain(scanner)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		values := strings.Fields(line)
+	for _, comment := range scanner.comments {
+		ppm.meta.parseComment(comment)
+	}
+	if err := verifyChecksum(ppm); err != nil {
+		return nil, err
+	}
+	return ppm, nil
+}
 
-		if len(values) < width*3 {
-			return nil, fmt.Errorf("nombre insuffisant de valeurs sur la ligne %d, valeurs trouvées: %v", i+2, values) // +2 pour compenser le 0-indexing et le fait que la première ligne est le chiffre magique "P3"
+// readPlain lit les pixels d'un fichier P3, en décimal.
+func (ppm *PPM) readPlain(scanner *sampleScanner) error {
+	for i, row := range ppm.data {
+		for _, pixel := range row {
+			for c := range pixel {
+				value, err := scanner.next()
+				if err != nil {
+					return fmt.Errorf("ligne %d de l'image: %v", i+1, err)
+				}
+				if value > ppm.max {
+					return fmt.Errorf("ligne %d de l'image: valeur %d supérieure au maximum %d", i+1, value, ppm.max)
+				}
+				pixel[c] = uint8(value)
+			}
 		}
+	}
+	return nil
+}
 
-		// S'assurer que le tableau a une taille suffisante
-		if len(data[i]) < width {
-			data[i] = make([][]uint8, width)
+// readRaw lit les pixels d'un fichier P6, trois octets par pixel.
+func (ppm *PPM) readRaw(scanner *sampleScanner) error {
+	if err := scanner.endHeader(); err != nil {
+		return err
+	}
+	line := make([]byte, 3*ppm.width)
+	for i, row := range ppm.data {
+		if err := scanner.read(line); err != nil {
+			return fmt.Errorf("ligne %d de l'image: %v", i+1, err)
 		}
-
-		for j := 0; j < width; j++ {
-			r, _ := strconv.Atoi(values[j*3])
-			g, _ := strconv.Atoi(values[j*3+1])
-			b, _ := strconv.Atoi(values[j*3+2])
-			data[i][j] = []uint8{uint8(r), uint8(g), uint8(b)}
+		for j, pixel := range row {
+			copy(pixel, line[3*j:])
 		}
 	}
-
-	return &PPM{data, width, height, magicNumber, max}, nil
+	return nil
 }
 
 // Size renvoie la largeur et la hauteur de l'image.
@@ -112,49 +141,77 @@ func (ppm *PPM) Size() (int, int) {
 	return ppm.width, ppm.height
 }
 
-// At renvoie la valeur du pixel en (x, y).
+// At renvoie la valeur du pixel en (x, y). C'est une copie : les lignes pouvant être partagées
+// avec une copie de l'image (voir Copy), la modifier n'a aucun effet ; il faut passer par Set.
 func (ppm *PPM) At(x, y int) []uint8 {
-	return ppm.data[y][x]
+	return append([]uint8(nil), ppm.data[y][x]...)
 }
 
-// / Save enregistre l'image PPM dans un fichier et renvoie une erreur en cas de problème.
+// / Save enregistre l'image PPM dans un fichier et renvoie une erreur en cas de problème. Le format,
+// P3 (ASCII) ou P6 (binaire), est celui du nombre magique de l'image (voir SetMagicNumber).
 func (ppm *PPM) Save(filename string) error {
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
+	if err := ppm.Encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
 
+// Encode écrit l'image PPM dans w, exactement comme Save l'écrit dans un fichier.
+func (ppm *PPM) Encode(w io.Writer) error {
+	writer := bufio.NewWriter(w)
 	fmt.Fprintf(writer, "%s\n", ppm.magicNumber)
 	fmt.Fprintf(writer, "%d %d\n", ppm.width, ppm.height)
 	fmt.Fprintf(writer, "%d\n", ppm.max)
 
+	// Chaque ligne est formatée dans un tampon réutilisé, sans allocation par pixel : en binaire
+	// pour P6, en décimal sinon.
+	line := make([]byte, 0, 12*ppm.width+1)
 	for _, row := range ppm.data {
+		line = line[:0]
 		for _, pixel := range row {
-			fmt.Fprintf(writer, "%d %d %d ", pixel[0], pixel[1], pixel[2])
+			if ppm.magicNumber == "P6" {
+				line = append(line, pixel[:3]...)
+				continue
+			}
+			for k := 0; k < 3; k++ {
+				line = strconv.AppendUint(line, uint64(pixel[k]), 10)
+				line = append(line, ' ')
+			}
+		}
+		if ppm.magicNumber != "P6" {
+			line = append(line, '\n')
+		}
+		if _, err := writer.Write(line); err != nil {
+			return err
 		}
-		fmt.Fprintln(writer)
 	}
 
-	return nil
+	return writer.Flush()
 }
 
 // Inverser inverse les couleurs de l'image PPM.
 func (ppm *PPM) Invert() {
-	for i := 0; i < ppm.height; i++ {
-		for j := 0; j < ppm.width; j++ {
-			for k := 0; k < 3; k++ {
-				ppm.data[i][j][k] = uint8(ppm.max) - ppm.data[i][j][k]
-			}
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("invert")
+	m := uint8(ppm.max)
+	for _, row := range ppm.data {
+		for _, pixel := range row {
+			pixel[0], pixel[1], pixel[2] = m-pixel[0], m-pixel[1], m-pixel[2]
 		}
 	}
 }
 
 // Flip retourne l'image PPM horizontalement.
 func (ppm *PPM) Flip() {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("flip")
 	for i := 0; i < ppm.height; i++ {
 		for j := 0; j < ppm.width/2; j++ {
 			ppm.data[i][j], ppm.data[i][ppm.width-j-1] = ppm.data[i][ppm.width-j-1], ppm.data[i][j]
@@ -164,6 +221,9 @@ func (ppm *PPM) Flip() {
 
 // Flop fait basculer l'image PPM verticalement.
 func (ppm *PPM) Flop() {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.meta.record("flop")
 	for i := 0; i < ppm.height/2; i++ {
 		for j := 0; j < ppm.width; j++ {
 			ppm.data[i][j], ppm.data[ppm.height-i-1][j] = ppm.data[ppm.height-i-1][j], ppm.data[i][j]
@@ -171,7 +231,18 @@ func (ppm *PPM) Flop() {
 	}
 }
 
-// SetMagicNumber définit le nombre magique de l'image PPM.
+// MagicNumber renvoie le nombre magique de l'image PPM.
+func (ppm *PPM) MagicNumber() string {
+	return ppm.magicNumber
+}
+
+// MaxValue renvoie la valeur maximale de l'image PPM.
+func (ppm *PPM) MaxValue() int {
+	return ppm.max
+}
+
+// SetMagicNumber définit le nombre magique de l'image PPM : "P3" ou "P6", il choisit la variante
+// ASCII ou binaire écrite par Save.
 func (ppm *PPM) SetMagicNumber(magicNumber string) {
 	ppm.magicNumber = magicNumber
 }
@@ -183,6 +254,10 @@ func (ppm *PPM) SetMaxValue(maxValue uint8) {
 
 // Rotate90CW fait pivoter l'image PPM de 90° dans le sens des aiguilles d'une montre.
 func (ppm *PPM) Rotate90CW() {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.detachViews()
+	ppm.meta.record("rotate 90")
 	rotatedData := make([][][]uint8, ppm.width)
 	for i := 0; i < ppm.width; i++ {
 		rotatedData[i] = make([][]uint8, ppm.height)
@@ -198,11 +273,54 @@ func (ppm *PPM) Rotate90CW() {
 	ppm.width, ppm.height = ppm.height, ppm.width
 }
 
+// Rotate90CCW fait pivoter l'image PPM de 90° dans le sens inverse des aiguilles d'une montre.
+func (ppm *PPM) Rotate90CCW() {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.detachViews()
+	ppm.meta.record("rotate 270")
+	rotatedData := make([][][]uint8, ppm.width)
+	for i := range rotatedData {
+		rotatedData[i] = make([][]uint8, ppm.height)
+	}
+
+	for i := 0; i < ppm.height; i++ {
+		for j := 0; j < ppm.width; j++ {
+			rotatedData[ppm.width-j-1][i] = ppm.data[i][j]
+		}
+	}
+
+	ppm.data = rotatedData
+	ppm.width, ppm.height = ppm.height, ppm.width
+}
+
+// Rotate180 fait pivoter l'image PPM d'un demi-tour.
+func (ppm *PPM) Rotate180() {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	ppm.detachViews()
+	ppm.meta.record("rotate 180")
+	for i, j := 0, ppm.height-1; i < j; i, j = i+1, j-1 {
+		ppm.data[i], ppm.data[j] = ppm.data[j], ppm.data[i]
+	}
+	for _, row := range ppm.data {
+		for j, k := 0, len(row)-1; j < k; j, k = j+1, k-1 {
+			row[j], row[k] = row[k], row[j]
+		}
+	}
+}
+
 // Point représente un point dans l'image.
 type Point struct {
 	X, Y int
 }
 
+// Rect représente une zone rectangulaire de l'image : son coin supérieur gauche et sa taille.
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
 // Fonction utilitaire abs pour obtenir la valeur absolue d'un nombre entier.
 func abs(x int) int {
 	if x < 0 {
@@ -213,6 +331,12 @@ func abs(x int) int {
 
 // DrawLine trace une ligne entre deux points.
 func (ppm *PPM) DrawLine(p1, p2 Point, couleur Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	if ppm.repeatSymmetric(func() { ppm.DrawLine(p1, p2, couleur) }) {
+		return
+	}
+	p1, p2 = ppm.toDevice(p1), ppm.toDevice(p2)
+
 	x1, y1 := p1.X, p1.Y
 	x2, y2 := p2.X, p2.Y
 
@@ -233,10 +357,7 @@ func (ppm *PPM) DrawLine(p1, p2 Point, couleur Pixel) {
 
 	for i := 0; i <= steps; i++ {
 		// Convertir les coordonnées en entiers et vérifier les limites
-		xInt, yInt := int(x), int(y)
-		if xInt >= 0 && xInt < ppm.width && yInt >= 0 && yInt < ppm.height {
-			ppm.Set(xInt, yInt, []uint8{couleur.Red, couleur.Green, couleur.Blue})
-		}
+		ppm.setPixel(int(x), int(y), couleur)
 		x += xInc
 		y += yInc
 	}
@@ -252,15 +373,21 @@ func (ppm *PPM) Set(x, y int, value []uint8) {
 	}
 
 	// Assurez-vous que ppm.data[y][x] a une longueur suffisante
-	for len(ppm.data[y]) <= x {
-		ppm.data[y] = append(ppm.data[y], make([]uint8, 3))
+	row := ppm.writableRow(y)
+	for len(row) <= x {
+		row = append(row, make([]uint8, 3))
 	}
+	ppm.data[y] = row
 
-	ppm.data[y][x] = value
+	// La valeur est copiée, pour que l'appelant ne garde pas la main sur le pixel.
+	row[x] = append([]uint8(nil), value...)
+	ppm.changes.mark(Rect{X: x, Y: y, Width: 1, Height: 1})
 }
 
 // DrawTriangle dessine un triangle.
 func (ppm *PPM) DrawTriangle(p1, p2, p3 Point, couleur Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+
 	ppm.DrawLine(p1, p2, couleur)
 	ppm.DrawLine(p2, p3, couleur)
 	ppm.DrawLine(p3, p1, couleur)
@@ -313,6 +440,12 @@ func (ppm *PPM) drawLine(start, end Point, color Pixel) {
 
 // DrawFilledTriangle dessine un triangle rempli dans l'image PPM.
 func (ppm *PPM) DrawFilledTriangle(p1, p2, p3 Point, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	if ppm.repeatSymmetric(func() { ppm.DrawFilledTriangle(p1, p2, p3, color) }) {
+		return
+	}
+	p1, p2, p3 = ppm.toDevice(p1), ppm.toDevice(p2), ppm.toDevice(p3)
+
 	// Utiliser l'algorithme de tracé de ligne pour dessiner les trois côtés du triangle.
 	ppm.drawFilledLine(p1, p2, color)
 	ppm.drawFilledLine(p2, p3, color)
@@ -367,6 +500,12 @@ func (ppm *PPM) drawFilledLine(start, end Point, color Pixel) {
 
 // DrawPolygon dessine un polygone dans l'image PPM.
 func (ppm *PPM) DrawPolygon(points []Point, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	if ppm.repeatSymmetric(func() { ppm.DrawPolygon(points, color) }) {
+		return
+	}
+	points = ppm.toDevicePoints(points)
+
 	// Vérifier que la liste de points n'est pas vide.
 	if len(points) < 3 {
 		fmt.Println("Un polygone doit avoir au moins trois points.")
@@ -383,6 +522,12 @@ func (ppm *PPM) DrawPolygon(points []Point, color Pixel) {
 
 // DrawFilledPolygon dessine un polygone rempli dans l'image PPM.
 func (ppm *PPM) DrawFilledPolygon(points []Point, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	if ppm.repeatSymmetric(func() { ppm.DrawFilledPolygon(points, color) }) {
+		return
+	}
+	points = ppm.toDevicePoints(points)
+
 	// Vérifier que la liste de points n'est pas vide.
 	if len(points) < 3 {
 		fmt.Println("Un polygone rempli doit avoir au moins trois points.")
@@ -415,6 +560,17 @@ func (ppm *PPM) drawHorizontalLine(y, startX, endX int, color Pixel) {
 
 // DrawFilledRectangle dessine un rectangle rempli dans l'image PPM.
 func (ppm *PPM) DrawFilledRectangle(p1 Point, width, height int, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	if ppm.repeatSymmetric(func() { ppm.DrawFilledRectangle(p1, width, height, color) }) {
+		return
+	}
+
+	// Dans un repère transformé, le rectangle peut être tourné : il est rempli comme un tracé.
+	if ppm.transform != nil {
+		ppm.FillPath(rectPath(Rect{X: p1.X, Y: p1.Y, Width: width, Height: height}), FillNonZero, color)
+		return
+	}
+
 	// Vérifier que les coordonnées du point ne dépassent pas les dimensions de l'image.
 	if p1.X < 0 || p1.X >= ppm.width || p1.Y < 0 || p1.Y >= ppm.height {
 		fmt.Println("Les coordonnées du point sont hors des limites de l'image.")
@@ -434,15 +590,29 @@ func (ppm *PPM) DrawFilledRectangle(p1 Point, width, height int, color Pixel) {
 	}
 
 	// Dessiner le rectangle rempli.
+	ppm.changes.mark(Rect{X: p1.X, Y: p1.Y, Width: width, Height: height})
 	for i := p1.Y; i < p1.Y+height; i++ {
+		row := ppm.writableRow(i)
 		for j := p1.X; j < p1.X+width; j++ {
-			ppm.data[i][j] = []uint8{color.Red, color.Green, color.Blue}
+			if ppm.visible(j, i) {
+				row[j] = []uint8{color.Red, color.Green, color.Blue}
+			}
 		}
 	}
 }
 
 // DrawCircle dessine un cercle dans l'image PPM.
 func (ppm *PPM) DrawCircle(center Point, radius int, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	if ppm.repeatSymmetric(func() { ppm.DrawCircle(center, radius, color) }) {
+		return
+	}
+
+	if ppm.transform != nil {
+		ppm.strokeCircle(center, radius, color)
+		return
+	}
+
 	// Vérifier que les coordonnées du centre ne dépassent pas les dimensions de l'image.
 	if center.X < 0 || center.X >= ppm.width || center.Y < 0 || center.Y >= ppm.height {
 		fmt.Println("Les coordonnées du centre sont hors des limites de l'image.")
@@ -461,7 +631,17 @@ func (ppm *PPM) DrawCircle(center Point, radius int, color Pixel) {
 		return
 	}
 
-	// Dessiner le cercle en utilisant l'algorithme de tracé de cercle de Bresenham.
+	ppm.strokeCircle(center, radius, color)
+}
+
+// strokeCircle dessine un cercle par l'algorithme de tracé de cercle de Bresenham, en ignorant les
+// pixels hors de l'image.
+func (ppm *PPM) strokeCircle(center Point, radius int, color Pixel) {
+	// Dans un repère transformé, le cercle peut devenir une ellipse : il est tracé comme un tracé.
+	if ppm.transform != nil {
+		ppm.StrokePath(circlePath(center, float64(radius)), 1, CapButt, JoinRound, color)
+		return
+	}
 	x := radius
 	y := 0
 	decision := 1 - x
@@ -493,14 +673,25 @@ func (ppm *PPM) setCirclePixels(center Point, x, y int, color Pixel) {
 
 // setPixel définit la couleur d'un pixel dans le cercle.
 func (ppm *PPM) setPixel(x, y int, color Pixel) {
-	// Assurez-vous que les coordonnées sont dans les limites de l'image.
-	if x >= 0 && x < ppm.width && y >= 0 && y < ppm.height {
-		ppm.data[y][x] = []uint8{color.Red, color.Green, color.Blue}
+	// Assurez-vous que les coordonnées sont dans les limites de l'image et de la zone de découpe.
+	if ppm.visible(x, y) {
+		ppm.writableRow(y)[x] = []uint8{color.Red, color.Green, color.Blue}
+		ppm.changes.mark(Rect{X: x, Y: y, Width: 1, Height: 1})
 	}
 }
 
 // DrawFilledCircle dessine un cercle rempli dans l'image PPM.
 func (ppm *PPM) DrawFilledCircle(center Point, radius int, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	if ppm.repeatSymmetric(func() { ppm.DrawFilledCircle(center, radius, color) }) {
+		return
+	}
+
+	if ppm.transform != nil {
+		ppm.FillPath(circlePath(center, float64(radius)+0.5), FillNonZero, color)
+		return
+	}
+
 	// Vérifier que les coordonnées du centre ne dépassent pas les dimensions de l'image.
 	if center.X < 0 || center.X >= ppm.width || center.Y < 0 || center.Y >= ppm.height {
 		fmt.Println("Les coordonnées du centre sont hors des limites de l'image.")
@@ -599,194 +790,29 @@ func max(a, b int) int {
 	return b
 }
 
-// Copy crée une copie de l'image PPM.
-func (ppm *PPM) Copy() *PPM {
-	copyData := make([][][]uint8, ppm.height)
-	for i := range copyData {
-		copyData[i] = make([][]uint8, ppm.width)
-		for j := range copyData[i] {
-			copyData[i][j] = make([]uint8, 3)
-			copy(copyData[i][j], ppm.data[i][j])
+// NewPPM crée une image PPM noire de la taille donnée.
+func NewPPM(width, height, max int) *PPM {
+	// Les pixels d'une ligne sont découpés dans un même tampon contigu : une allocation par ligne.
+	data := make([][][]uint8, height)
+	for i := range data {
+		buffer := make([]uint8, 3*width)
+		data[i] = make([][]uint8, width)
+		for j := range data[i] {
+			data[i][j] = buffer[3*j : 3*j+3 : 3*j+3]
 		}
 	}
 
 	return &PPM{
-		data:        copyData,
-		width:       ppm.width,
-		height:      ppm.height,
-		magicNumber: ppm.magicNumber,
-		max:         ppm.max,
+		data:        data,
+		width:       width,
+		height:      height,
+		magicNumber: "P3",
+		max:         max,
 	}
 }
 
-func main() {
-	// Exemple d'utilisation
-	ppm, err := ReadPPM("exemple.ppm")
-	if err != nil {
-		fmt.Println("Erreur lors de la lecture de l'image PPM:", err)
-		return
-	}
-	nouveauNombreMagique := "P3"
-	ppm.SetMagicNumber(nouveauNombreMagique)
-	fmt.Println()
-	fmt.Println("Nouveau nombre magique:", ppm.magicNumber)
-
-	width, height := ppm.Size()
-	fmt.Println()
-	fmt.Println("Taille de l'image:", width, height)
-
-	x, y := 0, 9
-	pixelValue := ppm.At(x, y)
-	fmt.Println()
-	fmt.Printf("Valeur du pixel à la position (%d, %d): %v\n", x, y, pixelValue)
-
-	newPixelValue := []uint8{100, 150, 200} // Nouvelle valeur que vous souhaitez définir
-	ppm.Set(x, y, newPixelValue)
-	fmt.Println()
-	fmt.Printf("Nouvelle valeur du pixel à la position (%d, %d): %v\n", x, y, ppm.At(x, y))
-
-	fmt.Println()
-	fmt.Println("Image normale :")
-	fmt.Println()
-	ppm.Display()
-	fmt.Println()
-
-	ppm.Invert()
-	fmt.Println("Image avec les couleurs inversées :")
-	fmt.Println()
-	ppm.Display()
-	fmt.Println()
-
-	ppm.Flip()
-	fmt.Println("Image retournée horizontalement :")
-	fmt.Println()
-	ppm.Display()
-	fmt.Println()
-
-	ppm.Flop()
-	fmt.Println("Image renversée verticalement :")
-	fmt.Println()
-	ppm.Display()
-	fmt.Println()
-
-	ppm.Rotate90CW()
-	fmt.Println("Image tournée à 90° dans le sens des aiguilles d'une montre :")
-	fmt.Println()
-	ppm.Display()
-	fmt.Println()
-
-	// Création d'une nouvelle instance de Point avec de nouvelles valeurs
-	newPoint := Point{X: 3, Y: 5}
-	newPixel := []uint8{100, 150, 200}
-
-	// Utilisation de la nouvelle instance de Point pour définir un nouveau pixel
-	ppm.Set(newPoint.X, newPoint.Y, newPixel)
-	fmt.Printf("Nouvelle valeur du pixel à la position (%d, %d): %v\n", newPoint.X, newPoint.Y, ppm.At(newPoint.X, newPoint.Y))
-	fmt.Println()
-
-	point1 := Point{X: 10, Y: 10}
-	point2 := Point{X: 50, Y: 30}
-
-	couleurLigne := Pixel{Red: 255, Green: 0, Blue: 0} // Rouge
-
-	// Tracez une ligne entre les deux points
-	ppm.DrawLine(point1, point2, couleurLigne)
-	fmt.Println("ligne :")
-	fmt.Println()
-	ppm.Display()
-	fmt.Println()
-
-	// Couleur du rectangle
-	couleurRectangle := Pixel{Red: 0, Green: 0, Blue: 255} // Bleu
-
-	// Dessiner un rectangle rempli dans l'image PPM
-	ppm.DrawFilledRectangle(point1, width, height, couleurRectangle)
-
-	// Affichage de l'image avec le rectangle rempli
-	fmt.Println("Rectangle rempli :")
-	fmt.Println()
-	ppm.Display()
-	fmt.Println()
-
-	// Dessiner un triangle
-	p1 := Point{5, 5}
-	p2 := Point{10, 15}
-	p3 := Point{15, 5}
-	ppm.DrawTriangle(p1, p2, p3, Pixel{255, 0, 0})
-
-	// Affichage de l'image avec la ligne dessinée
-	fmt.Println("triangle:")
-	fmt.Println()
-	ppm.Display()
-
-	couleurTriangle := Pixel{Red: 0, Green: 255, Blue: 0} // Vert
-	ppm.DrawFilledTriangle(p1, p2, p3, couleurTriangle)
-
-	// Affichage de l'image avec le triangle rempli
-	fmt.Println("Triangle rempli :")
-	fmt.Println()
-	ppm.Display()
-	fmt.Println()
-
-	centreCercle := Point{X: 30, Y: 30}
-	rayonCercle := 15
-	couleurCercle := Pixel{Red: 255, Green: 0, Blue: 0} // Rouge
-	ppmCercle := ppm.Copy()                             // Créer une copie de l'image originale
-	ppmCercle.DrawCircle(centreCercle, rayonCercle, couleurCercle)
-
-	// Affichage de l'image avec le cercle
-	fmt.Println("Image avec le cercle :")
-	ppmCercle.Display()
-	fmt.Println()
-
-	// Dessiner un cercle rempli
-	centreCercleRempli := Point{X: 80, Y: 30}
-	rayonCercleRempli := 10
-	couleurCercleRempli := Pixel{Red: 0, Green: 255, Blue: 0} // Vert
-	ppmCercleRempli := ppm.Copy()                             // Créer une copie de l'image originale
-	ppmCercleRempli.DrawFilledCircle(centreCercleRempli, rayonCercleRempli, couleurCercleRempli)
-
-	// Affichage de l'image avec le cercle rempli
-	fmt.Println("Image avec le cercle rempli :")
-	ppmCercleRempli.Display()
-	fmt.Println()
-
-	// Dessiner un polygone
-	pointsPolygone := []Point{
-		{X: 10, Y: 60},
-		{X: 20, Y: 80},
-		{X: 30, Y: 60},
-		{X: 25, Y: 50},
-	}
-	couleurPolygone := Pixel{Red: 0, Green: 0, Blue: 255} // Bleu
-	ppmPolygone := ppm.Copy()                             // Créer une copie de l'image originale
-	ppmPolygone.DrawPolygon(pointsPolygone, couleurPolygone)
-
-	// Affichage de l'image avec le polygone
-	fmt.Println("Image avec le polygone :")
-	ppmPolygone.Display()
-	fmt.Println()
-
-	// Dessiner un polygone rempli
-	pointsPolygoneRempli := []Point{
-		{X: 60, Y: 60},
-		{X: 70, Y: 80},
-		{X: 80, Y: 60},
-		{X: 75, Y: 50},
-	}
-	couleurPolygoneRempli := Pixel{Red: 255, Green: 255, Blue: 0} // Jaune
-	ppmPolygoneRempli := ppm.Copy()                               // Créer une copie de l'image originale
-	ppmPolygoneRempli.DrawFilledPolygon(pointsPolygoneRempli, couleurPolygoneRempli)
-
-	// Affichage de l'image avec le polygone rempli
-	fmt.Println("Image avec le polygone rempli :")
-	ppmPolygoneRempli.Display()
-	fmt.Println()
-
-	// Sauvegarde de l'image modifiée
-	err = ppm.Save("image_modifiee.ppm")
-	if err != nil {
-		fmt.Println("Erreur lors de l'enregistrement de l'image PPM modifiée:", err)
-		return
-	}
-}
\ No newline at end of file
+// pixel renvoie la couleur du pixel en (x, y).
+func (ppm *PPM) pixel(x, y int) Pixel {
+	value := ppm.data[y][x]
+	return Pixel{value[0], value[1], value[2]}
+}
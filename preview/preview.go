@@ -0,0 +1,336 @@
+// Package preview affiche une image PPM ou PGM dans une fenêtre de navigateur et la met à jour en
+// direct pendant que le programme la modifie, pour mettre au point du code de dessin sans
+// enregistrer puis ouvrir des fichiers à chaque essai. Il est séparé du paquet netpbm pour que
+// celui-ci ne dépende ni de net/http ni du lancement d'un navigateur :
+//
+//	p, err := preview.Serve(img, "localhost:0")
+//	if err != nil { … }
+//	defer p.Close()
+//	if err := p.Open(); err != nil {
+//		log.Println("aperçu disponible à l'adresse", p.URL())
+//	}
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	netpbm "github.com/eliiimk/Netpbm"
+)
+
+// Preview est l'aperçu en direct d'une image. La page permet de zoomer (boutons ou molette) et
+// indique les valeurs du pixel sous le curseur.
+//
+// Preview s'abonne aux modifications de l'image (voir OnChange) et en recopie les zones modifiées
+// dans un instantané, dans la goroutine qui dessine : le serveur HTTP ne lit jamais l'image
+// elle-même, qui peut donc continuer à être modifiée sans synchronisation.
+type Preview struct {
+	mu       sync.Mutex
+	frame    previewFrame
+	version  int
+	changed  chan struct{} // Fermé puis remplacé à chaque modification
+	cancel   func()
+	server   *http.Server
+	listener net.Listener
+}
+
+// previewFrame est une copie des valeurs brutes de l'image.
+type previewFrame struct {
+	width, height int
+	channels      int // 3 pour une image PPM, 1 pour une image PGM
+	max           int
+	values        []uint8
+}
+
+// New crée un aperçu de l'image, à servir avec ServeHTTP ; Serve le sert lui-même.
+func New(img netpbm.Image) (*Preview, error) {
+	preview := &Preview{changed: make(chan struct{})}
+	width, height := img.Size()
+	all := netpbm.Rect{Width: width, Height: height}
+	switch img := img.(type) {
+	case *netpbm.PPM:
+		preview.frame.channels = 3
+		preview.capture(img, all)
+		preview.cancel = img.OnChange(func(r netpbm.Rect) { preview.capture(img, r) })
+	case *netpbm.PGM:
+		preview.frame.channels = 1
+		preview.capture(img, all)
+		preview.cancel = img.OnChange(func(r netpbm.Rect) { preview.capture(img, r) })
+	default:
+		return nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+	}
+	return preview, nil
+}
+
+// Serve sert l'aperçu de l'image à l'adresse donnée (":0" pour un port libre), dont URL renvoie
+// l'adresse de la page. Open l'ouvre dans le navigateur ; Close arrête le serveur.
+func Serve(img netpbm.Image, addr string) (*Preview, error) {
+	preview, err := New(img)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		preview.cancel()
+		return nil, err
+	}
+	preview.listener = listener
+	preview.server = &http.Server{Handler: preview}
+	go preview.server.Serve(listener)
+	return preview, nil
+}
+
+// Open ouvre la page d'aperçu dans le navigateur par défaut du système. En cas d'échec (pas
+// d'environnement graphique…), l'appelant peut encore indiquer URL à l'utilisateur.
+func (preview *Preview) Open() error {
+	if preview.listener == nil {
+		return fmt.Errorf("aperçu non servi (voir Serve)")
+	}
+	return openBrowser(preview.URL())
+}
+
+// URL renvoie l'adresse de la page d'aperçu servie par Serve, ou une chaîne vide.
+func (preview *Preview) URL() string {
+	if preview.listener == nil {
+		return ""
+	}
+	return "http://" + preview.listener.Addr().String() + "/"
+}
+
+// Close se désabonne des modifications de l'image et arrête le serveur éventuel.
+func (preview *Preview) Close() error {
+	preview.cancel()
+	if preview.server != nil {
+		return preview.server.Close()
+	}
+	return nil
+}
+
+// capture recopie la zone r de l'image dans l'instantané ; les dimensions de l'image ont pu changer.
+func (preview *Preview) capture(img netpbm.Image, r netpbm.Rect) {
+	preview.mu.Lock()
+	defer preview.mu.Unlock()
+
+	frame := &preview.frame
+	width, height := img.Size()
+	if width != frame.width || height != frame.height {
+		frame.width, frame.height = width, height
+		frame.values = make([]uint8, width*height*frame.channels)
+		r = netpbm.Rect{Width: width, Height: height}
+	}
+	r = r.Intersect(netpbm.Rect{Width: width, Height: height})
+
+	switch img := img.(type) {
+	case *netpbm.PPM:
+		frame.max = img.MaxValue()
+		for y := r.Y; y < r.Y+r.Height; y++ {
+			for x := r.X; x < r.X+r.Width; x++ {
+				copy(frame.values[3*(y*width+x):], img.At(x, y)[:3])
+			}
+		}
+	case *netpbm.PGM:
+		frame.max = img.MaxValue()
+		for y := r.Y; y < r.Y+r.Height; y++ {
+			for x := r.X; x < r.X+r.Width; x++ {
+				frame.values[y*width+x] = img.At(x, y)
+			}
+		}
+	}
+
+	preview.version++
+	close(preview.changed)
+	preview.changed = make(chan struct{})
+}
+
+// ServeHTTP sert la page d'aperçu (/), l'image au format PNG (/image.png), les valeurs d'un pixel
+// (/pixel?x=…&y=…) et les notifications de modification (/events, en Server-Sent Events).
+func (preview *Preview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, previewPage)
+	case "/image.png":
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "no-store")
+		png.Encode(w, preview.image())
+	case "/pixel":
+		preview.servePixel(w, r)
+	case "/events":
+		preview.serveEvents(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// image convertit l'instantané en image de la bibliothèque standard, sur 8 bits.
+func (preview *Preview) image() image.Image {
+	preview.mu.Lock()
+	defer preview.mu.Unlock()
+
+	frame := preview.frame
+	if frame.channels == 1 {
+		gray := image.NewGray(image.Rect(0, 0, frame.width, frame.height))
+		for i, value := range frame.values {
+			gray.Pix[i] = scaleTo8(value, frame.max)
+		}
+		return gray
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, frame.width, frame.height))
+	for i := 0; i < frame.width*frame.height; i++ {
+		rgba.Pix[4*i] = scaleTo8(frame.values[3*i], frame.max)
+		rgba.Pix[4*i+1] = scaleTo8(frame.values[3*i+1], frame.max)
+		rgba.Pix[4*i+2] = scaleTo8(frame.values[3*i+2], frame.max)
+		rgba.Pix[4*i+3] = 255
+	}
+	return rgba
+}
+
+// servePixel renvoie en JSON les valeurs brutes du pixel demandé.
+func (preview *Preview) servePixel(w http.ResponseWriter, r *http.Request) {
+	x, errX := strconv.Atoi(r.URL.Query().Get("x"))
+	y, errY := strconv.Atoi(r.URL.Query().Get("y"))
+
+	preview.mu.Lock()
+	frame := preview.frame
+	if errX != nil || errY != nil || x < 0 || y < 0 || x >= frame.width || y >= frame.height {
+		preview.mu.Unlock()
+		http.Error(w, "coordonnées invalides", http.StatusBadRequest)
+		return
+	}
+	offset := frame.channels * (y*frame.width + x)
+	values := append([]uint8(nil), frame.values[offset:offset+frame.channels]...)
+	preview.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		X      int   `json:"x"`
+		Y      int   `json:"y"`
+		Values []int `json:"values"`
+		Max    int   `json:"max"`
+	}{x, y, toInts(values), frame.max})
+}
+
+// scaleTo8 ramène une valeur de l'échelle 0..max à l'échelle 0..255.
+func scaleTo8(value uint8, max int) uint8 {
+	if max <= 0 || max == 255 {
+		return value
+	}
+	return uint8((int(value)*255 + max/2) / max)
+}
+
+// toInts convertit des octets en entiers (encoding/json encoderait un []uint8 en base64).
+func toInts(values []uint8) []int {
+	ints := make([]int, len(values))
+	for i, value := range values {
+		ints[i] = int(value)
+	}
+	return ints
+}
+
+// serveEvents envoie le numéro de version de l'image à chaque modification. Les modifications
+// rapprochées (un dessin pixel par pixel) sont regroupées en une notification toutes les 50 ms.
+func (preview *Preview) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "flux non pris en charge", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+
+	for {
+		preview.mu.Lock()
+		version, changed := preview.version, preview.changed
+		preview.mu.Unlock()
+		fmt.Fprintf(w, "data: %d\n\n", version)
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+		select {
+		case <-time.After(50 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// openBrowser ouvre l'adresse dans le navigateur par défaut du système.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// previewPage est la page d'aperçu : l'image agrandie sans lissage, le zoom et l'inspecteur de pixels.
+const previewPage = `<!DOCTYPE html>
+<html lang="fr">
+<head>
+<meta charset="utf-8">
+<title>Aperçu Netpbm</title>
+<style>
+	body { margin: 0; font: 14px sans-serif; background: #333; color: #eee; }
+	header { position: sticky; top: 0; padding: 6px 10px; background: #222; }
+	main { padding: 10px; overflow: auto; }
+	img { image-rendering: pixelated; cursor: crosshair; background: #fff; }
+	#pixel { margin-left: 1em; font-family: monospace; }
+</style>
+</head>
+<body>
+<header>
+	<button id="out">−</button> <span id="zoom"></span> <button id="in">+</button>
+	<span id="pixel"></span>
+</header>
+<main><img id="image" src="image.png"></main>
+<script>
+	const img = document.getElementById("image");
+	let zoom = 1, last = "";
+
+	function setZoom(value) {
+		zoom = Math.min(64, Math.max(1, value));
+		img.style.width = img.naturalWidth * zoom + "px";
+		document.getElementById("zoom").textContent = "×" + zoom;
+	}
+	img.onload = () => setZoom(zoom);
+	document.getElementById("in").onclick = () => setZoom(zoom * 2);
+	document.getElementById("out").onclick = () => setZoom(zoom / 2);
+	img.addEventListener("wheel", (event) => {
+		event.preventDefault();
+		setZoom(event.deltaY < 0 ? zoom * 2 : zoom / 2);
+	});
+
+	img.addEventListener("mousemove", (event) => {
+		const x = Math.floor(event.offsetX / zoom), y = Math.floor(event.offsetY / zoom);
+		const key = x + "," + y;
+		if (key === last) return;
+		last = key;
+		fetch("pixel?x=" + x + "&y=" + y).then((r) => r.ok ? r.json() : null).then((p) => {
+			if (p) document.getElementById("pixel").textContent =
+				"(" + p.x + ", " + p.y + ") = " + p.values.join(" ") + " / " + p.max;
+		});
+	});
+
+	new EventSource("events").onmessage = (event) => { img.src = "image.png?v=" + event.data; };
+</script>
+</body>
+</html>
+`
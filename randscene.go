@@ -0,0 +1,193 @@
+package netpbm
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// ShapeKind est le type d'une forme tirée par SceneGenerator.
+type ShapeKind int
+
+const (
+	ShapeLine ShapeKind = iota
+	ShapeTriangle
+	ShapeFilledTriangle
+	ShapePolygon
+	ShapeFilledPolygon
+	ShapeFilledRectangle
+	ShapeCircle
+	ShapeFilledCircle
+	shapeKinds // Nombre de types de formes
+)
+
+// shapeNames associe chaque type de forme à son nom, tel qu'affiché par Shape.String.
+var shapeNames = [shapeKinds]string{
+	"ligne", "triangle", "triangle plein", "polygone", "polygone plein", "rectangle plein", "cercle", "cercle plein",
+}
+
+// String renvoie le nom du type de forme.
+func (kind ShapeKind) String() string {
+	if kind < 0 || kind >= shapeKinds {
+		return fmt.Sprintf("ShapeKind(%d)", int(kind))
+	}
+	return shapeNames[kind]
+}
+
+// Shape est une forme à dessiner : ses sommets (le coin supérieur gauche pour un rectangle, le
+// centre pour un cercle), sa taille éventuelle et sa couleur.
+type Shape struct {
+	Kind          ShapeKind
+	Points        []Point
+	Width, Height int // Rectangle
+	Radius        int // Cercle
+	Color         Pixel
+}
+
+// Draw dessine la forme sur l'image avec la méthode Draw correspondante.
+func (shape Shape) Draw(ppm *PPM) {
+	p := shape.Points
+	switch shape.Kind {
+	case ShapeLine:
+		ppm.DrawLine(p[0], p[1], shape.Color)
+	case ShapeTriangle:
+		ppm.DrawTriangle(p[0], p[1], p[2], shape.Color)
+	case ShapeFilledTriangle:
+		ppm.DrawFilledTriangle(p[0], p[1], p[2], shape.Color)
+	case ShapePolygon:
+		ppm.DrawPolygon(p, shape.Color)
+	case ShapeFilledPolygon:
+		ppm.DrawFilledPolygon(p, shape.Color)
+	case ShapeFilledRectangle:
+		ppm.DrawFilledRectangle(p[0], shape.Width, shape.Height, shape.Color)
+	case ShapeCircle:
+		ppm.DrawCircle(p[0], shape.Radius, shape.Color)
+	case ShapeFilledCircle:
+		ppm.DrawFilledCircle(p[0], shape.Radius, shape.Color)
+	}
+}
+
+// String décrit la forme sous une forme lisible et recopiable dans un test de non-régression,
+// par exemple "cercle (12,8) r=5 #ff8000".
+func (shape Shape) String() string {
+	var b strings.Builder
+	b.WriteString(shape.Kind.String())
+	for _, p := range shape.Points {
+		fmt.Fprintf(&b, " (%d,%d)", p.X, p.Y)
+	}
+	switch shape.Kind {
+	case ShapeFilledRectangle:
+		fmt.Fprintf(&b, " %dx%d", shape.Width, shape.Height)
+	case ShapeCircle, ShapeFilledCircle:
+		fmt.Fprintf(&b, " r=%d", shape.Radius)
+	}
+	fmt.Fprintf(&b, " #%02x%02x%02x", shape.Color.Red, shape.Color.Green, shape.Color.Blue)
+	return b.String()
+}
+
+// SceneGenerator tire des formes et des scènes au hasard pour éprouver le moteur de dessin (tests
+// de propriétés, fuzzing du tramage). Les tirages ne dépendent que de la graine : deux générateurs
+// créés avec la même graine et les mêmes réglages produisent exactement les mêmes formes, ce qui
+// permet de rejouer un échec.
+type SceneGenerator struct {
+	Width, Height int
+	// Margin est la distance maximale à laquelle les sommets des lignes et des polygones peuvent
+	// sortir de l'image, pour éprouver le découpage aux bords. Les rectangles et les cercles
+	// restent dans l'image, que leurs méthodes de dessin exigent.
+	Margin int
+	// Kinds restreint les types de formes tirés (tous si vide).
+	Kinds []ShapeKind
+
+	random *rand.Rand
+}
+
+// NewSceneGenerator crée un générateur de formes pour une image de la taille donnée.
+func NewSceneGenerator(seed int64, width, height int) *SceneGenerator {
+	return &SceneGenerator{
+		Width:  width,
+		Height: height,
+		Margin: max(width, height) / 4,
+		random: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// between tire un entier dans [low, high].
+func (generator *SceneGenerator) between(low, high int) int {
+	if high <= low {
+		return low
+	}
+	return low + generator.random.Intn(high-low+1)
+}
+
+// Point tire un point dans l'image élargie de Margin de chaque côté.
+func (generator *SceneGenerator) Point() Point {
+	return Point{
+		X: generator.between(-generator.Margin, generator.Width-1+generator.Margin),
+		Y: generator.between(-generator.Margin, generator.Height-1+generator.Margin),
+	}
+}
+
+// Color tire une couleur.
+func (generator *SceneGenerator) Color() Pixel {
+	c := generator.random.Uint32()
+	return Pixel{Red: uint8(c), Green: uint8(c >> 8), Blue: uint8(c >> 16)}
+}
+
+// Shape tire une forme.
+func (generator *SceneGenerator) Shape() Shape {
+	kind := ShapeKind(generator.random.Intn(int(shapeKinds)))
+	if len(generator.Kinds) > 0 {
+		kind = generator.Kinds[generator.random.Intn(len(generator.Kinds))]
+	}
+	if (kind == ShapeCircle || kind == ShapeFilledCircle) && min(generator.Width, generator.Height) < 3 {
+		kind = ShapeLine // Aucun cercle ne tient dans l'image.
+	}
+	shape := Shape{Kind: kind}
+
+	switch kind {
+	case ShapeLine:
+		shape.Points = []Point{generator.Point(), generator.Point()}
+	case ShapeTriangle, ShapeFilledTriangle:
+		shape.Points = []Point{generator.Point(), generator.Point(), generator.Point()}
+	case ShapePolygon, ShapeFilledPolygon:
+		shape.Points = make([]Point, generator.between(3, 8))
+		for i := range shape.Points {
+			shape.Points[i] = generator.Point()
+		}
+	case ShapeFilledRectangle:
+		corner := Point{X: generator.between(0, generator.Width-1), Y: generator.between(0, generator.Height-1)}
+		shape.Points = []Point{corner}
+		shape.Width = generator.between(1, generator.Width-corner.X)
+		shape.Height = generator.between(1, generator.Height-corner.Y)
+	case ShapeCircle, ShapeFilledCircle:
+		// Un cercle de rayon r centré en c doit vérifier r <= c et c + r < taille.
+		largest := (min(generator.Width, generator.Height) - 1) / 2
+		shape.Radius = generator.between(1, largest)
+		shape.Points = []Point{{
+			X: generator.between(shape.Radius, generator.Width-1-shape.Radius),
+			Y: generator.between(shape.Radius, generator.Height-1-shape.Radius),
+		}}
+	}
+	shape.Color = generator.Color()
+	return shape
+}
+
+// Scene tire count formes.
+func (generator *SceneGenerator) Scene(count int) []Shape {
+	shapes := make([]Shape, count)
+	for i := range shapes {
+		shapes[i] = generator.Shape()
+	}
+	return shapes
+}
+
+// RandomScene dessine count formes tirées au hasard avec la graine donnée sur une image noire de
+// la taille donnée, et renvoie l'image et les formes dessinées.
+func RandomScene(seed int64, width, height, count int) (*PPM, []Shape) {
+	ppm := NewPPM(width, height, 255)
+	shapes := NewSceneGenerator(seed, width, height).Scene(count)
+	for _, shape := range shapes {
+		shape.Draw(ppm)
+	}
+	return ppm, shapes
+}
@@ -0,0 +1,65 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadImage lit une image Netpbm quel que soit son type, d'après le nombre magique du fichier,
+// et renvoie un *PBM, un *PGM ou un *PPM.
+func ReadImage(filename string) (Image, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 2)
+	_, err = io.ReadFull(file, header)
+	file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("en-tête illisible: %v", err)
+	}
+
+	format, err := ParseFormat(strings.TrimSpace(string(header)))
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatP1, FormatP4:
+		return ReadPBM(filename)
+	case FormatP2, FormatP5:
+		return ReadPGM(filename)
+	default:
+		return ReadPPM(filename)
+	}
+}
+
+// readHeaderFields lit les count premiers champs de l'en-tête d'un fichier Netpbm binaire (nombre
+// magique, largeur, hauteur et éventuellement valeur maximale) en ignorant les commentaires, puis
+// consomme l'unique caractère blanc qui précède les données.
+func readHeaderFields(r *bufio.Reader, count int) ([]string, error) {
+	var fields []string
+	var field []byte
+	for len(fields) < count {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("en-tête incomplet: %v", err)
+		}
+		switch {
+		case c == '#' && len(field) == 0:
+			if _, err := r.ReadString('\n'); err != nil {
+				return nil, fmt.Errorf("en-tête incomplet: %v", err)
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if len(field) > 0 {
+				fields = append(fields, string(field))
+				field = field[:0]
+			}
+		default:
+			field = append(field, c)
+		}
+	}
+	return fields, nil
+}
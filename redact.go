@@ -0,0 +1,104 @@
+package netpbm
+
+// paste recopie l'image src dans la zone r de l'image, de même taille.
+func (ppm *PPM) paste(src *PPM, r Rect) {
+	for y := 0; y < r.Height; y++ {
+		row := ppm.writableRow(r.Y + y)
+		for x := 0; x < r.Width; x++ {
+			copy(row[r.X+x], src.data[y][x])
+		}
+	}
+	ppm.changes.mark(r)
+}
+
+// paste recopie l'image src dans la zone r de l'image, de même taille.
+func (pgm *PGM) paste(src *PGM, r Rect) {
+	for y := 0; y < r.Height; y++ {
+		copy(pgm.writableRow(r.Y + y)[r.X:r.X+r.Width], src.data[y])
+	}
+	pgm.changes.mark(r)
+}
+
+// BlurRegions floute fortement les zones données de l'image PPM (flou gaussien d'écart type sigma,
+// limité à chaque zone), par exemple pour masquer des visages ou des plaques détectés par ailleurs.
+// Les zones qui débordent de l'image sont rognées. Seules les zones floutées sont marquées modifiées.
+func (ppm *PPM) BlurRegions(regions []Rect, sigma float64) {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.meta.record("blur regions %d %g", len(regions), sigma)
+	for _, r := range regions {
+		r = r.Intersect(Rect{Width: ppm.width, Height: ppm.height})
+		if r.Empty() {
+			continue
+		}
+		ppm.paste(ppm.crop(r).toFloat(false).blur(sigma).toPPM(ppm.max, false), r)
+	}
+}
+
+// BlurRegions floute fortement les zones données de l'image PGM, comme PPM.BlurRegions.
+func (pgm *PGM) BlurRegions(regions []Rect, sigma float64) {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.meta.record("blur regions %d %g", len(regions), sigma)
+	for _, r := range regions {
+		r = r.Intersect(Rect{Width: pgm.width, Height: pgm.height})
+		if r.Empty() {
+			continue
+		}
+		pgm.paste(pgm.crop(r).toFloat(false).blur(sigma).toPGM(pgm.max, false), r)
+	}
+}
+
+// pixelate remplace chaque bloc de block×block pixels de l'image flottante par sa moyenne.
+func (img *floatImage) pixelate(block int) {
+	for top := 0; top < img.height; top += block {
+		for left := 0; left < img.width; left += block {
+			bottom, right := min(top+block, img.height), min(left+block, img.width)
+			count := float64((bottom - top) * (right - left))
+			for c := 0; c < img.channels; c++ {
+				var sum float64
+				for y := top; y < bottom; y++ {
+					for x := left; x < right; x++ {
+						sum += img.pix[img.index(x, y, c)]
+					}
+				}
+				for y := top; y < bottom; y++ {
+					for x := left; x < right; x++ {
+						img.pix[img.index(x, y, c)] = sum / count
+					}
+				}
+			}
+		}
+	}
+}
+
+// PixelateRegions remplace les zones données de l'image PPM par des blocs unis de block×block
+// pixels, alignés sur le coin de chaque zone : une autre manière de masquer, plus lisible comme
+// telle qu'un flou.
+func (ppm *PPM) PixelateRegions(regions []Rect, block int) {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.meta.record("pixelate regions %d %d", len(regions), block)
+	for _, r := range regions {
+		r = r.Intersect(Rect{Width: ppm.width, Height: ppm.height})
+		if r.Empty() || block < 1 {
+			continue
+		}
+		img := ppm.crop(r).toFloat(false)
+		img.pixelate(block)
+		ppm.paste(img.toPPM(ppm.max, false), r)
+	}
+}
+
+// PixelateRegions remplace les zones données de l'image PGM par des blocs unis, comme
+// PPM.PixelateRegions.
+func (pgm *PGM) PixelateRegions(regions []Rect, block int) {
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.meta.record("pixelate regions %d %d", len(regions), block)
+	for _, r := range regions {
+		r = r.Intersect(Rect{Width: pgm.width, Height: pgm.height})
+		if r.Empty() || block < 1 {
+			continue
+		}
+		img := pgm.crop(r).toFloat(false)
+		img.pixelate(block)
+		pgm.paste(img.toPGM(pgm.max, false), r)
+	}
+}
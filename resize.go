@@ -0,0 +1,102 @@
+package netpbm
+
+import "math"
+
+// resampleWeights renvoie, pour chaque position de destination, l'indice du premier pixel source
+// et les poids (normalisés) d'un filtre triangle. Lors d'une réduction, le filtre est élargi
+// du facteur de réduction pour moyenner tous les pixels sources couverts.
+func resampleWeights(source, destination int) ([]int, [][]float64) {
+	scale := float64(source) / float64(destination)
+	support := math.Max(scale, 1)
+
+	starts := make([]int, destination)
+	weights := make([][]float64, destination)
+	for i := 0; i < destination; i++ {
+		center := (float64(i)+0.5)*scale - 0.5
+		first := int(math.Floor(center - support))
+		last := int(math.Ceil(center + support))
+
+		var total float64
+		row := make([]float64, 0, last-first+1)
+		for j := first; j <= last; j++ {
+			w := 1 - math.Abs(float64(j)-center)/support
+			if w < 0 {
+				w = 0
+			}
+			row = append(row, w)
+			total += w
+		}
+		for k := range row {
+			row[k] /= total
+		}
+
+		starts[i] = first
+		weights[i] = row
+	}
+	return starts, weights
+}
+
+// resize redimensionne l'image flottante par filtrage séparable (horizontal puis vertical).
+func (img *floatImage) resize(width, height int) *floatImage {
+	starts, weights := resampleWeights(img.width, width)
+	horizontal := newFloatImage(width, img.height, img.channels)
+	for y := 0; y < img.height; y++ {
+		for x := 0; x < width; x++ {
+			for c := 0; c < img.channels; c++ {
+				var sum float64
+				for k, w := range weights[x] {
+					sum += w * img.at(starts[x]+k, y, c)
+				}
+				horizontal.pix[horizontal.index(x, y, c)] = sum
+			}
+		}
+	}
+
+	starts, weights = resampleWeights(img.height, height)
+	result := newFloatImage(width, height, img.channels)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			for c := 0; c < img.channels; c++ {
+				var sum float64
+				for k, w := range weights[y] {
+					sum += w * horizontal.at(x, starts[y]+k, c)
+				}
+				result.pix[result.index(x, y, c)] = sum
+			}
+		}
+	}
+	return result
+}
+
+// Resize renvoie une copie de l'image PPM redimensionnée à width×height (noire si l'image est vide).
+// Si linear est vrai, le filtrage est effectué en lumière linéaire (conversion sRGB aller-retour),
+// ce qui évite l'assombrissement des contours propre au filtrage direct des valeurs sRGB.
+func (ppm *PPM) Resize(width, height int, linear bool) *PPM {
+	if width <= 0 || height <= 0 {
+		return NewPPM(0, 0, ppm.max)
+	}
+	// Une image vide n'a aucun pixel à échantillonner : le résultat est noir.
+	result := NewPPM(width, height, ppm.max)
+	if ppm.width > 0 && ppm.height > 0 {
+		result = ppm.toFloat(linear).resize(width, height).toPPM(ppm.max, linear)
+	}
+	result.magicNumber = ppm.magicNumber
+	result.meta = ppm.meta.derive("resize %dx%d", width, height)
+	return result
+}
+
+// Resize renvoie une copie de l'image PGM redimensionnée à width×height (noire si l'image est vide).
+// Si linear est vrai, le filtrage est effectué en lumière linéaire.
+func (pgm *PGM) Resize(width, height int, linear bool) *PGM {
+	if width <= 0 || height <= 0 {
+		return NewPGM(0, 0, pgm.max)
+	}
+	// Une image vide n'a aucun pixel à échantillonner : le résultat est noir.
+	result := NewPGM(width, height, pgm.max)
+	if pgm.width > 0 && pgm.height > 0 {
+		result = pgm.toFloat(linear).resize(width, height).toPGM(pgm.max, linear)
+	}
+	result.magicNumber = pgm.magicNumber
+	result.meta = pgm.meta.derive("resize %dx%d", width, height)
+	return result
+}
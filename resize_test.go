@@ -0,0 +1,34 @@
+package netpbm
+
+import "testing"
+
+func TestResizeEmptySource(t *testing.T) {
+	for _, size := range [][2]int{{0, 4}, {4, 0}, {0, 0}} {
+		ppm := NewPPM(size[0], size[1], 255).Resize(3, 2, true)
+		if w, h := ppm.Size(); w != 3 || h != 2 {
+			t.Errorf("PPM %dx%d: %dx%d au lieu de 3x2", size[0], size[1], w, h)
+		}
+		pgm := NewPGM(size[0], size[1], 255).Resize(3, 2, false)
+		if w, h := pgm.Size(); w != 3 || h != 2 || pgm.At(2, 1) != 0 {
+			t.Errorf("PGM %dx%d: %dx%d au lieu de 3x2 noire", size[0], size[1], w, h)
+		}
+	}
+}
+
+func TestResizeUniform(t *testing.T) {
+	pgm := NewPGM(7, 5, 255)
+	pgm.Invert()
+	for _, linear := range []bool{false, true} {
+		resized := pgm.Resize(3, 11, linear)
+		for y, row := range resized.data {
+			for x, value := range row {
+				if value != 255 {
+					t.Fatalf("linear %v, (%d, %d): %d au lieu de 255", linear, x, y, value)
+				}
+			}
+		}
+	}
+	if w, h := pgm.Resize(0, 3, false).Size(); w != 0 || h != 0 {
+		t.Errorf("taille nulle: %dx%d au lieu de 0x0", w, h)
+	}
+}
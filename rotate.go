@@ -0,0 +1,126 @@
+package netpbm
+
+import "math"
+
+// rotation décrit une rotation d'image d'un angle quelconque autour de son centre : la taille de
+// l'image tournée, agrandie pour contenir toute l'image d'origine, et la correspondance inverse
+// de ses pixels vers l'image d'origine.
+type rotation struct {
+	width, height int
+	sin, cos      float64
+	srcCenter     Vec2
+	dstCenter     Vec2
+}
+
+// newRotation prépare la rotation d'une image de width×height pixels de angle degrés, dans le
+// sens des aiguilles d'une montre.
+func newRotation(width, height int, angle float64) rotation {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	// Le léger retrait évite qu'une erreur d'arrondi n'ajoute une ligne ou une colonne.
+	w := math.Abs(float64(width)*cos) + math.Abs(float64(height)*sin)
+	h := math.Abs(float64(width)*sin) + math.Abs(float64(height)*cos)
+	r := rotation{
+		width:  int(math.Ceil(w - 1e-9)),
+		height: int(math.Ceil(h - 1e-9)),
+		sin:    sin,
+		cos:    cos,
+	}
+	r.srcCenter = Vec2{X: float64(width-1) / 2, Y: float64(height-1) / 2}
+	r.dstCenter = Vec2{X: float64(r.width-1) / 2, Y: float64(r.height-1) / 2}
+	return r
+}
+
+// source renvoie le point de l'image d'origine qui vient se placer sur le pixel (x, y).
+func (r rotation) source(x, y int) (float64, float64) {
+	dx, dy := float64(x)-r.dstCenter.X, float64(y)-r.dstCenter.Y
+	return r.srcCenter.X + dx*r.cos + dy*r.sin, r.srcCenter.Y - dx*r.sin + dy*r.cos
+}
+
+// quarterTurns renvoie le nombre de quarts de tour (0 à 3) si angle, en degrés, en est un
+// multiple exact.
+func quarterTurns(angle float64) (int, bool) {
+	angle = math.Mod(angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	if angle != math.Trunc(angle) || int(angle)%90 != 0 {
+		return 0, false
+	}
+	return int(angle) / 90, true
+}
+
+// Rotate fait pivoter l'image PPM de angle degrés dans le sens des aiguilles d'une montre,
+// autour de son centre. L'image est agrandie pour contenir toute l'image tournée, les coins ainsi
+// découverts étant peints avec la couleur bg ; les pixels sont interpolés de façon bilinéaire.
+// Les multiples de 90° sont traités sans interpolation, par Rotate90CW, Rotate180 et Rotate90CCW.
+func (ppm *PPM) Rotate(angle float64, bg Pixel) {
+	if turns, ok := quarterTurns(angle); ok {
+		switch turns {
+		case 1:
+			ppm.Rotate90CW()
+		case 2:
+			ppm.Rotate180()
+		case 3:
+			ppm.Rotate90CCW()
+		}
+		return
+	}
+
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.changes.markAll(ppm.Size)
+	ppm.meta.record("rotate %g", angle)
+	r := newRotation(ppm.width, ppm.height, angle)
+	samples := ppm.toFloat(false)
+	result := NewPPM(r.width, r.height, ppm.max)
+	for y, row := range result.data {
+		for x, pixel := range row {
+			sx, sy := r.source(x, y)
+			if !samples.contains(sx, sy) {
+				pixel[0], pixel[1], pixel[2] = bg.Red, bg.Green, bg.Blue
+				continue
+			}
+			for c := range pixel {
+				pixel[c] = encodeValue(samples.bilinear(sx, sy, c), ppm.max, false)
+			}
+		}
+	}
+
+	ppm.data, ppm.shared, ppm.viewed = result.data, nil, nil
+	ppm.width, ppm.height = r.width, r.height
+}
+
+// Rotate fait pivoter l'image PGM comme PPM.Rotate ; faute de couleur, les coins découverts
+// prennent le niveau de gris bg.
+func (pgm *PGM) Rotate(angle float64, bg uint8) {
+	if turns, ok := quarterTurns(angle); ok {
+		switch turns {
+		case 1:
+			pgm.Rotate90CW()
+		case 2:
+			pgm.Rotate180()
+		case 3:
+			pgm.Rotate90CCW()
+		}
+		return
+	}
+
+	defer pgm.changes.batch(pgm.Size)()
+	pgm.changes.markAll(pgm.Size)
+	pgm.meta.record("rotate %g", angle)
+	r := newRotation(pgm.width, pgm.height, angle)
+	samples := pgm.toFloat(false)
+	result := NewPGM(r.width, r.height, pgm.max)
+	for y, row := range result.data {
+		for x := range row {
+			sx, sy := r.source(x, y)
+			if !samples.contains(sx, sy) {
+				row[x] = bg
+				continue
+			}
+			row[x] = encodeValue(samples.bilinear(sx, sy, 0), pgm.max, false)
+		}
+	}
+
+	pgm.data, pgm.shared, pgm.viewed = result.data, nil, nil
+	pgm.width, pgm.height = r.width, r.height
+}
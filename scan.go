@@ -0,0 +1,275 @@
+package netpbm
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DecodeOptions règle la lecture des fichiers Netpbm par ReadPGM, ReadPPM, ReadPackedPBM et les
+// fonctions Decode (DecodePPM…). Les petits fichiers (icônes, vignettes) sont lus d'un bloc puis
+// analysés en mémoire, sans recopie ; les gros (trames de plusieurs gigaoctets) et les flux passés
+// aux fonctions Decode sont lus à travers un tampon de taille fixe, pour ne jamais garder en mémoire
+// à la fois le fichier entier et l'image décodée.
+type DecodeOptions struct {
+	// InMemoryLimit est la taille en octets jusqu'à laquelle un fichier est lu d'un bloc.
+	InMemoryLimit int64
+	// BufferSize est la taille en octets du tampon de lecture en flux.
+	BufferSize int
+	// IgnoreChecksum désactive la vérification de l'empreinte des pixels (voir EncodeOptions.Checksum).
+	IgnoreChecksum bool
+	// Polarity est la convention donnée aux images PBM lues (voir Polarity).
+	Polarity Polarity
+}
+
+// DefaultDecodeOptions est utilisé par les fonctions de lecture ; une valeur nulle reprend la
+// valeur par défaut correspondante.
+var DefaultDecodeOptions = DecodeOptions{InMemoryLimit: 4 << 20, BufferSize: 256 << 10}
+
+// inMemoryLimit renvoie le seuil de lecture d'un bloc.
+func (options DecodeOptions) inMemoryLimit() int64 {
+	if options.InMemoryLimit <= 0 {
+		return 4 << 20
+	}
+	return options.InMemoryLimit
+}
+
+// bufferSize renvoie la taille du tampon de lecture en flux.
+func (options DecodeOptions) bufferSize() int {
+	if options.BufferSize <= 0 {
+		return 256 << 10
+	}
+	return options.BufferSize
+}
+
+// sampleScanner lit les champs d'un fichier Netpbm ASCII directement dans un tampon d'octets, sans
+// créer de chaîne par échantillon : les blancs et les commentaires sont sautés et les entiers
+// décimaux sont accumulés chiffre par chiffre. Les échantillons n'ont pas à respecter les lignes de
+// l'image, et les lignes du fichier peuvent être aussi longues que nécessaire.
+type sampleScanner struct {
+	buf []byte
+	pos int
+	r   io.Reader // nil si buf contient déjà tout le fichier
+	err error     // erreur qui a interrompu la lecture de r
+
+	comments []string // Commentaires rencontrés, sans le "#" ni les blancs qui l'entourent
+}
+
+// newSampleScanner crée un lecteur de champs sur r, avec un tampon de size octets.
+func newSampleScanner(r io.Reader, size int) *sampleScanner {
+	return &sampleScanner{buf: make([]byte, 0, size), r: r}
+}
+
+// scanFile crée un lecteur de champs sur le fichier, lu d'un bloc ou en flux selon sa taille.
+func scanFile(file *os.File, options DecodeOptions) (*sampleScanner, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if size := info.Size(); info.Mode().IsRegular() && size <= options.inMemoryLimit() {
+		buf := make([]byte, size)
+		n, err := io.ReadFull(file, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		return &sampleScanner{buf: buf[:n]}, nil
+	}
+	return newSampleScanner(file, options.bufferSize()), nil
+}
+
+// fill recharge le tampon et renvoie false s'il n'y a plus rien à lire.
+func (scanner *sampleScanner) fill() bool {
+	if scanner.r == nil || scanner.err != nil {
+		return false
+	}
+	n, err := io.ReadAtLeast(scanner.r, scanner.buf[:cap(scanner.buf)], 1)
+	scanner.buf, scanner.pos = scanner.buf[:n], 0
+	scanner.err = err
+	return n > 0
+}
+
+// isBlank indique si c est un caractère blanc au sens de Netpbm.
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// skip avance jusqu'au début du champ suivant, en sautant les blancs et les commentaires (gardés
+// dans comments), et renvoie false si le fichier se termine avant.
+func (scanner *sampleScanner) skip() bool {
+	var comment []byte
+	inComment := false
+	for {
+		for ; scanner.pos < len(scanner.buf); scanner.pos++ {
+			c := scanner.buf[scanner.pos]
+			switch {
+			case inComment && (c == '\n' || c == '\r'):
+				scanner.addComment(comment)
+				inComment, comment = false, comment[:0]
+			case inComment:
+				comment = append(comment, c)
+			case c == '#':
+				inComment = true
+			case !isBlank(c):
+				return true
+			}
+		}
+		if !scanner.fill() {
+			if inComment {
+				scanner.addComment(comment)
+			}
+			return false
+		}
+	}
+}
+
+// addComment garde le texte d'un commentaire.
+func (scanner *sampleScanner) addComment(text []byte) {
+	scanner.comments = append(scanner.comments, strings.TrimSpace(string(text)))
+}
+
+// endError renvoie l'erreur à signaler quand le fichier se termine au milieu d'une lecture.
+func (scanner *sampleScanner) endError() error {
+	if scanner.err != nil && scanner.err != io.EOF {
+		return scanner.err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// token renvoie le champ suivant tel quel (le nombre magique, par exemple).
+func (scanner *sampleScanner) token() (string, error) {
+	if !scanner.skip() {
+		return "", scanner.endError()
+	}
+	var field []byte
+	for {
+		start := scanner.pos
+		for scanner.pos < len(scanner.buf) && !isBlank(scanner.buf[scanner.pos]) && scanner.buf[scanner.pos] != '#' {
+			scanner.pos++
+		}
+		field = append(field, scanner.buf[start:scanner.pos]...)
+		if scanner.pos < len(scanner.buf) || !scanner.fill() {
+			return string(field), nil
+		}
+	}
+}
+
+// next renvoie l'entier décimal suivant. Le tampon est parcouru à travers des variables locales,
+// que le compilateur garde en registre ; on ne repasse par les champs qu'en fin de tampon.
+func (scanner *sampleScanner) next() (int, error) {
+	buf, pos := scanner.buf, scanner.pos
+	for pos < len(buf) && (buf[pos] == ' ' || buf[pos] == '\n') {
+		pos++
+	}
+	if pos == len(buf) || buf[pos] == '#' || isBlank(buf[pos]) {
+		scanner.pos = pos
+		if !scanner.skip() {
+			return 0, scanner.endError()
+		}
+		buf, pos = scanner.buf, scanner.pos
+	}
+
+	value := 0
+	for {
+		for ; pos < len(buf); pos++ {
+			c := buf[pos]
+			if c-'0' > 9 {
+				scanner.pos = pos
+				if isBlank(c) || c == '#' {
+					return value, nil
+				}
+				return 0, fmt.Errorf("caractère inattendu dans un nombre: %q", c)
+			}
+			value = value*10 + int(c-'0')
+			if value > 1<<30 {
+				return 0, fmt.Errorf("nombre trop grand")
+			}
+		}
+		scanner.pos = pos
+		if !scanner.fill() {
+			return value, nil
+		}
+		buf, pos = scanner.buf, scanner.pos
+	}
+}
+
+// bit renvoie le pixel suivant d'une image PBM ASCII : un chiffre 0 ou 1, séparé ou non des
+// suivants par des blancs.
+func (scanner *sampleScanner) bit() (bool, error) {
+	if !scanner.skip() {
+		return false, scanner.endError()
+	}
+	c := scanner.buf[scanner.pos]
+	scanner.pos++
+	switch c {
+	case '0':
+		return false, nil
+	case '1':
+		return true, nil
+	}
+	return false, fmt.Errorf("valeur de pixel inattendue: %q", c)
+}
+
+// endHeader saute l'unique blanc qui sépare l'en-tête d'un format binaire (P4 à P6) de ses données.
+func (scanner *sampleScanner) endHeader() error {
+	if scanner.pos == len(scanner.buf) && !scanner.fill() {
+		return scanner.endError()
+	}
+	if !isBlank(scanner.buf[scanner.pos]) {
+		return fmt.Errorf("caractère inattendu après l'en-tête: %q", scanner.buf[scanner.pos])
+	}
+	scanner.pos++
+	return nil
+}
+
+// read remplit dst avec les octets suivants du fichier, tels quels.
+func (scanner *sampleScanner) read(dst []byte) error {
+	n := copy(dst, scanner.buf[scanner.pos:])
+	scanner.pos += n
+	if n == len(dst) {
+		return nil
+	}
+	if scanner.r == nil || scanner.err != nil {
+		return scanner.endError()
+	}
+	// Le reste est lu directement dans dst, sans passer par le tampon.
+	if _, err := io.ReadFull(scanner.r, dst[n:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+// dimensions lit la largeur et la hauteur d'une image, puis sa valeur maximale si withMax est vrai.
+// Les valeurs maximales de 1 à 65535 sont acceptées (voir check8Bits).
+func (scanner *sampleScanner) dimensions(withMax bool) (width, height, max int, err error) {
+	if width, err = scanner.next(); err != nil {
+		return 0, 0, 0, fmt.Errorf("largeur illisible: %v", err)
+	}
+	if height, err = scanner.next(); err != nil {
+		return 0, 0, 0, fmt.Errorf("hauteur illisible: %v", err)
+	}
+	if width == 0 || height == 0 {
+		return 0, 0, 0, fmt.Errorf("dimensions de l'image non spécifiées")
+	}
+	if withMax {
+		if max, err = scanner.next(); err != nil {
+			return 0, 0, 0, fmt.Errorf("valeur maximale illisible: %v", err)
+		}
+		if max == 0 || max > 65535 {
+			return 0, 0, 0, fmt.Errorf("valeur maximale invalide: %d", max)
+		}
+	}
+	return width, height, max, nil
+}
+
+// check8Bits refuse une valeur maximale supérieure à 255 : les échantillons de PGM et PPM sont
+// stockés sur 8 bits et seraient tronqués. Ces images se lisent avec DecodePGM16 ou DecodePPM16.
+func check8Bits(max int) error {
+	if max > 255 {
+		return fmt.Errorf("valeur maximale sur 16 bits non prise en charge: %d", max)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package netpbm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodePlain(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []uint8 // Échantillons attendus ; nil si le fichier doit être refusé
+	}{
+		{"P2 simple", "P2\n2 2\n255\n0 1\n2 255\n", []uint8{0, 1, 2, 255}},
+		{"P2 commentaires", "P2 # image\n2 # largeur\n1\n# max\n9\n4\n5", []uint8{4, 5}},
+		{"P2 une ligne", "P2 3 1 7 1 2 3", []uint8{1, 2, 3}},
+		{"P2 max 16 bits", "P2\n2 1\n65535\n0 65535\n", nil},
+		{"P2 max 256", "P2\n1 1\n256\n0\n", nil},
+		{"P2 max nul", "P2\n1 1\n0\n0\n", nil},
+		{"P2 échantillon trop grand", "P2\n2 1\n10\n3 11\n", nil},
+		{"P2 tronqué", "P2\n2 2\n255\n0 1 2", nil},
+		{"P2 taille nulle", "P2\n0 2\n255\n", nil},
+		{"P3 simple", "P3\n1 2\n255\n1 2 3\n4 5 6\n", []uint8{1, 2, 3, 4, 5, 6}},
+		{"P3 max 16 bits", "P3\n1 1\n1023\n0 0 1023\n", nil},
+		{"P3 échantillon trop grand", "P3\n1 1\n100\n0 101 0\n", nil},
+		{"P3 tronqué", "P3\n1 1\n255\n1 2", nil},
+	}
+	for _, test := range tests {
+		// Tampon de 3 octets : les champs chevauchent les remplissages successifs.
+		scanner := newSampleScanner(strings.NewReader(test.input), 3)
+		var got []uint8
+		var err error
+		if strings.HasPrefix(test.input, "P2") {
+			var pgm *PGM
+			if pgm, err = decodePGM(scanner); err == nil {
+				for _, row := range pgm.data {
+					got = append(got, row...)
+				}
+			}
+		} else {
+			var ppm *PPM
+			if ppm, err = decodePPM(scanner); err == nil {
+				for _, row := range ppm.data {
+					for _, pixel := range row {
+						got = append(got, pixel...)
+					}
+				}
+			}
+		}
+
+		switch {
+		case test.want == nil && err == nil:
+			t.Errorf("%s: fichier accepté (%v)", test.name, got)
+		case test.want != nil && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case test.want != nil && string(got) != string(test.want):
+			t.Errorf("%s: %v au lieu de %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDecodePlainComments(t *testing.T) {
+	pgm, err := DecodePGM(strings.NewReader("P2\n# auteur: test\n1 1\n255\n0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, h := pgm.Size(); h != 1 {
+		t.Fatalf("hauteur %d au lieu de 1", h)
+	}
+}
+
+func TestReadExampleFiles(t *testing.T) {
+	for _, name := range []string{"exemple.pbm", "exemple.pgm", "exemple.ppm"} {
+		if _, err := ReadImage(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
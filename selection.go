@@ -0,0 +1,53 @@
+package netpbm
+
+// floodMask renvoie le masque de la région 4-connexe contenant start dont les pixels satisfont similar.
+func floodMask(width, height int, start Point, similar func(x, y int) bool) *PBM {
+	mask := NewPBM(width, height)
+	if start.X < 0 || start.X >= width || start.Y < 0 || start.Y >= height {
+		return mask
+	}
+
+	stack := []Point{start}
+	mask.data[start.Y][start.X] = true
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, n := range [4]Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := p.X+n.X, p.Y+n.Y
+			if nx < 0 || nx >= width || ny < 0 || ny >= height || mask.data[ny][nx] || !similar(nx, ny) {
+				continue
+			}
+			mask.data[ny][nx] = true
+			stack = append(stack, Point{X: nx, Y: ny})
+		}
+	}
+	return mask
+}
+
+// SelectRegion renvoie le masque (pixels à 1) de la région contiguë (4-connexité) qui contient start
+// et dont chaque composante diffère d'au plus tolerance de la couleur du pixel de départ,
+// comme l'outil « baguette magique » d'un logiciel de dessin.
+func (ppm *PPM) SelectRegion(start Point, tolerance int) *PBM {
+	if start.X < 0 || start.X >= ppm.width || start.Y < 0 || start.Y >= ppm.height {
+		return NewPBM(ppm.width, ppm.height)
+	}
+	reference := ppm.pixel(start.X, start.Y)
+	return floodMask(ppm.width, ppm.height, start, func(x, y int) bool {
+		value := ppm.data[y][x]
+		return abs(int(value[0])-int(reference.Red)) <= tolerance &&
+			abs(int(value[1])-int(reference.Green)) <= tolerance &&
+			abs(int(value[2])-int(reference.Blue)) <= tolerance
+	})
+}
+
+// SelectRegion renvoie le masque de la région contiguë qui contient start et dont les niveaux
+// diffèrent d'au plus tolerance de celui du pixel de départ.
+func (pgm *PGM) SelectRegion(start Point, tolerance int) *PBM {
+	if start.X < 0 || start.X >= pgm.width || start.Y < 0 || start.Y >= pgm.height {
+		return NewPBM(pgm.width, pgm.height)
+	}
+	reference := int(pgm.data[start.Y][start.X])
+	return floodMask(pgm.width, pgm.height, start, func(x, y int) bool {
+		return abs(int(pgm.data[y][x])-reference) <= tolerance
+	})
+}
@@ -0,0 +1,87 @@
+package netpbm
+
+import "math"
+
+// CompositeSprite colle le sprite dans l'image PPM avec son coin supérieur gauche en at.
+// Seuls les pixels dont le masque est allumé sont copiés ; un masque nil copie tout le sprite.
+// Les pixels du sprite qui sortent d'un masque plus petit sont considérés comme éteints.
+func (ppm *PPM) CompositeSprite(sprite *PPM, mask *PBM, at Point) {
+	for y := 0; y < sprite.height; y++ {
+		for x := 0; x < sprite.width; x++ {
+			if mask != nil && (y >= mask.height || x >= mask.width || !mask.data[y][x]) {
+				continue
+			}
+			ppm.setPixel(at.X+x, at.Y+y, sprite.pixel(x, y))
+		}
+	}
+}
+
+// blurredMask renvoie le masque PBM converti en couverture flottante, agrandi de margin pixels de
+// chaque côté puis flouté (flou gaussien d'écart type sigma).
+func (pbm *PBM) blurredMask(margin int, sigma float64) *floatImage {
+	coverage := newFloatImage(pbm.width+2*margin, pbm.height+2*margin, 1)
+	for y := 0; y < pbm.height; y++ {
+		for x := 0; x < pbm.width; x++ {
+			if pbm.data[y][x] {
+				coverage.pix[coverage.index(x+margin, y+margin, 0)] = 1
+			}
+		}
+	}
+	return coverage.blur(sigma)
+}
+
+// paintCoverage fusionne la couleur dans l'image selon la couverture (entre 0 et 1) multipliée par opacity.
+// Le pixel (0, 0) de la couverture est placé en origin.
+func (ppm *PPM) paintCoverage(coverage *floatImage, origin Point, color Pixel, opacity float64, mode BlendMode) {
+	defer ppm.changes.batch(ppm.Size)()
+	ppm.own()
+	table := decodeTable(ppm.max, false)
+	colorValues := [3]float64{table[color.Red], table[color.Green], table[color.Blue]}
+
+	for y := 0; y < coverage.height; y++ {
+		for x := 0; x < coverage.width; x++ {
+			px, py := origin.X+x, origin.Y+y
+			alpha := coverage.pix[coverage.index(x, y, 0)] * opacity
+			if alpha <= 0 || px < 0 || px >= ppm.width || py < 0 || py >= ppm.height {
+				continue
+			}
+
+			value := make([]uint8, 3)
+			for c := 0; c < 3; c++ {
+				base := table[ppm.data[py][px][c]]
+				blended := mode.blend(base, colorValues[c])
+				value[c] = encodeValue(base+(blended-base)*alpha, ppm.max, false)
+			}
+			ppm.data[py][px] = value
+		}
+	}
+}
+
+// DrawDropShadow dessine dans l'image l'ombre portée d'un sprite de masque mask placé en at :
+// le masque est décalé de offset, flouté avec un écart type blurRadius et multiplié par la couleur
+// de l'ombre avec l'opacité donnée. Le sprite lui-même se colle ensuite avec CompositeSprite.
+func (ppm *PPM) DrawDropShadow(mask *PBM, at, offset Point, blurRadius float64, color Pixel, opacity float64) {
+	margin := int(math.Ceil(3 * math.Max(blurRadius, 0)))
+	coverage := mask.blurredMask(margin, blurRadius)
+	origin := Point{X: at.X + offset.X - margin, Y: at.Y + offset.Y - margin}
+	ppm.paintCoverage(coverage, origin, color, opacity, BlendMultiply)
+}
+
+// DrawOuterGlow dessine dans l'image un halo lumineux autour d'un sprite de masque mask placé en at :
+// le masque flouté (écart type blurRadius) est fusionné en mode écran avec la couleur du halo.
+func (ppm *PPM) DrawOuterGlow(mask *PBM, at Point, blurRadius float64, color Pixel, opacity float64) {
+	margin := int(math.Ceil(3 * math.Max(blurRadius, 0)))
+	coverage := mask.blurredMask(margin, blurRadius)
+
+	// Le halo est limité à l'extérieur du sprite.
+	for y := 0; y < mask.height; y++ {
+		for x := 0; x < mask.width; x++ {
+			if mask.data[y][x] {
+				coverage.pix[coverage.index(x+margin, y+margin, 0)] = 0
+			}
+		}
+	}
+
+	origin := Point{X: at.X - margin, Y: at.Y - margin}
+	ppm.paintCoverage(coverage, origin, color, opacity, BlendScreen)
+}
@@ -0,0 +1,177 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Segment est un segment de contour d'une coupe, en millimètres.
+type Segment struct {
+	A, B Vec2
+}
+
+// PolygonSegments renvoie les côtés de polygones fermés. Les trous sont décrits par des polygones
+// intérieurs : le remplissage suit la règle pair-impair, quel que soit le sens de parcours.
+func PolygonSegments(polygons [][]Vec2) []Segment {
+	var segments []Segment
+	for _, polygon := range polygons {
+		for i := range polygon {
+			segments = append(segments, Segment{polygon[i], polygon[(i+1)%len(polygon)]})
+		}
+	}
+	return segments
+}
+
+// segmentBounds renvoie la boîte englobante des segments.
+func segmentBounds(segments []Segment) (Vec2, Vec2) {
+	low := Vec2{math.Inf(1), math.Inf(1)}
+	high := Vec2{math.Inf(-1), math.Inf(-1)}
+	for _, segment := range segments {
+		for _, p := range []Vec2{segment.A, segment.B} {
+			low.X, low.Y = math.Min(low.X, p.X), math.Min(low.Y, p.Y)
+			high.X, high.Y = math.Max(high.X, p.X), math.Max(high.Y, p.Y)
+		}
+	}
+	return low, high
+}
+
+// RasterizeSegments remplit en noir l'intérieur des contours, dans la zone [low, high] (en
+// millimètres) rendue à dpi points par pouce. L'axe Y de la coupe est dirigé vers le haut : la
+// ligne 0 de l'image correspond à high.Y. Un pixel est rempli si son centre est à l'intérieur.
+func RasterizeSegments(segments []Segment, low, high Vec2, dpi float64) *PBM {
+	pixelSize := 25.4 / dpi
+	width := max(int(math.Ceil((high.X-low.X)/pixelSize)), 1)
+	height := max(int(math.Ceil((high.Y-low.Y)/pixelSize)), 1)
+	pbm := NewPBM(width, height)
+
+	var crossings []float64
+	for row := 0; row < height; row++ {
+		y := high.Y - (float64(row)+0.5)*pixelSize
+		crossings = crossings[:0]
+		for _, segment := range segments {
+			a, b := segment.A, segment.B
+			// Intervalle semi-ouvert : un sommet posé sur la ligne n'est compté qu'une fois.
+			if (a.Y <= y) == (b.Y <= y) {
+				continue
+			}
+			crossings = append(crossings, a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+		}
+		sort.Float64s(crossings)
+
+		for i := 0; i+1 < len(crossings); i += 2 {
+			// Pixels dont le centre est compris entre les deux intersections.
+			start := max(int(math.Ceil((crossings[i]-low.X)/pixelSize-0.5)), 0)
+			end := min(int(math.Floor((crossings[i+1]-low.X)/pixelSize-0.5)), width-1)
+			for x := start; x <= end; x++ {
+				pbm.data[row][x] = true
+			}
+		}
+	}
+	return pbm
+}
+
+// RasterizeSlice remplit une coupe décrite par des polygones (en millimètres) dans un masque PBM à
+// dpi points par pouce, cadré sur la boîte englobante des polygones.
+func RasterizeSlice(polygons [][]Vec2, dpi float64) *PBM {
+	segments := PolygonSegments(polygons)
+	if len(segments) == 0 {
+		return NewPBM(1, 1)
+	}
+	low, high := segmentBounds(segments)
+	return RasterizeSegments(segments, low, high, dpi)
+}
+
+// LoadSTL lit un fichier STL ASCII ; chaque facette devient une face triangulaire du modèle.
+func LoadSTL(filename string) (*Model, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	model := &Model{}
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	var face []int
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "outer":
+			face = nil
+		case "vertex":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("sommet incomplet à la ligne %d", lineNumber)
+			}
+			var coords [3]float64
+			for i := range coords {
+				if coords[i], err = strconv.ParseFloat(fields[i+1], 64); err != nil {
+					return nil, fmt.Errorf("coordonnée invalide à la ligne %d: %v", lineNumber, err)
+				}
+			}
+			face = append(face, len(model.Vertices))
+			model.Vertices = append(model.Vertices, Vec3{coords[0], coords[1], coords[2]})
+		case "endloop":
+			if len(face) < 3 {
+				return nil, fmt.Errorf("facette incomplète à la ligne %d", lineNumber)
+			}
+			model.Faces = append(model.Faces, face)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(model.Faces) == 0 {
+		return nil, fmt.Errorf("aucune facette dans %s (seuls les STL ASCII sont lus)", filename)
+	}
+	return model, nil
+}
+
+// SliceAt coupe le modèle par le plan horizontal Z = z et renvoie les segments du contour.
+// Les sommets situés exactement sur le plan sont considérés comme au-dessus, ce qui évite les
+// segments dégénérés.
+func (model *Model) SliceAt(z float64) []Segment {
+	var segments []Segment
+	for _, face := range model.Faces {
+		// Les faces de plus de trois sommets sont découpées en éventail.
+		for i := 1; i+1 < len(face); i++ {
+			triangle := [3]Vec3{model.Vertices[face[0]], model.Vertices[face[i]], model.Vertices[face[i+1]]}
+			var points []Vec2
+			for j := 0; j < 3; j++ {
+				a, b := triangle[j], triangle[(j+1)%3]
+				if (a.Z >= z) == (b.Z >= z) {
+					continue
+				}
+				t := (z - a.Z) / (b.Z - a.Z)
+				points = append(points, Vec2{a.X + t*(b.X-a.X), a.Y + t*(b.Y-a.Y)})
+			}
+			if len(points) == 2 {
+				segments = append(segments, Segment{points[0], points[1]})
+			}
+		}
+	}
+	return segments
+}
+
+// SliceMask rend la coupe du modèle à la hauteur z en masque PBM à dpi points par pouce. Le cadrage
+// est celui de l'emprise XY du modèle entier, pour que toutes les couches se superposent.
+func (model *Model) SliceMask(z, dpi float64) *PBM {
+	low := Vec2{math.Inf(1), math.Inf(1)}
+	high := Vec2{math.Inf(-1), math.Inf(-1)}
+	for _, v := range model.Vertices {
+		low.X, low.Y = math.Min(low.X, v.X), math.Min(low.Y, v.Y)
+		high.X, high.Y = math.Max(high.X, v.X), math.Max(high.Y, v.Y)
+	}
+	if len(model.Vertices) == 0 {
+		return NewPBM(1, 1)
+	}
+	return RasterizeSegments(model.SliceAt(z), low, high, dpi)
+}
@@ -0,0 +1,69 @@
+package netpbm
+
+import "math"
+
+// sobelStep est la norme du gradient de Sobel à la frontière nette entre le noir et le blanc
+// (niveaux ramenés entre 0 et 1) : elle donne le blanc dans la carte des contours.
+const sobelStep = 4.0
+
+// EdgeSobel renvoie la carte des contours de l'image PGM : la norme de son gradient, mesurée par
+// les filtres de Sobel, avec la même valeur maximale que l'image. Un passage franc du noir au
+// blanc donne le blanc ; les bords de l'image sont prolongés.
+func (pgm *PGM) EdgeSobel() *PGM {
+	magnitude, _ := pgm.EdgeSobelDirection()
+	return magnitude
+}
+
+// EdgeSobelDirection renvoie la carte des contours de EdgeSobel et, pour chaque pixel, la
+// direction du gradient en radians, entre -π et π : 0 quand les niveaux croissent vers la
+// droite, π/2 quand ils croissent vers le bas (l'axe Y de l'image est dirigé vers le bas). Les
+// contours sont perpendiculaires à cette direction.
+func (pgm *PGM) EdgeSobelDirection() (*PGM, [][]float64) {
+	magnitude, direction := sobel(pgm.toFloat(false), pgm.max)
+	magnitude.meta = pgm.meta.derive("sobel")
+	return magnitude, direction
+}
+
+// EdgeSobel renvoie la carte des contours de la luminance de l'image PPM (coefficients Rec601),
+// comme PGM.EdgeSobel, avec la même valeur maximale que l'image.
+func (ppm *PPM) EdgeSobel() *PGM {
+	magnitude, _ := ppm.EdgeSobelDirection()
+	return magnitude
+}
+
+// EdgeSobelDirection renvoie la carte des contours de la luminance de l'image PPM et la direction
+// du gradient, comme PGM.EdgeSobelDirection.
+func (ppm *PPM) EdgeSobelDirection() (*PGM, [][]float64) {
+	gray := newFloatImage(ppm.width, ppm.height, 1)
+	scale := 0.0
+	if ppm.max > 0 {
+		scale = 1 / float64(ppm.max)
+	}
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			gray.pix[gray.index(x, y, 0)] = luminance(pixel) * scale
+		}
+	}
+	magnitude, direction := sobel(gray, ppm.max)
+	magnitude.meta = ppm.meta.derive("sobel")
+	return magnitude, direction
+}
+
+// sobel applique les filtres de Sobel à l'image flottante (un canal) et renvoie la norme du
+// gradient, en image PGM de valeur maximale max, et sa direction.
+func sobel(img *floatImage, max int) (*PGM, [][]float64) {
+	magnitude := NewPGM(img.width, img.height, max)
+	direction := make([][]float64, img.height)
+	for y := range direction {
+		direction[y] = make([]float64, img.width)
+		for x := range direction[y] {
+			at := func(dx, dy int) float64 { return img.at(x+dx, y+dy, 0) }
+			gx := at(1, -1) + 2*at(1, 0) + at(1, 1) - at(-1, -1) - 2*at(-1, 0) - at(-1, 1)
+			gy := at(-1, 1) + 2*at(0, 1) + at(1, 1) - at(-1, -1) - 2*at(0, -1) - at(1, -1)
+			norm := math.Min(math.Hypot(gx, gy)/sobelStep, 1)
+			magnitude.data[y][x] = uint8(math.Round(norm * float64(max)))
+			direction[y][x] = math.Atan2(gy, gx)
+		}
+	}
+	return magnitude, direction
+}
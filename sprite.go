@@ -0,0 +1,202 @@
+package netpbm
+
+import "fmt"
+
+// SpriteSheet représente une planche de sprites PPM découpée selon une grille régulière.
+type SpriteSheet struct {
+	Image                     *PPM
+	SpriteWidth, SpriteHeight int
+}
+
+// Columns renvoie le nombre de sprites par ligne de la planche (0 si les dimensions de sprite
+// sont invalides).
+func (sheet *SpriteSheet) Columns() int {
+	if sheet.check() != nil {
+		return 0
+	}
+	return sheet.Image.width / sheet.SpriteWidth
+}
+
+// Count renvoie le nombre de sprites complets contenus dans la planche (0 si les dimensions de
+// sprite sont invalides).
+func (sheet *SpriteSheet) Count() int {
+	if sheet.check() != nil {
+		return 0
+	}
+	return sheet.Columns() * (sheet.Image.height / sheet.SpriteHeight)
+}
+
+// check vérifie que les dimensions de sprite sont strictement positives.
+func (sheet *SpriteSheet) check() error {
+	if sheet.SpriteWidth <= 0 || sheet.SpriteHeight <= 0 {
+		return fmt.Errorf("dimensions de sprite invalides: %dx%d", sheet.SpriteWidth, sheet.SpriteHeight)
+	}
+	return nil
+}
+
+// ExtractSprite renvoie une copie du sprite d'indice donné, numéroté de gauche à droite puis de haut en bas.
+func (sheet *SpriteSheet) ExtractSprite(index int) (*PPM, error) {
+	if err := sheet.check(); err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= sheet.Count() {
+		return nil, fmt.Errorf("indice de sprite hors limites: %d", index)
+	}
+
+	originX := (index % sheet.Columns()) * sheet.SpriteWidth
+	originY := (index / sheet.Columns()) * sheet.SpriteHeight
+
+	sprite := NewPPM(sheet.SpriteWidth, sheet.SpriteHeight, sheet.Image.max)
+	sprite.magicNumber = sheet.Image.magicNumber
+	for y := 0; y < sheet.SpriteHeight; y++ {
+		for x := 0; x < sheet.SpriteWidth; x++ {
+			copy(sprite.data[y][x], sheet.Image.data[originY+y][originX+x])
+		}
+	}
+
+	return sprite, nil
+}
+
+// Frames renvoie tous les sprites de la planche, dans l'ordre des indices.
+func (sheet *SpriteSheet) Frames() ([]*PPM, error) {
+	if err := sheet.check(); err != nil {
+		return nil, err
+	}
+	frames := make([]*PPM, 0, sheet.Count())
+	for i := 0; i < sheet.Count(); i++ {
+		frame, err := sheet.ExtractSprite(i)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// BuildSheet assemble des images de même taille en une planche de cols colonnes.
+// Les cases restantes de la dernière ligne sont laissées noires.
+func BuildSheet(frames []*PPM, cols int) (*PPM, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("aucune image à assembler")
+	}
+	if cols <= 0 {
+		return nil, fmt.Errorf("nombre de colonnes invalide: %d", cols)
+	}
+
+	width, height := frames[0].Size()
+	for i, frame := range frames {
+		if frame.width != width || frame.height != height {
+			return nil, fmt.Errorf("l'image %d mesure %dx%d au lieu de %dx%d", i, frame.width, frame.height, width, height)
+		}
+	}
+
+	rows := (len(frames) + cols - 1) / cols
+	sheet := NewPPM(width*cols, height*rows, frames[0].max)
+	for i, frame := range frames {
+		originX, originY := (i%cols)*width, (i/cols)*height
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				copy(sheet.data[originY+y][originX+x], frame.data[y][x])
+			}
+		}
+	}
+
+	return sheet, nil
+}
+
+// GraySpriteSheet représente une planche de sprites PGM découpée selon une grille régulière.
+type GraySpriteSheet struct {
+	Image                     *PGM
+	SpriteWidth, SpriteHeight int
+}
+
+// Columns renvoie le nombre de sprites par ligne de la planche (0 si les dimensions de sprite
+// sont invalides).
+func (sheet *GraySpriteSheet) Columns() int {
+	if sheet.check() != nil {
+		return 0
+	}
+	return sheet.Image.width / sheet.SpriteWidth
+}
+
+// Count renvoie le nombre de sprites complets contenus dans la planche (0 si les dimensions de
+// sprite sont invalides).
+func (sheet *GraySpriteSheet) Count() int {
+	if sheet.check() != nil {
+		return 0
+	}
+	return sheet.Columns() * (sheet.Image.height / sheet.SpriteHeight)
+}
+
+// check vérifie que les dimensions de sprite sont strictement positives.
+func (sheet *GraySpriteSheet) check() error {
+	if sheet.SpriteWidth <= 0 || sheet.SpriteHeight <= 0 {
+		return fmt.Errorf("dimensions de sprite invalides: %dx%d", sheet.SpriteWidth, sheet.SpriteHeight)
+	}
+	return nil
+}
+
+// ExtractSprite renvoie une copie du sprite d'indice donné, numéroté de gauche à droite puis de haut en bas.
+func (sheet *GraySpriteSheet) ExtractSprite(index int) (*PGM, error) {
+	if err := sheet.check(); err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= sheet.Count() {
+		return nil, fmt.Errorf("indice de sprite hors limites: %d", index)
+	}
+
+	originX := (index % sheet.Columns()) * sheet.SpriteWidth
+	originY := (index / sheet.Columns()) * sheet.SpriteHeight
+
+	sprite := NewPGM(sheet.SpriteWidth, sheet.SpriteHeight, sheet.Image.max)
+	sprite.magicNumber = sheet.Image.magicNumber
+	for y := 0; y < sheet.SpriteHeight; y++ {
+		copy(sprite.data[y], sheet.Image.data[originY+y][originX:originX+sheet.SpriteWidth])
+	}
+
+	return sprite, nil
+}
+
+// Frames renvoie tous les sprites de la planche, dans l'ordre des indices.
+func (sheet *GraySpriteSheet) Frames() ([]*PGM, error) {
+	if err := sheet.check(); err != nil {
+		return nil, err
+	}
+	frames := make([]*PGM, 0, sheet.Count())
+	for i := 0; i < sheet.Count(); i++ {
+		frame, err := sheet.ExtractSprite(i)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// BuildSheetPGM assemble des images PGM de même taille en une planche de cols colonnes.
+func BuildSheetPGM(frames []*PGM, cols int) (*PGM, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("aucune image à assembler")
+	}
+	if cols <= 0 {
+		return nil, fmt.Errorf("nombre de colonnes invalide: %d", cols)
+	}
+
+	width, height := frames[0].Size()
+	for i, frame := range frames {
+		if frame.width != width || frame.height != height {
+			return nil, fmt.Errorf("l'image %d mesure %dx%d au lieu de %dx%d", i, frame.width, frame.height, width, height)
+		}
+	}
+
+	rows := (len(frames) + cols - 1) / cols
+	sheet := NewPGM(width*cols, height*rows, frames[0].max)
+	for i, frame := range frames {
+		originX, originY := (i%cols)*width, (i/cols)*height
+		for y := 0; y < height; y++ {
+			copy(sheet.data[originY+y][originX:originX+width], frame.data[y])
+		}
+	}
+
+	return sheet, nil
+}
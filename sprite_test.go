@@ -0,0 +1,40 @@
+package netpbm
+
+import "testing"
+
+func TestGraySpriteSheetFrames(t *testing.T) {
+	frames := make([]*PGM, 5)
+	for i := range frames {
+		frames[i] = NewPGM(2, 3, 255)
+		frames[i].Set(1, 2, uint8(10*(i+1)))
+	}
+	image, err := BuildSheetPGM(frames, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet := &GraySpriteSheet{Image: image, SpriteWidth: 2, SpriteHeight: 3}
+	got, err := sheet.Frames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// La dernière case de la planche, vide, est aussi un sprite.
+	if len(got) != 6 {
+		t.Fatalf("%d sprites au lieu de 6", len(got))
+	}
+	for i, frame := range frames {
+		if got[i].At(1, 2) != frame.At(1, 2) {
+			t.Errorf("sprite %d: %d au lieu de %d", i, got[i].At(1, 2), frame.At(1, 2))
+		}
+	}
+}
+
+func TestSpriteSheetInvalidSize(t *testing.T) {
+	sheet := &SpriteSheet{Image: NewPPM(4, 4, 255)}
+	if _, err := sheet.Frames(); err == nil {
+		t.Error("taille de sprite nulle acceptée")
+	}
+	gray := &GraySpriteSheet{Image: NewPGM(4, 4, 255), SpriteHeight: 2}
+	if _, err := gray.Frames(); err == nil || gray.Count() != 0 {
+		t.Error("taille de sprite nulle acceptée")
+	}
+}
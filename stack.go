@@ -0,0 +1,130 @@
+package netpbm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// maxClipIterations borne le nombre de passes du rejet par écart type de StackMean.
+const maxClipIterations = 5
+
+// StackMedian empile des images de la même scène, prises du même point de vue, et garde pour
+// chaque composante de chaque pixel la médiane des valeurs : le bruit diminue et ce qui ne
+// figure que sur une minorité d'images (passants, voitures, satellites…) disparaît. Le résultat
+// a la valeur maximale de la première image ; les autres y sont ramenées.
+func StackMedian(frames []*PPM) (*PPM, error) {
+	return stackFrames(frames, "stack median", func(values []float64) float64 {
+		sort.Float64s(values)
+		return median(values)
+	})
+}
+
+// StackMean empile des images comme StackMedian, mais en faisant la moyenne des valeurs, qui
+// réduit mieux le bruit. Si sigma est positif, les valeurs qui s'écartent de la médiane de plus
+// de sigma écarts types sont écartées de la moyenne, à plusieurs reprises tant qu'il en reste :
+// c'est le « sigma clipping » des astronomes, qui élimine les pixels chauds, les rayons
+// cosmiques et les traînées d'avions. Une valeur de 2 à 3 convient le plus souvent.
+func StackMean(frames []*PPM, sigma float64) (*PPM, error) {
+	return stackFrames(frames, fmt.Sprintf("stack mean %g", sigma), func(values []float64) float64 {
+		if sigma > 0 {
+			values = sigmaClip(values, sigma)
+		}
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	})
+}
+
+// stackFrames vérifie les images puis combine, par combine, les valeurs de chaque composante de
+// chaque pixel. combine peut réordonner la tranche qu'il reçoit.
+func stackFrames(frames []*PPM, operation string, combine func(values []float64) float64) (*PPM, error) {
+	if err := checkStack(frames); err != nil {
+		return nil, err
+	}
+	first, scales := frames[0], stackScales(frames)
+	result := NewPPM(first.width, first.height, first.max)
+	values := make([]float64, len(frames))
+	for y, row := range result.data {
+		for x, pixel := range row {
+			for c := range pixel {
+				for i, frame := range frames {
+					values[i] = float64(frame.data[y][x][c]) * scales[i]
+				}
+				pixel[c] = uint8(clampFloat(math.Round(combine(values)), 0, float64(first.max)))
+			}
+		}
+	}
+	result.meta = first.meta.derive(operation)
+	return result, nil
+}
+
+// checkStack vérifie qu'il y a des images à empiler et qu'elles ont toutes la même taille.
+func checkStack(frames []*PPM) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("aucune image à empiler")
+	}
+	first := frames[0]
+	for i, frame := range frames {
+		if frame.width != first.width || frame.height != first.height {
+			return fmt.Errorf("l'image %d mesure %dx%d au lieu de %dx%d", i, frame.width, frame.height, first.width, first.height)
+		}
+	}
+	return nil
+}
+
+// stackScales renvoie les facteurs qui ramènent chaque image à la valeur maximale de la première.
+func stackScales(frames []*PPM) []float64 {
+	scales := make([]float64, len(frames))
+	for i, frame := range frames {
+		scales[i] = 1
+		if frame.max > 0 {
+			scales[i] = float64(frames[0].max) / float64(frame.max)
+		}
+	}
+	return scales
+}
+
+// median renvoie la médiane de values, déjà triées.
+func median(values []float64) float64 {
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// sigmaClip renvoie les valeurs qui ne s'écartent pas de la médiane de plus de sigma écarts
+// types, en recommençant sur les valeurs gardées jusqu'à ce qu'aucune ne soit plus rejetée. Les
+// valeurs sont triées sur place et le résultat en est une sous-tranche.
+func sigmaClip(values []float64, sigma float64) []float64 {
+	sort.Float64s(values)
+	for i := 0; i < maxClipIterations && len(values) > 2; i++ {
+		center := median(values)
+		mean, variance := 0.0, 0.0
+		for _, v := range values {
+			mean += v
+		}
+		mean /= float64(len(values))
+		for _, v := range values {
+			variance += (v - mean) * (v - mean)
+		}
+		limit := sigma * math.Sqrt(variance/float64(len(values)))
+
+		// Les valeurs étant triées, les valeurs gardées forment un intervalle.
+		low, high := 0, len(values)
+		for low < high && values[low] < center-limit {
+			low++
+		}
+		for high > low && values[high-1] > center+limit {
+			high--
+		}
+		if low == 0 && high == len(values) {
+			break
+		}
+		values = values[low:high]
+	}
+	return values
+}
@@ -0,0 +1,77 @@
+package netpbm
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// scaleTo8 ramène une valeur comprise entre 0 et max sur 8 bits.
+func scaleTo8(value uint8, max int) uint8 {
+	if max <= 0 || max == 255 {
+		return value
+	}
+	return uint8((int(value)*255 + max/2) / max)
+}
+
+// toStdImage convertit l'image en image de la bibliothèque standard (image.Gray ou image.RGBA),
+// pour les encodeurs PNG et JPEG. Les valeurs sont ramenées sur 8 bits.
+func toStdImage(img Image) (image.Image, error) {
+	switch img := img.(type) {
+	case *PBM:
+		gray := image.NewGray(image.Rect(0, 0, img.width, img.height))
+		for y := 0; y < img.height; y++ {
+			for x := 0; x < img.width; x++ {
+				if !img.data[y][x] {
+					gray.SetGray(x, y, color.Gray{Y: 255})
+				}
+			}
+		}
+		return gray, nil
+	case *PGM:
+		gray := image.NewGray(image.Rect(0, 0, img.width, img.height))
+		for y := 0; y < img.height; y++ {
+			for x := 0; x < img.width; x++ {
+				gray.SetGray(x, y, color.Gray{Y: scaleTo8(img.data[y][x], img.max)})
+			}
+		}
+		return gray, nil
+	case *PPM:
+		rgba := image.NewRGBA(image.Rect(0, 0, img.width, img.height))
+		for y := 0; y < img.height; y++ {
+			for x := 0; x < img.width; x++ {
+				value := img.data[y][x]
+				rgba.SetRGBA(x, y, color.RGBA{
+					R: scaleTo8(value[0], img.max),
+					G: scaleTo8(value[1], img.max),
+					B: scaleTo8(value[2], img.max),
+					A: 255,
+				})
+			}
+		}
+		return rgba, nil
+	}
+	return nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+}
+
+// FromImage convertit une image de la bibliothèque standard (photo décodée par image/png,
+// image/jpeg…) en image PPM de valeur maximale maxValue (de 1 à 255), pour lui appliquer les
+// fonctions de dessin et les filtres du paquet. Comme pour DecodeFarbfeld, la transparence est
+// ignorée : chaque pixel garde sa couleur, qu'il soit opaque ou non.
+func FromImage(img image.Image, maxValue int) (*PPM, error) {
+	if maxValue < 1 || maxValue > 255 {
+		return nil, fmt.Errorf("valeur maximale invalide: %d (entre 1 et 255)", maxValue)
+	}
+	bounds := img.Bounds()
+	ppm := NewPPM(bounds.Dx(), bounds.Dy(), maxValue)
+	scale := func(v uint16) uint8 {
+		return uint8((uint32(v)*uint32(maxValue) + 32767) / 65535)
+	}
+	for y, row := range ppm.data {
+		for x, pixel := range row {
+			c := color.NRGBA64Model.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA64)
+			pixel[0], pixel[1], pixel[2] = scale(c.R), scale(c.G), scale(c.B)
+		}
+	}
+	return ppm, nil
+}
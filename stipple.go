@@ -0,0 +1,131 @@
+package netpbm
+
+import (
+	"math"
+	"math/rand"
+)
+
+// poissonCandidates est le nombre d'essais autour d'un point actif avant de l'abandonner (algorithme de Bridson).
+const poissonCandidates = 30
+
+// PoissonDisk génère un échantillonnage en disque de Poisson (bruit bleu) de la zone width×height :
+// deux points sont toujours séparés d'au moins radius. La graine rend le résultat reproductible.
+func PoissonDisk(width, height int, radius float64, seed int64) []Point {
+	return poissonSample(width, height, radius, radius, seed, func(x, y float64) float64 { return radius })
+}
+
+// BlueNoiseMask renvoie un masque PBM dont les pixels allumés forment un échantillonnage en disque de Poisson.
+func BlueNoiseMask(width, height int, radius float64, seed int64) *PBM {
+	mask := NewPBM(width, height)
+	for _, p := range PoissonDisk(width, height, radius, seed) {
+		mask.data[p.Y][p.X] = true
+	}
+	return mask
+}
+
+// Stipple convertit l'image PGM en pointillé PBM : la densité de points suit l'obscurité de l'image.
+// L'espacement entre points varie de minSpacing (pixels noirs) à maxSpacing (pixels blancs).
+func (pgm *PGM) Stipple(minSpacing, maxSpacing float64, seed int64) *PBM {
+	if minSpacing < 1 {
+		minSpacing = 1
+	}
+	if maxSpacing < minSpacing {
+		maxSpacing = minSpacing
+	}
+	scale := float64(pgm.max)
+	if scale <= 0 {
+		scale = 255
+	}
+
+	spacing := func(x, y float64) float64 {
+		brightness := float64(pgm.data[int(y)][int(x)]) / scale
+		return minSpacing + (maxSpacing-minSpacing)*brightness
+	}
+
+	stipple := NewPBM(pgm.width, pgm.height)
+	stipple.meta = pgm.meta.derive("stipple %g %g", minSpacing, maxSpacing)
+	for _, p := range poissonSample(pgm.width, pgm.height, minSpacing, maxSpacing, seed, spacing) {
+		// Les pixels blancs purs ne reçoivent aucun point.
+		if pgm.data[p.Y][p.X] < uint8(pgm.max) {
+			stipple.data[p.Y][p.X] = true
+		}
+	}
+	return stipple
+}
+
+// poissonSample implémente l'algorithme de Bridson avec un rayon d'exclusion variable radiusAt(x, y),
+// compris entre minRadius et maxRadius.
+func poissonSample(width, height int, minRadius, maxRadius float64, seed int64, radiusAt func(x, y float64) float64) []Point {
+	if width <= 0 || height <= 0 || minRadius <= 0 {
+		return nil
+	}
+
+	type sample struct{ x, y float64 }
+
+	random := rand.New(rand.NewSource(seed))
+	cellSize := minRadius / math.Sqrt2
+	cols := int(math.Ceil(float64(width)/cellSize)) + 1
+	rows := int(math.Ceil(float64(height)/cellSize)) + 1
+	grid := make([][]int, cols*rows)
+	reach := int(math.Ceil(maxRadius / cellSize))
+
+	var samples []sample
+	var active []int
+
+	fits := func(s sample) bool {
+		r := radiusAt(s.x, s.y)
+		cx, cy := int(s.x/cellSize), int(s.y/cellSize)
+		for gy := max(cy-reach, 0); gy <= min(cy+reach, rows-1); gy++ {
+			for gx := max(cx-reach, 0); gx <= min(cx+reach, cols-1); gx++ {
+				for _, index := range grid[gy*cols+gx] {
+					other := samples[index]
+					if math.Hypot(other.x-s.x, other.y-s.y) < r {
+						return false
+					}
+				}
+			}
+		}
+		return true
+	}
+
+	add := func(s sample) {
+		samples = append(samples, s)
+		index := len(samples) - 1
+		cell := int(s.y/cellSize)*cols + int(s.x/cellSize)
+		grid[cell] = append(grid[cell], index)
+		active = append(active, index)
+	}
+
+	add(sample{random.Float64() * float64(width), random.Float64() * float64(height)})
+	for len(active) > 0 {
+		i := random.Intn(len(active))
+		origin := samples[active[i]]
+		r := radiusAt(origin.x, origin.y)
+
+		found := false
+		for k := 0; k < poissonCandidates; k++ {
+			angle := random.Float64() * 2 * math.Pi
+			distance := r * (1 + random.Float64())
+			candidate := sample{origin.x + distance*math.Cos(angle), origin.y + distance*math.Sin(angle)}
+			if candidate.x < 0 || candidate.x >= float64(width) || candidate.y < 0 || candidate.y >= float64(height) {
+				continue
+			}
+			if fits(candidate) {
+				add(candidate)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			active[i] = active[len(active)-1]
+			active = active[:len(active)-1]
+		}
+	}
+
+	points := make([]Point, len(samples))
+	for i, s := range samples {
+		points[i] = Point{X: int(s.x), Y: int(s.y)}
+	}
+	return points
+}
@@ -0,0 +1,100 @@
+package netpbm
+
+import "math"
+
+// luminance renvoie la luminance Rec. 601 d'une valeur RVB.
+func luminance(value []uint8) float64 {
+	return 0.299*float64(value[0]) + 0.587*float64(value[1]) + 0.114*float64(value[2])
+}
+
+// Kuwahara renvoie une copie de l'image PPM filtrée par le filtre de Kuwahara : chaque pixel prend
+// la couleur moyenne de celui de ses quatre quadrants (de côté radius+1) dont la luminance varie le
+// moins. Les contours sont conservés tandis que les zones uniformes sont lissées, d'où un rendu de peinture.
+func (ppm *PPM) Kuwahara(radius int) *PPM {
+	if radius < 1 {
+		return ppm.Copy()
+	}
+
+	result := ppm.mapNeighborhoods(func(x, y int) []uint8 {
+		quadrants := [4][2]int{{-radius, -radius}, {0, -radius}, {-radius, 0}, {0, 0}}
+
+		bestVariance := math.Inf(1)
+		var best [3]float64
+		for _, q := range quadrants {
+			x0, y0 := x+q[0], y+q[1]
+
+			var sum [3]float64
+			var sumL, sumL2 float64
+			n := 0
+			ppm.forEachNeighbor(x0, y0, x0+radius, y0+radius, func(value []uint8) {
+				for c := 0; c < 3; c++ {
+					sum[c] += float64(value[c])
+				}
+				l := luminance(value)
+				sumL += l
+				sumL2 += l * l
+				n++
+			})
+			if n == 0 {
+				continue
+			}
+
+			mean := sumL / float64(n)
+			variance := sumL2/float64(n) - mean*mean
+			if variance < bestVariance {
+				bestVariance = variance
+				for c := 0; c < 3; c++ {
+					best[c] = sum[c] / float64(n)
+				}
+			}
+		}
+
+		return []uint8{uint8(math.Round(best[0])), uint8(math.Round(best[1])), uint8(math.Round(best[2]))}
+	})
+	result.meta = ppm.meta.derive("kuwahara %d", radius)
+	return result
+}
+
+// OilPaint renvoie une copie de l'image PPM stylisée en peinture à l'huile : les pixels du voisinage
+// de rayon radius sont répartis en levels niveaux d'intensité, et chaque pixel prend la couleur moyenne
+// du niveau le plus représenté (le mode du voisinage). Un rayon négatif compte pour 0.
+func (ppm *PPM) OilPaint(radius, levels int) *PPM {
+	radius = max(radius, 0)
+	if levels < 1 {
+		levels = 1
+	}
+	scale := float64(ppm.max)
+	if scale <= 0 {
+		scale = 255
+	}
+
+	counts := make([]int, levels)
+	sums := make([][3]int, levels)
+	result := ppm.mapNeighborhoods(func(x, y int) []uint8 {
+		for i := range counts {
+			counts[i] = 0
+			sums[i] = [3]int{}
+		}
+
+		ppm.forEachNeighbor(x-radius, y-radius, x+radius, y+radius, func(value []uint8) {
+			level := int(luminance(value)/scale*float64(levels-1) + 0.5)
+			level = clampInt(level, 0, levels-1)
+			counts[level]++
+			for c := 0; c < 3; c++ {
+				sums[level][c] += int(value[c])
+			}
+		})
+
+		mode := 0
+		for i := range counts {
+			if counts[i] > counts[mode] {
+				mode = i
+			}
+		}
+
+		n := counts[mode]
+		return []uint8{uint8(sums[mode][0] / n), uint8(sums[mode][1] / n), uint8(sums[mode][2] / n)}
+	})
+	result.meta = ppm.meta.derive("oilpaint %d %d", radius, levels)
+	return result
+}
@@ -0,0 +1,150 @@
+package netpbm
+
+// Supersampled est un canevas de dessin suréchantillonné : les primitives sont tracées
+// sur une image interne factor fois plus grande, puis réduites par moyenne lors de
+// l'aplatissement, ce qui donne un anticrénelage global.
+// Si LinearLight est vrai, la moyenne est calculée en lumière linéaire, ce qui évite que les
+// contours anticrénelés paraissent trop sombres.
+type Supersampled struct {
+	LinearLight bool
+
+	canvas *PPM
+	factor int
+	width  int
+	height int
+}
+
+// Supersample renvoie un canevas suréchantillonné initialisé avec le contenu de l'image PPM.
+// Un facteur inférieur à 1 est ramené à 1.
+func (ppm *PPM) Supersample(factor int) *Supersampled {
+	if factor < 1 {
+		factor = 1
+	}
+
+	canvas := NewPPM(ppm.width*factor, ppm.height*factor, ppm.max)
+	canvas.magicNumber = ppm.magicNumber
+	for y := 0; y < canvas.height; y++ {
+		for x := 0; x < canvas.width; x++ {
+			copy(canvas.data[y][x], ppm.data[y/factor][x/factor])
+		}
+	}
+
+	return &Supersampled{canvas: canvas, factor: factor, width: ppm.width, height: ppm.height}
+}
+
+// Canvas renvoie l'image interne suréchantillonnée, pour les opérations qui ne sont pas encapsulées.
+func (ss *Supersampled) Canvas() *PPM {
+	return ss.canvas
+}
+
+// Factor renvoie le facteur de suréchantillonnage.
+func (ss *Supersampled) Factor() int {
+	return ss.factor
+}
+
+// scale convertit un point de l'image finale en point du canevas (centre de la cellule).
+func (ss *Supersampled) scale(p Point) Point {
+	return Point{X: p.X*ss.factor + ss.factor/2, Y: p.Y*ss.factor + ss.factor/2}
+}
+
+// stroke trace une primitive de contour avec une épaisseur d'un pixel final, en la répétant
+// avec tous les décalages d'une cellule du canevas.
+func (ss *Supersampled) stroke(draw func(offset Point)) {
+	half := ss.factor / 2
+	for dy := 0; dy < ss.factor; dy++ {
+		for dx := 0; dx < ss.factor; dx++ {
+			draw(Point{X: dx - half, Y: dy - half})
+		}
+	}
+}
+
+// translate décale un point.
+func translate(p, offset Point) Point {
+	return Point{X: p.X + offset.X, Y: p.Y + offset.Y}
+}
+
+// DrawLine trace une ligne entre deux points.
+func (ss *Supersampled) DrawLine(p1, p2 Point, color Pixel) {
+	a, b := ss.scale(p1), ss.scale(p2)
+	ss.stroke(func(offset Point) {
+		ss.canvas.DrawLine(translate(a, offset), translate(b, offset), color)
+	})
+}
+
+// DrawTriangle dessine un triangle.
+func (ss *Supersampled) DrawTriangle(p1, p2, p3 Point, color Pixel) {
+	ss.DrawLine(p1, p2, color)
+	ss.DrawLine(p2, p3, color)
+	ss.DrawLine(p3, p1, color)
+}
+
+// DrawFilledTriangle dessine un triangle rempli.
+func (ss *Supersampled) DrawFilledTriangle(p1, p2, p3 Point, color Pixel) {
+	ss.DrawFilledPolygon([]Point{p1, p2, p3}, color)
+}
+
+// DrawPolygon dessine un polygone.
+func (ss *Supersampled) DrawPolygon(points []Point, color Pixel) {
+	for i := range points {
+		ss.DrawLine(points[i], points[(i+1)%len(points)], color)
+	}
+}
+
+// DrawFilledPolygon dessine un polygone rempli.
+func (ss *Supersampled) DrawFilledPolygon(points []Point, color Pixel) {
+	scaled := make([]Point, len(points))
+	for i, p := range points {
+		scaled[i] = ss.scale(p)
+	}
+	ss.canvas.DrawFilledPolygon(scaled, color)
+}
+
+// DrawFilledRectangle dessine un rectangle rempli.
+func (ss *Supersampled) DrawFilledRectangle(p1 Point, width, height int, color Pixel) {
+	ss.canvas.DrawFilledRectangle(Point{X: p1.X * ss.factor, Y: p1.Y * ss.factor}, width*ss.factor, height*ss.factor, color)
+}
+
+// DrawCircle dessine un cercle.
+func (ss *Supersampled) DrawCircle(center Point, radius int, color Pixel) {
+	c := ss.scale(center)
+	ss.stroke(func(offset Point) {
+		ss.canvas.DrawCircle(translate(c, offset), radius*ss.factor, color)
+	})
+}
+
+// DrawFilledCircle dessine un cercle rempli.
+func (ss *Supersampled) DrawFilledCircle(center Point, radius int, color Pixel) {
+	ss.canvas.DrawFilledCircle(ss.scale(center), radius*ss.factor, color)
+}
+
+// Flatten réduit le canevas à la taille finale en moyennant chaque cellule factor×factor.
+func (ss *Supersampled) Flatten() *PPM {
+	table := decodeTable(ss.canvas.max, ss.LinearLight)
+	result := NewPPM(ss.width, ss.height, ss.canvas.max)
+	result.magicNumber = ss.canvas.magicNumber
+	area := float64(ss.factor * ss.factor)
+
+	for y := 0; y < ss.height; y++ {
+		for x := 0; x < ss.width; x++ {
+			var sum [3]float64
+			for dy := 0; dy < ss.factor; dy++ {
+				for dx := 0; dx < ss.factor; dx++ {
+					value := ss.canvas.data[y*ss.factor+dy][x*ss.factor+dx]
+					for k := 0; k < 3; k++ {
+						sum[k] += table[value[k]]
+					}
+				}
+			}
+			for k := 0; k < 3; k++ {
+				result.data[y][x][k] = encodeValue(sum[k]/area, ss.canvas.max, ss.LinearLight)
+			}
+		}
+	}
+
+	return result
+}
+
+// Save réduit le canevas puis enregistre l'image dans un fichier.
+func (ss *Supersampled) Save(filename string) error {
+	return ss.Flatten().Save(filename)
+}
@@ -0,0 +1,76 @@
+package netpbm
+
+import "math"
+
+// Symétrie du dessin : une fois SetMirrorSymmetry ou SetRotationalSymmetry appelée, chaque méthode
+// de dessin géométrique de l'image PPM (celles qui suivent Translate, voir transform.go) dessine sa
+// forme plusieurs fois, réfléchie ou tournée dans le repère de l'image, comme les modes miroir et
+// kaléidoscope des logiciels de dessin. Les copies sont dessinées dans l'ordre, la forme d'origine
+// en premier. Les formes remplies et les tracés sont rendus exactement symétriques ; les lignes d'un
+// pixel de DrawLine peuvent différer d'un pixel d'une copie à l'autre.
+
+// SetMirrorSymmetry répète chaque dessin en miroir par rapport à l'axe vertical x = center.X si
+// vertical est vrai et à l'axe horizontal y = center.Y si horizontal est vrai (quatre copies avec
+// les deux). Les coordonnées de center sont celles des tracés : le centre de l'image est en
+// (largeur/2, hauteur/2).
+func (ppm *PPM) SetMirrorSymmetry(center Vec2, vertical, horizontal bool) {
+	ppm.symmetry = []Homography{identity}
+	if vertical {
+		ppm.symmetry = append(ppm.symmetry, aroundPoint(center, Homography{{-1, 0, 0}, {0, 1, 0}, {0, 0, 1}}))
+	}
+	if horizontal {
+		for _, h := range ppm.symmetry {
+			ppm.symmetry = append(ppm.symmetry, aroundPoint(center, Homography{{1, 0, 0}, {0, -1, 0}, {0, 0, 1}}).multiply(h))
+		}
+	}
+}
+
+// SetRotationalSymmetry répète chaque dessin folds fois, tourné de 360°/folds à chaque fois autour
+// de center. Avec mirror, chaque copie est aussi réfléchie (2×folds copies), ce qui donne l'effet
+// d'un kaléidoscope.
+func (ppm *PPM) SetRotationalSymmetry(center Vec2, folds int, mirror bool) {
+	ppm.symmetry = nil
+	folds = max(folds, 1)
+	reflection := aroundPoint(center, Homography{{-1, 0, 0}, {0, 1, 0}, {0, 0, 1}})
+	for i := 0; i < folds; i++ {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(folds))
+		rotation := aroundPoint(center, Homography{{cos, -sin, 0}, {sin, cos, 0}, {0, 0, 1}})
+		ppm.symmetry = append(ppm.symmetry, rotation)
+		if mirror {
+			ppm.symmetry = append(ppm.symmetry, rotation.multiply(reflection))
+		}
+	}
+}
+
+// ClearSymmetry revient au dessin sans répétition.
+func (ppm *PPM) ClearSymmetry() {
+	ppm.symmetry = nil
+}
+
+// aroundPoint renvoie la transformation h appliquée autour de center plutôt que de l'origine.
+func aroundPoint(center Vec2, h Homography) Homography {
+	to := Homography{{1, 0, center.X}, {0, 1, center.Y}, {0, 0, 1}}
+	from := Homography{{1, 0, -center.X}, {0, 1, -center.Y}, {0, 0, 1}}
+	return to.multiply(h).multiply(from)
+}
+
+// repeatSymmetric appelle draw une fois par copie de la symétrie en cours, chacune dans son repère,
+// et renvoie true ; sans symétrie, ou pendant l'une de ces copies (une primitive qui en appelle
+// une autre), il renvoie false et la méthode appelante dessine normalement.
+func (ppm *PPM) repeatSymmetric(draw func()) bool {
+	if len(ppm.symmetry) == 0 || ppm.repeating {
+		return false
+	}
+	base := ppm.transform
+	ppm.repeating = true
+	for _, h := range ppm.symmetry {
+		// Même la copie d'origine passe par un repère explicite : toutes les copies sont alors
+		// rendues par le même code et restent exactement symétriques.
+		ppm.transform = base
+		composed := h.multiply(ppm.Transform())
+		ppm.transform = &composed
+		draw()
+	}
+	ppm.transform, ppm.repeating = base, false
+	return true
+}
@@ -0,0 +1,107 @@
+// Package testutil aide à tester du code qui produit des images Netpbm, en les comparant à des
+// images de référence (« golden files ») enregistrées avec les tests.
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	netpbm "github.com/eliiimk/Netpbm"
+)
+
+// UpdateGoldenEnv est la variable d'environnement qui, à 1, fait réécrire les images de référence
+// par AssertImagesEqual au lieu de les comparer (go test après une modification voulue du rendu).
+const UpdateGoldenEnv = "NETPBM_UPDATE_GOLDEN"
+
+// AssertImagesEqual compare l'image got à l'image de référence enregistrée dans wantFile, chaque
+// composante pouvant s'en écarter de tolerance au plus, et signale l'échec du test sinon. Les deux
+// images sont comparées en couleurs, quel que soit leur type. En cas d'échec, deux fichiers sont
+// écrits à côté de la référence pour examiner la différence : l'image obtenue (nom.got.ext) et
+// la référence où les pixels différents sont peints en rouge et les zones encadrées (nom.diff.ppm).
+func AssertImagesEqual(t testing.TB, got netpbm.Image, wantFile string, tolerance int) bool {
+	t.Helper()
+
+	if os.Getenv(UpdateGoldenEnv) == "1" {
+		if err := netpbm.SaveImage(wantFile, got, netpbm.EncodeOptions{}); err != nil {
+			t.Errorf("écriture de la référence %s: %v", wantFile, err)
+			return false
+		}
+		return true
+	}
+
+	want, err := netpbm.ReadImage(wantFile)
+	if err != nil {
+		t.Errorf("lecture de la référence %s: %v (relancer avec %s=1 pour la créer)", wantFile, err, UpdateGoldenEnv)
+		return false
+	}
+	gotColor, err := toColor(got)
+	if err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	wantColor, err := toColor(want)
+	if err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+
+	base := strings.TrimSuffix(wantFile, filepath.Ext(wantFile))
+	gotFile := base + ".got" + filepath.Ext(wantFile)
+	gotWidth, gotHeight := gotColor.Size()
+	wantWidth, wantHeight := wantColor.Size()
+	if gotWidth != wantWidth || gotHeight != wantHeight {
+		netpbm.SaveImage(gotFile, got, netpbm.EncodeOptions{})
+		t.Errorf("%s: taille %dx%d, %dx%d attendue (image obtenue: %s)",
+			wantFile, gotWidth, gotHeight, wantWidth, wantHeight, gotFile)
+		return false
+	}
+
+	count, worst := 0, 0
+	for y := 0; y < gotHeight; y++ {
+		for x := 0; x < gotWidth; x++ {
+			gotPixel, wantPixel := gotColor.At(x, y), wantColor.At(x, y)
+			delta := 0
+			for k := 0; k < 3; k++ {
+				delta = max(delta, abs(int(gotPixel[k])-int(wantPixel[k])))
+			}
+			if delta > tolerance {
+				count++
+			}
+			worst = max(worst, delta)
+		}
+	}
+	if count == 0 {
+		return true
+	}
+
+	diffFile := base + ".diff.ppm"
+	netpbm.SaveImage(gotFile, got, netpbm.EncodeOptions{})
+	overlay, regions, _ := netpbm.DiffOverlay(wantColor, gotColor, netpbm.Pixel{Red: 255}, netpbm.DiffOptions{
+		Tolerance: tolerance,
+		Boxes:     true,
+		BoxColor:  netpbm.Pixel{Red: 255, Green: 255},
+	})
+	netpbm.SaveImage(diffFile, overlay, netpbm.EncodeOptions{})
+	t.Errorf("%s: %d pixels différents dans %d zones (écart maximal %d, tolérance %d) ; image obtenue: %s, différences: %s",
+		wantFile, count, len(regions), worst, tolerance, gotFile, diffFile)
+	return false
+}
+
+// toColor convertit l'image en PPM, quel que soit son type.
+func toColor(img netpbm.Image) (*netpbm.PPM, error) {
+	color, err := netpbm.Convert(img, netpbm.FormatP3, netpbm.ConvertOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return color.(*netpbm.PPM), nil
+}
+
+// abs renvoie la valeur absolue d'un entier.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,42 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseTextArt construit une image PBM à partir d'un dessin en texte, une ligne de texte par ligne
+// de l'image : les caractères de setChars donnent des pixels noirs, tous les autres des pixels
+// blancs. L'image a la largeur de la plus longue ligne, les lignes plus courtes étant complétées
+// de blanc. C'est pratique pour définir de petits masques ou sprites dans le code :
+//
+//	mask, err := ParseTextArt(strings.NewReader(".#.\n###\n.#."), "#")
+func ParseTextArt(r io.Reader, setChars string) (*PBM, error) {
+	var lines []string
+	width := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		lines = append(lines, line)
+		width = max(width, utf8.RuneCountInString(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if width == 0 {
+		return nil, fmt.Errorf("dessin vide")
+	}
+
+	pbm := NewPBM(width, len(lines))
+	for y, line := range lines {
+		x := 0
+		for _, c := range line {
+			pbm.data[y][x] = strings.ContainsRune(setChars, c)
+			x++
+		}
+	}
+	return pbm, nil
+}
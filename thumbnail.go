@@ -0,0 +1,60 @@
+package netpbm
+
+// thumbnailSize renvoie la taille d'une vignette de l'image width×height dont le plus grand côté
+// mesure au plus maxDim pixels, proportions conservées.
+func thumbnailSize(width, height, maxDim int) (int, int) {
+	if width <= maxDim && height <= maxDim {
+		return width, height
+	}
+	if width >= height {
+		return maxDim, max(1, (height*maxDim+width/2)/width)
+	}
+	return max(1, (width*maxDim+height/2)/height), maxDim
+}
+
+// unsharp accentue l'image flottante par masque flou : chaque composante est écartée de amount
+// fois sa différence avec l'image floutée par un flou gaussien d'écart type sigma.
+func (img *floatImage) unsharp(sigma, amount float64) *floatImage {
+	blurred := img.blur(sigma)
+	for i, v := range img.pix {
+		blurred.pix[i] = v + amount*(v-blurred.pix[i])
+	}
+	return blurred
+}
+
+// Réglages de l'accentuation des vignettes : assez légère pour ne pas créer de halo, elle rend
+// le piqué que la réduction a moyenné.
+const (
+	thumbnailSharpenSigma  = 0.6
+	thumbnailSharpenAmount = 0.4
+)
+
+// Thumbnail renvoie une vignette de l'image PPM dont le plus grand côté mesure au plus maxDim
+// pixels. La réduction filtre tous les pixels sources en lumière linéaire, pour ne pas assombrir
+// les détails fins, puis la vignette est légèrement accentuée. Une image déjà assez petite n'est
+// pas agrandie, ni accentuée.
+func (ppm *PPM) Thumbnail(maxDim int) *PPM {
+	width, height := thumbnailSize(ppm.width, ppm.height, maxDim)
+	if width == ppm.width && height == ppm.height {
+		return ppm.Copy()
+	}
+	result := ppm.toFloat(true).resize(width, height).
+		unsharp(thumbnailSharpenSigma, thumbnailSharpenAmount).toPPM(ppm.max, true)
+	result.magicNumber = ppm.magicNumber
+	result.meta = ppm.meta.derive("thumbnail %d", maxDim)
+	return result
+}
+
+// Thumbnail renvoie une vignette de l'image PGM dont le plus grand côté mesure au plus maxDim
+// pixels, comme PPM.Thumbnail.
+func (pgm *PGM) Thumbnail(maxDim int) *PGM {
+	width, height := thumbnailSize(pgm.width, pgm.height, maxDim)
+	if width == pgm.width && height == pgm.height {
+		return pgm.Copy()
+	}
+	result := pgm.toFloat(true).resize(width, height).
+		unsharp(thumbnailSharpenSigma, thumbnailSharpenAmount).toPGM(pgm.max, true)
+	result.magicNumber = pgm.magicNumber
+	result.meta = pgm.meta.derive("thumbnail %d", maxDim)
+	return result
+}
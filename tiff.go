@@ -0,0 +1,111 @@
+package netpbm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// packBits compresse une ligne selon le schéma PackBits (TIFF, compression 32773) : chaque
+// paquet commence par un octet n, suivi de n+1 octets littéraux si n est entre 0 et 127, ou d'un
+// octet à répéter 1-n fois si n est entre -127 et -1. Les grandes plages blanches ou noires d'un
+// document numérisé tiennent ainsi en deux octets par tranche de 128 octets.
+func packBits(dst, src []byte) []byte {
+	for i := 0; i < len(src); {
+		// Longueur de la répétition qui commence en i.
+		run := 1
+		for i+run < len(src) && run < 128 && src[i+run] == src[i] {
+			run++
+		}
+		if run >= 2 {
+			dst = append(dst, byte(1-run), src[i])
+			i += run
+			continue
+		}
+		// Littéraux jusqu'à la prochaine répétition d'au moins trois octets.
+		start := i
+		for i < len(src) && i-start < 128 {
+			if i+2 < len(src) && src[i] == src[i+1] && src[i] == src[i+2] {
+				break
+			}
+			i++
+		}
+		dst = append(dst, byte(i-start-1))
+		dst = append(dst, src[start:i]...)
+	}
+	return dst
+}
+
+// Étiquettes TIFF écrites par ExportTIFF.
+const (
+	tiffImageWidth      = 256
+	tiffImageLength     = 257
+	tiffBitsPerSample   = 258
+	tiffCompression     = 259
+	tiffPhotometric     = 262
+	tiffStripOffsets    = 273
+	tiffSamplesPerPixel = 277
+	tiffRowsPerStrip    = 278
+	tiffStripByteCounts = 279
+	tiffXResolution     = 282
+	tiffYResolution     = 283
+	tiffResolutionUnit  = 296
+)
+
+// ExportTIFF écrit l'image dans un fichier TIFF bitonal compressé en PackBits, bien plus compact
+// qu'un fichier P4 pour un document numérisé, et lisible par tous les logiciels d'archivage. dpi
+// est la résolution inscrite dans le fichier (72 si elle n'est pas positive).
+func (packed *PackedPBM) ExportTIFF(w io.Writer, dpi float64) error {
+	if !(dpi > 0) {
+		dpi = 72
+	}
+	var strip []byte
+	for y := 0; y < packed.height; y++ {
+		strip = packBits(strip, packed.row(y))
+	}
+
+	// En-tête, puis la bande compressée, puis le répertoire et la résolution.
+	const entries = 12
+	ifdOffset := 8 + uint32(len(strip)+len(strip)%2)
+	resolutionOffset := ifdOffset + 2 + 12*entries + 4
+	order := binary.LittleEndian
+	writer := bufio.NewWriter(w)
+	writer.Write([]byte{'I', 'I', 42, 0})
+	binary.Write(writer, order, ifdOffset)
+	writer.Write(strip)
+	if len(strip)%2 == 1 {
+		writer.WriteByte(0) // Le répertoire commence à une adresse paire.
+	}
+
+	// Chaque entrée : étiquette, type (3 = SHORT, 4 = LONG, 5 = RATIONAL), nombre, valeur.
+	entry := func(tag, kind uint16, value uint32) {
+		binary.Write(writer, order, [2]uint16{tag, kind})
+		binary.Write(writer, order, [2]uint32{1, value})
+	}
+	binary.Write(writer, order, uint16(entries))
+	entry(tiffImageWidth, 4, uint32(packed.width))
+	entry(tiffImageLength, 4, uint32(packed.height))
+	entry(tiffBitsPerSample, 3, 1)
+	entry(tiffCompression, 3, 32773) // PackBits
+	entry(tiffPhotometric, 3, 0)     // WhiteIsZero : 1 est noir, comme en PBM
+	entry(tiffStripOffsets, 4, 8)
+	entry(tiffSamplesPerPixel, 3, 1)
+	entry(tiffRowsPerStrip, 4, uint32(packed.height))
+	entry(tiffStripByteCounts, 4, uint32(len(strip)))
+	entry(tiffXResolution, 5, resolutionOffset)
+	entry(tiffYResolution, 5, resolutionOffset)
+	entry(tiffResolutionUnit, 3, 2) // Pouce
+	binary.Write(writer, order, uint32(0))
+	binary.Write(writer, order, [2]uint32{uint32(math.Round(dpi * 100)), 100})
+	return writer.Flush()
+}
+
+// ExportTIFF écrit l'image PBM dans un fichier TIFF bitonal compressé en PackBits, à la
+// résolution donnée ou, si dpi est nul, à celle de ses métadonnées (voir PackedPBM.ExportTIFF).
+func (pbm *PBM) ExportTIFF(w io.Writer, dpi float64) error {
+	if dpi <= 0 {
+		dpi = pbm.meta.DPI
+	}
+	return pbm.Pack().ExportTIFF(w, dpi)
+}
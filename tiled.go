@@ -0,0 +1,324 @@
+package netpbm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// TileManifest décrit une mosaïque écrite par SaveTiled : l'image complète est découpée en une
+// grille de tuiles, chacune enregistrée dans son propre fichier, pour les outils qui ne savent pas
+// ouvrir une seule image gigantesque. Le manifeste est enregistré en JSON à côté des tuiles.
+type TileManifest struct {
+	Format     string      `json:"format"` // Nombre magique des tuiles
+	Width      int         `json:"width"`
+	Height     int         `json:"height"`
+	MaxValue   int         `json:"maxValue,omitempty"`
+	TileWidth  int         `json:"tileWidth"`
+	TileHeight int         `json:"tileHeight"`
+	Tiles      []TileEntry `json:"tiles"`
+}
+
+// TileEntry est une tuile de la mosaïque : son fichier, relatif au répertoire du manifeste, et la
+// zone de l'image complète qu'elle couvre. Les tuiles de la dernière ligne et de la dernière
+// colonne peuvent être plus petites que les autres.
+type TileEntry struct {
+	File   string `json:"file"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Rect renvoie la zone de l'image complète couverte par la tuile.
+func (entry TileEntry) Rect() Rect {
+	return Rect{X: entry.X, Y: entry.Y, Width: entry.Width, Height: entry.Height}
+}
+
+// tileManifestSuffix termine le nom du manifeste d'une mosaïque.
+const tileManifestSuffix = ".tiles.json"
+
+// saveTiled découpe l'image en tuiles de tileWidth × tileHeight pixels, enregistrées sous les noms
+// prefix_rLLL_cCCC.ext (ligne et colonne de la tuile), puis écrit le manifeste prefix.tiles.json.
+func saveTiled(img Image, maxValue int, crop func(Rect) Image, prefix string, tileWidth, tileHeight int) error {
+	if tileWidth <= 0 || tileHeight <= 0 {
+		return fmt.Errorf("taille de tuile invalide: %dx%d", tileWidth, tileHeight)
+	}
+	width, height := img.Size()
+	format := originalFormat(img)
+	manifest := TileManifest{
+		Format:     format.MagicNumber(),
+		Width:      width,
+		Height:     height,
+		MaxValue:   maxValue,
+		TileWidth:  tileWidth,
+		TileHeight: tileHeight,
+	}
+
+	for row, y := 0, 0; y < height; row, y = row+1, y+tileHeight {
+		for column, x := 0, 0; x < width; column, x = column+1, x+tileWidth {
+			r := Rect{X: x, Y: y, Width: min(tileWidth, width-x), Height: min(tileHeight, height-y)}
+			filename := fmt.Sprintf("%s_r%03d_c%03d%s", prefix, row, column, format.Extension())
+			if err := SaveImage(filename, crop(r), EncodeOptions{Format: format}); err != nil {
+				return err
+			}
+			manifest.Tiles = append(manifest.Tiles, TileEntry{
+				File: filepath.Base(filename), X: r.X, Y: r.Y, Width: r.Width, Height: r.Height,
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(prefix+tileManifestSuffix, append(data, '\n'), 0644)
+}
+
+// SaveTiled enregistre l'image PPM en mosaïque de tuiles de tileWidth × tileHeight pixels,
+// nommées d'après prefix, accompagnée du manifeste prefix.tiles.json (voir TileManifest).
+func (ppm *PPM) SaveTiled(prefix string, tileWidth, tileHeight int) error {
+	return saveTiled(ppm, ppm.max, func(r Rect) Image { return ppm.crop(r) }, prefix, tileWidth, tileHeight)
+}
+
+// SaveTiled enregistre l'image PGM en mosaïque de tuiles, accompagnée de son manifeste.
+func (pgm *PGM) SaveTiled(prefix string, tileWidth, tileHeight int) error {
+	return saveTiled(pgm, pgm.max, func(r Rect) Image { return pgm.crop(r) }, prefix, tileWidth, tileHeight)
+}
+
+// SaveTiled enregistre l'image PBM en mosaïque de tuiles, accompagnée de son manifeste.
+func (pbm *PBM) SaveTiled(prefix string, tileWidth, tileHeight int) error {
+	return saveTiled(pbm, 0, func(r Rect) Image { return pbm.crop(r) }, prefix, tileWidth, tileHeight)
+}
+
+// TiledImage est une mosaïque ouverte par OpenTiled, vue comme une seule image virtuelle : les
+// tuiles ne sont lues qu'au moment où l'on accède à leurs pixels, et seules les plus récemment
+// utilisées restent en mémoire. Les statistiques et les découpes peuvent ainsi porter sur toute
+// la mosaïque sans la charger entièrement. Un TiledImage peut être partagé entre plusieurs goroutines.
+type TiledImage struct {
+	// CacheSize est le nombre maximal de tuiles gardées en mémoire (16 si nul).
+	CacheSize int
+
+	manifest TileManifest
+	dir      string
+	format   Format
+	grid     [][]int // Indice dans manifest.Tiles de la tuile de chaque ligne et colonne
+
+	mu    sync.Mutex
+	cache map[int]Image
+	order []int // Tuiles en cache, de la moins à la plus récemment utilisée
+}
+
+// OpenTiled ouvre la mosaïque décrite par le manifeste écrit par SaveTiled. Seul le manifeste est
+// lu ; il doit décrire une grille complète de tuiles.
+func OpenTiled(manifestFile string) (*TiledImage, error) {
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	var manifest TileManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("manifeste invalide: %v", err)
+	}
+	format, err := ParseFormat(manifest.Format)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Width <= 0 || manifest.Height <= 0 || manifest.TileWidth <= 0 || manifest.TileHeight <= 0 {
+		return nil, fmt.Errorf("dimensions invalides dans le manifeste %s", manifestFile)
+	}
+
+	rows := (manifest.Height + manifest.TileHeight - 1) / manifest.TileHeight
+	columns := (manifest.Width + manifest.TileWidth - 1) / manifest.TileWidth
+	grid := make([][]int, rows)
+	for row := range grid {
+		grid[row] = make([]int, columns)
+		for column := range grid[row] {
+			grid[row][column] = -1
+		}
+	}
+	for i, entry := range manifest.Tiles {
+		row, column := entry.Y/manifest.TileHeight, entry.X/manifest.TileWidth
+		want := Rect{X: column * manifest.TileWidth, Y: row * manifest.TileHeight, Width: manifest.TileWidth, Height: manifest.TileHeight}
+		want = want.Intersect(Rect{Width: manifest.Width, Height: manifest.Height})
+		if entry.Rect() != want || grid[row][column] >= 0 {
+			return nil, fmt.Errorf("tuile %s mal placée: %+v", entry.File, entry.Rect())
+		}
+		grid[row][column] = i
+	}
+	for row := range grid {
+		for column, i := range grid[row] {
+			if i < 0 {
+				return nil, fmt.Errorf("tuile manquante: ligne %d, colonne %d", row, column)
+			}
+		}
+	}
+
+	return &TiledImage{
+		manifest: manifest,
+		dir:      filepath.Dir(manifestFile),
+		format:   format,
+		grid:     grid,
+		cache:    make(map[int]Image),
+	}, nil
+}
+
+// Size renvoie la largeur et la hauteur de la mosaïque complète.
+func (tiled *TiledImage) Size() (int, int) {
+	return tiled.manifest.Width, tiled.manifest.Height
+}
+
+// Manifest renvoie le manifeste de la mosaïque.
+func (tiled *TiledImage) Manifest() TileManifest {
+	return tiled.manifest
+}
+
+// tile renvoie la tuile d'indice i, lue si elle n'est pas en cache.
+func (tiled *TiledImage) tile(i int) (Image, error) {
+	tiled.mu.Lock()
+	defer tiled.mu.Unlock()
+
+	if img, ok := tiled.cache[i]; ok {
+		for k, cached := range tiled.order {
+			if cached == i {
+				tiled.order = append(tiled.order[:k], tiled.order[k+1:]...)
+				break
+			}
+		}
+		tiled.order = append(tiled.order, i)
+		return img, nil
+	}
+
+	entry := tiled.manifest.Tiles[i]
+	img, err := ReadImage(filepath.Join(tiled.dir, entry.File))
+	if err != nil {
+		return nil, fmt.Errorf("tuile %s: %v", entry.File, err)
+	}
+	if width, height := img.Size(); width != entry.Width || height != entry.Height {
+		return nil, fmt.Errorf("tuile %s: taille %dx%d, %dx%d attendue", entry.File, width, height, entry.Width, entry.Height)
+	}
+
+	limit := tiled.CacheSize
+	if limit <= 0 {
+		limit = 16
+	}
+	for len(tiled.order) >= limit {
+		delete(tiled.cache, tiled.order[0])
+		tiled.order = tiled.order[1:]
+	}
+	tiled.cache[i] = img
+	tiled.order = append(tiled.order, i)
+	return img, nil
+}
+
+// At renvoie les composantes du pixel (x, y) de la mosaïque : trois pour une mosaïque PPM, une pour
+// une mosaïque PGM ou PBM (1 pour noir).
+func (tiled *TiledImage) At(x, y int) ([]uint8, error) {
+	if x < 0 || y < 0 || x >= tiled.manifest.Width || y >= tiled.manifest.Height {
+		return nil, fmt.Errorf("pixel (%d, %d) hors de l'image", x, y)
+	}
+	i := tiled.grid[y/tiled.manifest.TileHeight][x/tiled.manifest.TileWidth]
+	img, err := tiled.tile(i)
+	if err != nil {
+		return nil, err
+	}
+	entry := tiled.manifest.Tiles[i]
+	x, y = x-entry.X, y-entry.Y
+	switch img := img.(type) {
+	case *PPM:
+		return append([]uint8(nil), img.data[y][x][:3]...), nil
+	case *PGM:
+		return []uint8{img.data[y][x]}, nil
+	case *PBM:
+		return []uint8{uint8(boolToInt(img.data[y][x]))}, nil
+	}
+	return nil, fmt.Errorf("type d'image non pris en charge: %T", img)
+}
+
+// ForEachTile appelle fn pour chaque tuile, ligne par ligne, en ne gardant que les tuiles du cache
+// en mémoire : de quoi calculer des statistiques sur toute la mosaïque. fn ne doit pas modifier la
+// tuile. Le parcours s'arrête à la première erreur.
+func (tiled *TiledImage) ForEachTile(fn func(entry TileEntry, tile Image) error) error {
+	for _, row := range tiled.grid {
+		for _, i := range row {
+			img, err := tiled.tile(i)
+			if err != nil {
+				return err
+			}
+			if err := fn(tiled.manifest.Tiles[i], img); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Crop renvoie la zone r de la mosaïque, bornée à l'image, dans une image du type des tuiles. Seules
+// les tuiles qui recouvrent la zone sont lues.
+func (tiled *TiledImage) Crop(r Rect) (Image, error) {
+	r = r.Intersect(Rect{Width: tiled.manifest.Width, Height: tiled.manifest.Height})
+	if r.Empty() {
+		return nil, fmt.Errorf("zone hors de l'image")
+	}
+
+	var result Image
+	switch tiled.format {
+	case FormatP1, FormatP4:
+		result = NewPBM(r.Width, r.Height)
+	case FormatP2, FormatP5:
+		result = NewPGM(r.Width, r.Height, tiled.manifest.MaxValue)
+	default:
+		result = NewPPM(r.Width, r.Height, tiled.manifest.MaxValue)
+	}
+
+	tw, th := tiled.manifest.TileWidth, tiled.manifest.TileHeight
+	for row := r.Y / th; row <= (r.Y+r.Height-1)/th; row++ {
+		for column := r.X / tw; column <= (r.X+r.Width-1)/tw; column++ {
+			i := tiled.grid[row][column]
+			img, err := tiled.tile(i)
+			if err != nil {
+				return nil, err
+			}
+			entry := tiled.manifest.Tiles[i]
+			part := entry.Rect().Intersect(r)
+			if err := copyTilePart(result, img, part, entry, r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// copyTilePart recopie la zone part (en coordonnées de la mosaïque) de la tuile entry dans dst, qui
+// couvre la zone target de la mosaïque.
+func copyTilePart(dst, tile Image, part Rect, entry TileEntry, target Rect) error {
+	for y := part.Y; y < part.Y+part.Height; y++ {
+		sx, sy := part.X-entry.X, y-entry.Y
+		dx, dy := part.X-target.X, y-target.Y
+		switch dst := dst.(type) {
+		case *PPM:
+			src, ok := tile.(*PPM)
+			if !ok {
+				return fmt.Errorf("tuile %s: type %T inattendu", entry.File, tile)
+			}
+			for x := 0; x < part.Width; x++ {
+				copy(dst.data[dy][dx+x], src.data[sy][sx+x])
+			}
+		case *PGM:
+			src, ok := tile.(*PGM)
+			if !ok {
+				return fmt.Errorf("tuile %s: type %T inattendu", entry.File, tile)
+			}
+			copy(dst.data[dy][dx:dx+part.Width], src.data[sy][sx:sx+part.Width])
+		case *PBM:
+			src, ok := tile.(*PBM)
+			if !ok {
+				return fmt.Errorf("tuile %s: type %T inattendu", entry.File, tile)
+			}
+			copy(dst.data[dy][dx:dx+part.Width], src.data[sy][sx:sx+part.Width])
+		}
+	}
+	return nil
+}
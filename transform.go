@@ -0,0 +1,148 @@
+package netpbm
+
+import "math"
+
+// Transformations du dessin : Translate, RotateTransform et Scale modifient le repère dans lequel sont
+// exprimées les coordonnées des méthodes de dessin géométriques de l'image PPM (DrawLine,
+// DrawPolygon, DrawFilledRectangle, DrawCircle, DrawMarker, FillPath, PushClipPath…), comme le
+// canevas HTML ou cairo : une forme peut être décrite dans son propre repère puis placée, tournée
+// ou agrandie. SaveState et RestoreState mémorisent et rétablissent la transformation et les zones
+// de découpe. Le texte, la grille, les règles et les annotations restent dans le repère de l'image.
+//
+// Une transformation composée est une Homography (affine : dernière ligne 0 0 1). Les points
+// entiers désignent des pixels : le centre du pixel (x, y) est transformé, puis arrondi au pixel
+// qui le contient.
+
+// identity est la transformation identité.
+var identity = Homography{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+// multiply renvoie la transformation h∘other : other est appliquée en premier.
+func (h Homography) multiply(other Homography) Homography {
+	var result Homography
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				result[i][j] += h[i][k] * other[k][j]
+			}
+		}
+	}
+	return result
+}
+
+// drawState est l'état du dessin mémorisé par SaveState.
+type drawState struct {
+	transform *Homography
+	clips     int
+}
+
+// SaveState mémorise la transformation et les zones de découpe en cours, pour que RestoreState
+// les rétablisse. Les appels s'imbriquent.
+func (ppm *PPM) SaveState() {
+	ppm.states = append(ppm.states, drawState{transform: ppm.transform, clips: len(ppm.clips)})
+}
+
+// RestoreState rétablit l'état mémorisé par le dernier SaveState, en retirant les zones de découpe
+// empilées depuis ; il est sans effet si aucun état n'est mémorisé.
+func (ppm *PPM) RestoreState() {
+	if len(ppm.states) == 0 {
+		return
+	}
+	state := ppm.states[len(ppm.states)-1]
+	ppm.states = ppm.states[:len(ppm.states)-1]
+	ppm.transform = state.transform
+	if state.clips < len(ppm.clips) {
+		ppm.clips = ppm.clips[:state.clips]
+	}
+}
+
+// Transform renvoie la transformation en cours.
+func (ppm *PPM) Transform() Homography {
+	if ppm.transform == nil {
+		return identity
+	}
+	return *ppm.transform
+}
+
+// compose ajoute h à la transformation en cours : h s'applique aux coordonnées avant elle.
+func (ppm *PPM) compose(h Homography) {
+	composed := ppm.Transform().multiply(h)
+	ppm.transform = &composed
+}
+
+// ResetTransform revient au repère de l'image.
+func (ppm *PPM) ResetTransform() {
+	ppm.transform = nil
+}
+
+// Translate déplace l'origine du repère de (dx, dy).
+func (ppm *PPM) Translate(dx, dy float64) {
+	ppm.compose(Homography{{1, 0, dx}, {0, 1, dy}, {0, 0, 1}})
+}
+
+// RotateTransform tourne le repère de angle radians autour de son origine, dans le sens des
+// aiguilles d'une montre à l'écran (axe Y vers le bas). Pour tourner l'image elle-même, voir Rotate.
+func (ppm *PPM) RotateTransform(angle float64) {
+	sin, cos := math.Sincos(angle)
+	ppm.compose(Homography{{cos, -sin, 0}, {sin, cos, 0}, {0, 0, 1}})
+}
+
+// Scale agrandit le repère de sx horizontalement et de sy verticalement, à partir de son origine.
+func (ppm *PPM) Scale(sx, sy float64) {
+	ppm.compose(Homography{{sx, 0, 0}, {0, sy, 0}, {0, 0, 1}})
+}
+
+// toDevice renvoie le pixel de l'image où tombe le pixel p du repère en cours.
+func (ppm *PPM) toDevice(p Point) Point {
+	if ppm.transform == nil {
+		return p
+	}
+	x, y := ppm.transform.Apply(float64(p.X)+0.5, float64(p.Y)+0.5)
+	return Point{X: int(math.Floor(x)), Y: int(math.Floor(y))}
+}
+
+// toDevicePoints applique toDevice à chaque point, sans modifier points.
+func (ppm *PPM) toDevicePoints(points []Point) []Point {
+	if ppm.transform == nil {
+		return points
+	}
+	result := make([]Point, len(points))
+	for i, p := range points {
+		result[i] = ppm.toDevice(p)
+	}
+	return result
+}
+
+// toDevicePath renvoie le tracé exprimé dans le repère de l'image.
+func (ppm *PPM) toDevicePath(path *Path) *Path {
+	if ppm.transform == nil {
+		return path
+	}
+	result := &Path{subpaths: make([]subpath, len(path.subpaths))}
+	for i, sub := range path.subpaths {
+		points := make([]Vec2, len(sub.points))
+		for j, p := range sub.points {
+			points[j].X, points[j].Y = ppm.transform.Apply(p.X, p.Y)
+		}
+		result.subpaths[i] = subpath{points: points, closed: sub.closed}
+	}
+	return result
+}
+
+// rectPath renvoie le tracé qui couvre exactement les pixels de r.
+func rectPath(r Rect) *Path {
+	path := NewPath()
+	x0, y0 := float64(r.X), float64(r.Y)
+	x1, y1 := float64(r.X+r.Width), float64(r.Y+r.Height)
+	path.MoveTo(x0, y0)
+	path.LineTo(x1, y0)
+	path.LineTo(x1, y1)
+	path.LineTo(x0, y1)
+	path.Close()
+	return path
+}
+
+// circlePath renvoie le cercle de rayon radius centré sur le pixel center.
+func circlePath(center Point, radius float64) *Path {
+	points := disk(Vec2{X: float64(center.X) + 0.5, Y: float64(center.Y) + 0.5}, radius)
+	return &Path{subpaths: []subpath{{points: points, closed: true}}}
+}
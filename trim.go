@@ -0,0 +1,102 @@
+package netpbm
+
+// trimBounds renvoie le plus petit rectangle contenant tous les pixels qui ne sont pas du fond.
+// Si l'image n'est faite que de fond, le rectangle renvoyé est vide.
+func trimBounds(width, height int, background func(x, y int) bool) Rect {
+	minX, minY, maxX, maxY := width, height, -1, -1
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if background(x, y) {
+				continue
+			}
+			minX, maxX = min(minX, x), max(maxX, x)
+			minY, maxY = min(minY, y), max(maxY, y)
+		}
+	}
+	if maxX < 0 {
+		return Rect{}
+	}
+	return Rect{X: minX, Y: minY, Width: maxX - minX + 1, Height: maxY - minY + 1}
+}
+
+// crop renvoie une copie de la zone r de l'image.
+func (ppm *PPM) crop(r Rect) *PPM {
+	result := NewPPM(r.Width, r.Height, ppm.max)
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			copy(result.data[y][x], ppm.data[r.Y+y][r.X+x])
+		}
+	}
+	return result
+}
+
+// crop renvoie une copie de la zone r de l'image.
+func (pgm *PGM) crop(r Rect) *PGM {
+	result := NewPGM(r.Width, r.Height, pgm.max)
+	for y := 0; y < r.Height; y++ {
+		copy(result.data[y], pgm.data[r.Y+y][r.X:r.X+r.Width])
+	}
+	return result
+}
+
+// crop renvoie une copie de la zone r de l'image.
+func (pbm *PBM) crop(r Rect) *PBM {
+	result := NewPBM(r.Width, r.Height)
+	result.polarity = pbm.polarity
+	for y := 0; y < r.Height; y++ {
+		copy(result.data[y], pbm.data[r.Y+y][r.X:r.X+r.Width])
+	}
+	return result
+}
+
+// Trim renvoie l'image débarrassée de ses bords uniformes. La couleur du fond est celle du pixel
+// en haut à gauche ; un pixel est considéré comme du fond si aucune de ses composantes ne s'en
+// écarte de plus de tolerance. Une image entièrement unie est renvoyée inchangée (copiée).
+func (ppm *PPM) Trim(tolerance int) *PPM {
+	if ppm.width == 0 || ppm.height == 0 {
+		return ppm.Copy()
+	}
+	background := ppm.pixel(0, 0)
+	r := trimBounds(ppm.width, ppm.height, func(x, y int) bool {
+		value := ppm.data[y][x]
+		return abs(int(value[0])-int(background.Red)) <= tolerance &&
+			abs(int(value[1])-int(background.Green)) <= tolerance &&
+			abs(int(value[2])-int(background.Blue)) <= tolerance
+	})
+	if r.Width == 0 {
+		return ppm.Copy()
+	}
+	result := ppm.crop(r)
+	result.meta = ppm.meta.derive("trim %dx%d+%d+%d", r.Width, r.Height, r.X, r.Y)
+	return result
+}
+
+// Trim renvoie l'image débarrassée de ses bords uniformes, d'après le niveau du pixel en haut à gauche.
+func (pgm *PGM) Trim(tolerance int) *PGM {
+	if pgm.width == 0 || pgm.height == 0 {
+		return pgm.Copy()
+	}
+	background := int(pgm.data[0][0])
+	r := trimBounds(pgm.width, pgm.height, func(x, y int) bool {
+		return abs(int(pgm.data[y][x])-background) <= tolerance
+	})
+	if r.Width == 0 {
+		return pgm.Copy()
+	}
+	result := pgm.crop(r)
+	result.meta = pgm.meta.derive("trim %dx%d+%d+%d", r.Width, r.Height, r.X, r.Y)
+	return result
+}
+
+// Trim renvoie l'image débarrassée de ses bords blancs.
+func (pbm *PBM) Trim() *PBM {
+	r := trimBounds(pbm.width, pbm.height, func(x, y int) bool {
+		return !pbm.data[y][x]
+	})
+	if r.Width == 0 {
+		r = Rect{Width: pbm.width, Height: pbm.height}
+	}
+	result := pbm.crop(r)
+	result.meta = pbm.meta.derive("trim %dx%d+%d+%d", r.Width, r.Height, r.X, r.Y)
+	return result
+}
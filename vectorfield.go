@@ -0,0 +1,126 @@
+package netpbm
+
+import "math"
+
+// VectorField associe un vecteur (dx, dy) à chaque point (x, y) de l'image, en coordonnées de
+// pixels (le centre du pixel (0, 0) est en (0, 0)) : vitesse d'un écoulement, gradient d'un champ
+// scalaire…
+type VectorField func(x, y float64) (dx, dy float64)
+
+// GradientField renvoie le gradient de l'image PGM vue comme un champ scalaire (niveaux ramenés
+// entre 0 et 1), calculé par différences centrées sur l'image interpolée. Il pointe vers les
+// niveaux croissants.
+func (pgm *PGM) GradientField() VectorField {
+	img := pgm.toFloat(false)
+	return func(x, y float64) (float64, float64) {
+		dx := (img.bilinear(x+1, y, 0) - img.bilinear(x-1, y, 0)) / 2
+		dy := (img.bilinear(x, y+1, 0) - img.bilinear(x, y-1, 0)) / 2
+		return dx, dy
+	}
+}
+
+// DrawVectorField dessine le champ par des flèches placées au centre de cases de spacing pixels
+// de côté. Les longueurs sont proportionnelles à celles des vecteurs, la plus longue flèche
+// occupant presque toute sa case ; les vecteurs nuls ne sont pas dessinés.
+func (ppm *PPM) DrawVectorField(field VectorField, spacing int, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	spacing = max(spacing, 4)
+
+	type sample struct{ x, y, dx, dy float64 }
+	var samples []sample
+	longest := 0.0
+	for y := spacing / 2; y < ppm.height; y += spacing {
+		for x := spacing / 2; x < ppm.width; x += spacing {
+			dx, dy := field(float64(x), float64(y))
+			samples = append(samples, sample{float64(x), float64(y), dx, dy})
+			longest = math.Max(longest, math.Hypot(dx, dy))
+		}
+	}
+	if longest == 0 || math.IsInf(longest, 0) || math.IsNaN(longest) {
+		return
+	}
+
+	scale := 0.9 * float64(spacing) / longest
+	for _, s := range samples {
+		length := math.Hypot(s.dx, s.dy) * scale
+		if length < 1 {
+			continue
+		}
+		// La flèche est centrée sur le point d'échantillonnage.
+		halfX, halfY := s.dx*scale/2, s.dy*scale/2
+		from := Point{X: int(math.Round(s.x - halfX)), Y: int(math.Round(s.y - halfY))}
+		to := Point{X: int(math.Round(s.x + halfX)), Y: int(math.Round(s.y + halfY))}
+		ppm.DrawArrow(from, to, max(int(length/3), 2), color)
+	}
+}
+
+// DrawStreamlines dessine les lignes de courant du champ : des courbes partout tangentes aux
+// vecteurs, partant de points espacés de spacing pixels et suivies dans les deux sens. Une ligne
+// s'arrête au bord de l'image, là où le champ s'annule ou quand elle s'approche à moins de
+// spacing/2 d'une ligne déjà tracée, ce qui répartit les lignes régulièrement.
+func (ppm *PPM) DrawStreamlines(field VectorField, spacing int, color Pixel) {
+	defer ppm.changes.batch(ppm.Size)()
+	spacing = max(spacing, 2)
+
+	// Grille d'occupation : une case de spacing/2 pixels ne porte qu'une ligne.
+	cell := max(spacing/2, 1)
+	columns, rows := (ppm.width+cell-1)/cell, (ppm.height+cell-1)/cell
+	occupied := make([]int, columns*rows) // Numéro de la ligne qui occupe la case (0 si libre)
+	cellOf := func(x, y float64) int {
+		return int(y+0.5)/cell*columns + int(x+0.5)/cell
+	}
+	inside := func(x, y float64) bool {
+		return x >= -0.5 && x < float64(ppm.width)-0.5 && y >= -0.5 && y < float64(ppm.height)-0.5
+	}
+	// direction renvoie le vecteur unitaire du champ en (x, y), multiplié par sign.
+	direction := func(x, y, sign float64) (float64, float64, bool) {
+		dx, dy := field(x, y)
+		length := math.Hypot(dx, dy)
+		if length < 1e-12 || math.IsNaN(length) || math.IsInf(length, 0) {
+			return 0, 0, false
+		}
+		return sign * dx / length, sign * dy / length, true
+	}
+
+	const step = 0.5                         // Pas d'intégration, en pixels
+	maxSteps := 8 * (ppm.width + ppm.height) // Deux fois le tour de l'image, au pas de step
+	line := 0
+	for seedY := spacing / 2; seedY < ppm.height; seedY += spacing {
+		for seedX := spacing / 2; seedX < ppm.width; seedX += spacing {
+			if occupied[cellOf(float64(seedX), float64(seedY))] != 0 {
+				continue
+			}
+			line++
+			for _, sign := range []float64{1, -1} {
+				x, y := float64(seedX), float64(seedY)
+				occupied[cellOf(x, y)] = line
+				previous := Point{X: seedX, Y: seedY}
+				for i := 0; i < maxSteps; i++ {
+					// Méthode du point milieu (Runge-Kutta d'ordre 2).
+					dx, dy, ok := direction(x, y, sign)
+					if !ok {
+						break
+					}
+					mx, my, ok := direction(x+dx*step/2, y+dy*step/2, sign)
+					if !ok {
+						break
+					}
+					x, y = x+mx*step, y+my*step
+					if !inside(x, y) {
+						break
+					}
+					if owner := occupied[cellOf(x, y)]; owner != 0 && owner != line {
+						break
+					}
+					occupied[cellOf(x, y)] = line
+
+					current := Point{X: int(math.Round(x)), Y: int(math.Round(y))}
+					if current != previous {
+						ppm.DrawLine(previous, current, color)
+						previous = current
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package netpbm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ConvertPreset décrit la conversion appliquée aux fichiers entrants.
+type ConvertPreset struct {
+	Format    Format // Format de sortie
+	MaxWidth  int    // Largeur maximale (0 = inchangée) ; les proportions sont conservées
+	MaxHeight int    // Hauteur maximale (0 = inchangée)
+	// Eval est un programme appliqué à l'image convertie (voir Program) : il porte sur r, g et b
+	// pour un format PPM, sur v pour un format PGM. Il est ignoré s'il est vide.
+	Eval string
+}
+
+// Extension renvoie l'extension de fichier correspondant au format du préréglage.
+func (preset ConvertPreset) Extension() string {
+	return preset.Format.Extension()
+}
+
+// Apply convertit l'image selon le préréglage.
+func (preset ConvertPreset) Apply(img Image) (Image, error) {
+	width, height := img.Size()
+	fitWidth, fitHeight := fitInside(width, height, preset.MaxWidth, preset.MaxHeight)
+	if fitWidth != width || fitHeight != height {
+		switch typed := img.(type) {
+		case *PPM:
+			img = typed.Resize(fitWidth, fitHeight, true)
+		case *PGM:
+			img = typed.Resize(fitWidth, fitHeight, true)
+		case *PBM:
+			gray, err := convertToPGM(typed, ConvertOptions{})
+			if err != nil {
+				return nil, err
+			}
+			img = gray.Resize(fitWidth, fitHeight, false)
+		}
+	}
+	result, err := Convert(img, preset.Format, ConvertOptions{})
+	if err != nil || preset.Eval == "" {
+		return result, err
+	}
+	switch typed := result.(type) {
+	case *PPM:
+		err = typed.Eval(preset.Eval)
+	case *PGM:
+		err = typed.Eval(preset.Eval)
+	default:
+		err = fmt.Errorf("programme non applicable au format %s", preset.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fitInside renvoie la plus grande taille de mêmes proportions que width×height tenant dans
+// maxWidth×maxHeight (une limite nulle est ignorée). L'image n'est jamais agrandie.
+func fitInside(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		scale = min64(scale, float64(maxHeight)/float64(height))
+	}
+	if scale >= 1 {
+		return width, height
+	}
+	return max(int(float64(width)*scale+0.5), 1), max(int(float64(height)*scale+0.5), 1)
+}
+
+// min64 renvoie le minimum de deux réels.
+func min64(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// FolderWatcher surveille un répertoire par scrutation et convertit automatiquement les fichiers
+// Netpbm (P1 à P6) qui y arrivent vers le répertoire de sortie, selon un préréglage. Un fichier
+// n'est traité que lorsque sa taille et sa date n'ont pas changé entre deux passages, pour ne pas
+// lire un fichier encore en cours d'écriture par un scanner ou une ferme de rendu.
+type FolderWatcher struct {
+	Input    string                           // Répertoire surveillé
+	Output   string                           // Répertoire de sortie
+	Preset   ConvertPreset                    // Conversion appliquée
+	Interval time.Duration                    // Intervalle de scrutation (2 s si nul)
+	Logf     func(format string, args ...any) // Journal (aucun si nil)
+
+	pending map[string]fileState // Fichiers vus mais pas encore stables
+	done    map[string]fileState // Fichiers déjà convertis
+}
+
+// fileState identifie une version d'un fichier.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// logf écrit dans le journal s'il est défini.
+func (watcher *FolderWatcher) logf(format string, args ...any) {
+	if watcher.Logf != nil {
+		watcher.Logf(format, args...)
+	}
+}
+
+// Scan effectue un passage sur le répertoire et convertit les fichiers devenus stables.
+// Il renvoie le nombre de fichiers convertis ; les échecs de conversion sont journalisés sans interrompre le passage.
+func (watcher *FolderWatcher) Scan() (int, error) {
+	if watcher.pending == nil {
+		watcher.pending = make(map[string]fileState)
+		watcher.done = make(map[string]fileState)
+	}
+
+	entries, err := os.ReadDir(watcher.Input)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(watcher.Output, 0o755); err != nil {
+		return 0, err
+	}
+
+	converted := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !isNetpbmName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		name := entry.Name()
+
+		if done, ok := watcher.done[name]; ok && done == state {
+			continue
+		}
+		if previous, ok := watcher.pending[name]; !ok || previous != state {
+			watcher.pending[name] = state
+			continue
+		}
+
+		delete(watcher.pending, name)
+		watcher.done[name] = state
+		if err := watcher.convert(name); err != nil {
+			watcher.logf("échec de la conversion de %s: %v", name, err)
+			continue
+		}
+		converted++
+	}
+	return converted, nil
+}
+
+// convert convertit un fichier du répertoire surveillé.
+func (watcher *FolderWatcher) convert(name string) error {
+	img, err := ReadImage(filepath.Join(watcher.Input, name))
+	if err != nil {
+		return err
+	}
+	result, err := watcher.Preset.Apply(img)
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	target := filepath.Join(watcher.Output, base+watcher.Preset.Extension())
+	if err := SaveImage(target, result, EncodeOptions{Format: watcher.Preset.Format}); err != nil {
+		return err
+	}
+	watcher.logf("%s → %s", name, target)
+	return nil
+}
+
+// Run scrute le répertoire jusqu'à la fermeture du canal stop.
+func (watcher *FolderWatcher) Run(stop <-chan struct{}) error {
+	interval := watcher.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := watcher.Scan(); err != nil {
+			return fmt.Errorf("scrutation de %s: %v", watcher.Input, err)
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// isNetpbmName indique si le nom de fichier porte une extension Netpbm.
+func isNetpbmName(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".pbm", ".pgm", ".ppm", ".pnm":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package netpbm
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFolderWatcherConvertsP5(t *testing.T) {
+	input, output := t.TempDir(), t.TempDir()
+	pgm := testPGM()
+	pgm.SetMagicNumber("P5")
+	if err := pgm.Save(filepath.Join(input, "scan.pgm")); err != nil {
+		t.Fatal(err)
+	}
+
+	var failures []string
+	watcher := &FolderWatcher{
+		Input:  input,
+		Output: output,
+		Preset: ConvertPreset{Format: FormatP2},
+		Logf: func(format string, args ...any) {
+			if format != "%s → %s" {
+				failures = append(failures, format)
+			}
+		},
+	}
+	// Le premier passage ne fait que noter le fichier, le second le convertit une fois stable.
+	for pass, want := range []int{0, 1} {
+		converted, err := watcher.Scan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if converted != want {
+			t.Fatalf("passage %d: %d fichiers convertis au lieu de %d (%v)", pass+1, converted, want, failures)
+		}
+	}
+
+	converted, err := ReadPGM(filepath.Join(output, "scan"+watcher.Preset.Extension()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.MagicNumber() != "P2" {
+		t.Errorf("nombre magique %s au lieu de P2", converted.MagicNumber())
+	}
+	assertSamePGM(t, converted, testPGM())
+}
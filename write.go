@@ -0,0 +1,211 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Format représente l'un des six formats Netpbm, identifié par son nombre magique.
+type Format int
+
+const (
+	FormatP1 Format = iota + 1 // PBM ASCII
+	FormatP2                   // PGM ASCII
+	FormatP3                   // PPM ASCII
+	FormatP4                   // PBM binaire
+	FormatP5                   // PGM binaire
+	FormatP6                   // PPM binaire
+)
+
+// MagicNumber renvoie le nombre magique du format ("P1" à "P6").
+func (format Format) MagicNumber() string {
+	return fmt.Sprintf("P%d", int(format))
+}
+
+// String renvoie le nombre magique du format.
+func (format Format) String() string {
+	return format.MagicNumber()
+}
+
+// Extension renvoie l'extension de fichier usuelle du format (".pbm", ".pgm" ou ".ppm").
+func (format Format) Extension() string {
+	switch format {
+	case FormatP1, FormatP4:
+		return ".pbm"
+	case FormatP2, FormatP5:
+		return ".pgm"
+	}
+	return ".ppm"
+}
+
+// Raw indique si le format est binaire (P4, P5, P6).
+func (format Format) Raw() bool {
+	return format >= FormatP4
+}
+
+// ParseFormat renvoie le format correspondant à un nombre magique.
+func ParseFormat(magicNumber string) (Format, error) {
+	if len(magicNumber) == 2 && magicNumber[0] == 'P' && magicNumber[1] >= '1' && magicNumber[1] <= '6' {
+		return Format(magicNumber[1] - '0'), nil
+	}
+	return 0, fmt.Errorf("nombre magique inconnu: %s", magicNumber)
+}
+
+// originalFormat renvoie le format d'une image d'après son nombre magique, ou le format ASCII de son type.
+func originalFormat(img Image) Format {
+	var magicNumber string
+	var fallback Format
+	switch img := img.(type) {
+	case *PBM:
+		magicNumber, fallback = img.magicNumber, FormatP1
+	case *PackedPBM:
+		fallback = FormatP4
+	case *PGM:
+		magicNumber, fallback = img.magicNumber, FormatP2
+	case *PPM:
+		magicNumber, fallback = img.magicNumber, FormatP3
+	}
+	if format, err := ParseFormat(magicNumber); err == nil {
+		return format
+	}
+	return fallback
+}
+
+// Image est implémentée par les trois types d'image du paquet : *PBM, *PGM et *PPM.
+type Image interface {
+	Size() (int, int)
+}
+
+// Write écrit l'image dans le format demandé, en la convertissant si nécessaire avec les réglages
+// par défaut de Convert : une image PPM écrite en P2/P5 est convertie en niveaux de gris par
+// luminance, une image PGM écrite en P1/P4 est seuillée à mi-hauteur, et les images bitonales ou
+// grises sont étendues vers les formats plus riches.
+func Write(w io.Writer, img Image, format Format) error {
+	return Encode(w, img, EncodeOptions{Format: format})
+}
+
+// writeHeader écrit l'en-tête : nombre magique, commentaires, taille et, hors PBM, valeur maximale.
+func writeHeader(w *bufio.Writer, format Format, width, height, maxValue int, comments []string) {
+	w.WriteString(format.MagicNumber())
+	w.WriteByte('\n')
+	for _, comment := range comments {
+		// Un commentaire sur plusieurs lignes donne plusieurs lignes de commentaire.
+		for _, line := range strings.Split(comment, "\n") {
+			fmt.Fprintf(w, "# %s\n", line)
+		}
+	}
+	fmt.Fprintf(w, "%d %d\n", width, height)
+	if maxValue > 0 {
+		fmt.Fprintf(w, "%d\n", maxValue)
+	}
+}
+
+// asciiWriter écrit les échantillons d'un format ASCII séparés par des espaces, en commençant une
+// nouvelle ligne à chaque ligne de l'image et, si lineWidth est positif, avant de dépasser lineWidth
+// caractères. Les nombres sont formatés dans un tampon réutilisé, sans allocation par échantillon.
+type asciiWriter struct {
+	w         *bufio.Writer
+	lineWidth int
+	column    int
+	digits    []byte
+}
+
+// sample écrit un échantillon.
+func (writer *asciiWriter) sample(value int) {
+	writer.digits = strconv.AppendInt(writer.digits[:0], int64(value), 10)
+	if writer.column > 0 {
+		if writer.lineWidth > 0 && writer.column+1+len(writer.digits) > writer.lineWidth {
+			writer.w.WriteByte('\n')
+			writer.column = 0
+		} else {
+			writer.w.WriteByte(' ')
+			writer.column++
+		}
+	}
+	writer.w.Write(writer.digits)
+	writer.column += len(writer.digits)
+}
+
+// endRow termine une ligne de l'image.
+func (writer *asciiWriter) endRow() {
+	writer.w.WriteByte('\n')
+	writer.column = 0
+}
+
+// writePBM écrit l'image PBM en P1 ou, si raw est vrai, en P4 (8 pixels par octet, lignes complétées à l'octet).
+func writePBM(w *bufio.Writer, pbm *PBM, raw bool, options EncodeOptions) error {
+	if !raw {
+		writeHeader(w, FormatP1, pbm.width, pbm.height, 0, options.Comments)
+		ascii := &asciiWriter{w: w, lineWidth: options.LineWidth}
+		for _, row := range pbm.data {
+			for _, value := range row {
+				ascii.sample(boolToInt(value))
+			}
+			ascii.endRow()
+		}
+		return nil
+	}
+
+	writeHeader(w, FormatP4, pbm.width, pbm.height, 0, options.Comments)
+	packed := make([]byte, (pbm.width+7)/8)
+	for _, row := range pbm.data {
+		packRow(packed, row)
+		if _, err := w.Write(packed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePGM écrit l'image PGM en P2 ou, si raw est vrai, en P5 (un octet par pixel).
+func writePGM(w *bufio.Writer, pgm *PGM, raw bool, options EncodeOptions) error {
+	if !raw {
+		writeHeader(w, FormatP2, pgm.width, pgm.height, pgm.max, options.Comments)
+		ascii := &asciiWriter{w: w, lineWidth: options.LineWidth}
+		for _, row := range pgm.data {
+			for _, value := range row {
+				ascii.sample(int(value))
+			}
+			ascii.endRow()
+		}
+		return nil
+	}
+
+	writeHeader(w, FormatP5, pgm.width, pgm.height, pgm.max, options.Comments)
+	for _, row := range pgm.data {
+		if _, err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePPM écrit l'image PPM en P3 ou, si raw est vrai, en P6 (trois octets par pixel).
+func writePPM(w *bufio.Writer, ppm *PPM, raw bool, options EncodeOptions) error {
+	if !raw {
+		writeHeader(w, FormatP3, ppm.width, ppm.height, ppm.max, options.Comments)
+		ascii := &asciiWriter{w: w, lineWidth: options.LineWidth}
+		for _, row := range ppm.data {
+			for _, pixel := range row {
+				for c := 0; c < 3; c++ {
+					ascii.sample(int(pixel[c]))
+				}
+			}
+			ascii.endRow()
+		}
+		return nil
+	}
+
+	writeHeader(w, FormatP6, ppm.width, ppm.height, ppm.max, options.Comments)
+	for _, row := range ppm.data {
+		for _, pixel := range row {
+			if _, err := w.Write(pixel[:3]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package netpbm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ChromaFormat indique le sous-échantillonnage des plans de chrominance d'une image YUV.
+type ChromaFormat int
+
+const (
+	Chroma420 ChromaFormat = iota // Chrominance divisée par deux dans les deux sens (yuv420p)
+	Chroma444                     // Chrominance pleine résolution (yuv444p)
+)
+
+// YUVFrame est une image en plans Y, U (Cb) et V (Cr) sur 8 bits, dans la plage limitée BT.601
+// (Y de 16 à 235) utilisée par défaut par ffmpeg pour rawvideo.
+type YUVFrame struct {
+	Width, Height int
+	Chroma        ChromaFormat
+	Y, U, V       []uint8
+}
+
+// chromaSize renvoie la taille des plans de chrominance.
+func (chroma ChromaFormat) chromaSize(width, height int) (int, int) {
+	if chroma == Chroma420 {
+		return (width + 1) / 2, (height + 1) / 2
+	}
+	return width, height
+}
+
+// NewYUVFrame crée une image YUV noire.
+func NewYUVFrame(width, height int, chroma ChromaFormat) *YUVFrame {
+	chromaWidth, chromaHeight := chroma.chromaSize(width, height)
+	frame := &YUVFrame{
+		Width: width, Height: height, Chroma: chroma,
+		Y: make([]uint8, width*height),
+		U: make([]uint8, chromaWidth*chromaHeight),
+		V: make([]uint8, chromaWidth*chromaHeight),
+	}
+	for i := range frame.Y {
+		frame.Y[i] = 16
+	}
+	for i := range frame.U {
+		frame.U[i], frame.V[i] = 128, 128
+	}
+	return frame
+}
+
+// toByte arrondit et borne une valeur sur 8 bits.
+func toByte(value float64) uint8 {
+	return uint8(clampFloat(math.Round(value), 0, 255))
+}
+
+// ToYUV convertit l'image PPM en plans YUV. En 4:2:0, chaque échantillon de chrominance est la
+// moyenne d'un bloc de 2×2 pixels.
+func (ppm *PPM) ToYUV(chroma ChromaFormat) *YUVFrame {
+	frame := NewYUVFrame(ppm.width, ppm.height, chroma)
+	chromaWidth, chromaHeight := chroma.chromaSize(ppm.width, ppm.height)
+	u := make([]float64, chromaWidth*chromaHeight)
+	v := make([]float64, chromaWidth*chromaHeight)
+	count := make([]int, chromaWidth*chromaHeight)
+
+	for y := 0; y < ppm.height; y++ {
+		for x := 0; x < ppm.width; x++ {
+			pixel := ppm.data[y][x]
+			r := float64(scaleTo8(pixel[0], ppm.max)) / 255
+			g := float64(scaleTo8(pixel[1], ppm.max)) / 255
+			b := float64(scaleTo8(pixel[2], ppm.max)) / 255
+			frame.Y[y*ppm.width+x] = toByte(16 + 65.481*r + 128.553*g + 24.966*b)
+
+			i := y*chromaWidth + x
+			if chroma == Chroma420 {
+				i = (y/2)*chromaWidth + x/2
+			}
+			u[i] += 128 - 37.797*r - 74.203*g + 112*b
+			v[i] += 128 + 112*r - 93.786*g - 18.214*b
+			count[i]++
+		}
+	}
+	for i := range u {
+		frame.U[i] = toByte(u[i] / float64(count[i]))
+		frame.V[i] = toByte(v[i] / float64(count[i]))
+	}
+	return frame
+}
+
+// ToPPM convertit l'image YUV en image PPM de valeur maximale 255.
+func (frame *YUVFrame) ToPPM() *PPM {
+	ppm := NewPPM(frame.Width, frame.Height, 255)
+	chromaWidth, _ := frame.Chroma.chromaSize(frame.Width, frame.Height)
+	for y := 0; y < frame.Height; y++ {
+		for x := 0; x < frame.Width; x++ {
+			i := y*chromaWidth + x
+			if frame.Chroma == Chroma420 {
+				i = (y/2)*chromaWidth + x/2
+			}
+			luma := 1.164383 * (float64(frame.Y[y*frame.Width+x]) - 16)
+			cb, cr := float64(frame.U[i])-128, float64(frame.V[i])-128
+			ppm.data[y][x] = []uint8{
+				toByte(luma + 1.596027*cr),
+				toByte(luma - 0.391762*cb - 0.812968*cr),
+				toByte(luma + 2.017232*cb),
+			}
+		}
+	}
+	return ppm
+}
+
+// WriteTo écrit les trois plans à la suite, comme le format rawvideo de ffmpeg.
+func (frame *YUVFrame) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	for _, plane := range [][]uint8{frame.Y, frame.U, frame.V} {
+		n, err := w.Write(plane)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ReadYUVFrame lit une image rawvideo (plans Y, U et V à la suite) de la taille donnée.
+func ReadYUVFrame(r io.Reader, width, height int, chroma ChromaFormat) (*YUVFrame, error) {
+	frame := NewYUVFrame(width, height, chroma)
+	for _, plane := range [][]uint8{frame.Y, frame.U, frame.V} {
+		if _, err := io.ReadFull(r, plane); err != nil {
+			return nil, err
+		}
+	}
+	return frame, nil
+}
+
+// Y4MWriter écrit un flux YUV4MPEG2 (.y4m), lisible par ffmpeg et la plupart des encodeurs vidéo.
+type Y4MWriter struct {
+	w                   *bufio.Writer
+	width, height       int
+	chroma              ChromaFormat
+	frameRate, timeBase int
+	headerWritten       bool
+}
+
+// NewY4MWriter crée un écrivain de flux Y4M ; la cadence est frameRate/timeBase images par seconde.
+func NewY4MWriter(w io.Writer, width, height int, chroma ChromaFormat, frameRate, timeBase int) *Y4MWriter {
+	return &Y4MWriter{w: bufio.NewWriter(w), width: width, height: height, chroma: chroma, frameRate: frameRate, timeBase: timeBase}
+}
+
+// WriteFrame ajoute une image au flux ; elle doit avoir la taille et le format du flux.
+func (writer *Y4MWriter) WriteFrame(frame *YUVFrame) error {
+	if frame.Width != writer.width || frame.Height != writer.height || frame.Chroma != writer.chroma {
+		return fmt.Errorf("image %dx%d incompatible avec le flux %dx%d", frame.Width, frame.Height, writer.width, writer.height)
+	}
+	if !writer.headerWritten {
+		tag := "C420jpeg"
+		if writer.chroma == Chroma444 {
+			tag = "C444"
+		}
+		fmt.Fprintf(writer.w, "YUV4MPEG2 W%d H%d F%d:%d Ip A1:1 %s\n", writer.width, writer.height, writer.frameRate, writer.timeBase, tag)
+		writer.headerWritten = true
+	}
+	writer.w.WriteString("FRAME\n")
+	if _, err := frame.WriteTo(writer.w); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+// WritePPM convertit l'image PPM et l'ajoute au flux.
+func (writer *Y4MWriter) WritePPM(ppm *PPM) error {
+	return writer.WriteFrame(ppm.ToYUV(writer.chroma))
+}
+
+// Y4MReader lit un flux YUV4MPEG2 image par image.
+type Y4MReader struct {
+	r                   *bufio.Reader
+	Width, Height       int
+	Chroma              ChromaFormat
+	FrameRate, TimeBase int
+}
+
+// NewY4MReader lit l'en-tête du flux.
+func NewY4MReader(r io.Reader) (*Y4MReader, error) {
+	reader := &Y4MReader{r: bufio.NewReader(r), Chroma: Chroma420, FrameRate: 25, TimeBase: 1}
+	header, err := reader.r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("en-tête Y4M illisible: %v", err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 0 || fields[0] != "YUV4MPEG2" {
+		return nil, fmt.Errorf("flux Y4M invalide")
+	}
+
+	for _, field := range fields[1:] {
+		value := field[1:]
+		switch field[0] {
+		case 'W':
+			reader.Width, err = strconv.Atoi(value)
+		case 'H':
+			reader.Height, err = strconv.Atoi(value)
+		case 'F':
+			_, err = fmt.Sscanf(value, "%d:%d", &reader.FrameRate, &reader.TimeBase)
+		case 'C':
+			switch {
+			case strings.HasPrefix(value, "444"):
+				reader.Chroma = Chroma444
+			case strings.HasPrefix(value, "420"):
+				reader.Chroma = Chroma420
+			default:
+				return nil, fmt.Errorf("sous-échantillonnage Y4M non pris en charge: %s", value)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("paramètre Y4M invalide: %s", field)
+		}
+	}
+	if reader.Width <= 0 || reader.Height <= 0 {
+		return nil, fmt.Errorf("taille Y4M invalide: %dx%d", reader.Width, reader.Height)
+	}
+	return reader, nil
+}
+
+// ReadFrame lit l'image suivante du flux ; elle renvoie io.EOF à la fin du flux.
+func (reader *Y4MReader) ReadFrame() (*YUVFrame, error) {
+	header, err := reader.r.ReadString('\n')
+	if err != nil {
+		if err == io.EOF && header == "" {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("en-tête d'image Y4M illisible: %v", err)
+	}
+	if !strings.HasPrefix(header, "FRAME") {
+		return nil, fmt.Errorf("en-tête d'image Y4M invalide: %q", strings.TrimSpace(header))
+	}
+	frame, err := ReadYUVFrame(reader.r, reader.Width, reader.Height, reader.Chroma)
+	if err != nil {
+		return nil, fmt.Errorf("image Y4M tronquée: %v", err)
+	}
+	return frame, nil
+}
+
+// ReadPPM lit l'image suivante du flux et la convertit en image PPM.
+func (reader *Y4MReader) ReadPPM() (*PPM, error) {
+	frame, err := reader.ReadFrame()
+	if err != nil {
+		return nil, err
+	}
+	return frame.ToPPM(), nil
+}